@@ -0,0 +1,259 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// IndexSuggestion is a composite index suggested by an IndexAdvisor.
+type IndexSuggestion struct {
+	Table   string
+	Columns []string
+	// Queries is the number of observed queries the index would serve.
+	Queries int
+}
+
+func (s IndexSuggestion) String() string {
+	return fmt.Sprintf("%s (%s)", s.Table, strings.Join(s.Columns, ", "))
+}
+
+// IndexAdvisor aggregates the indexes which would serve the observed SELECT
+// queries: per table, the columns compared for equality in the WHERE clause
+// (sorted), then the first column compared with a range operator or, if there
+// is none, the ORDER BY columns. OR conditions and plain string predicates are
+// not analyzed. The suggestions are a hint for a test run or a staging
+// environment, not a replacement for the query planner.
+//
+// Ex:
+//
+//	advisor := NewIndexAdvisor()
+//	EnableIndexAdvisor(advisor)
+//	// run the test suite
+//	advisor.Report(func(s IndexSuggestion) { log.Printf("%s: %d queries", s, s.Queries) })
+type IndexAdvisor struct {
+	mu          sync.Mutex
+	suggestions map[string]*IndexSuggestion
+}
+
+// NewIndexAdvisor returns an IndexAdvisor without observed queries.
+func NewIndexAdvisor() *IndexAdvisor {
+	return &IndexAdvisor{suggestions: map[string]*IndexSuggestion{}}
+}
+
+// indexAdvisor holds the *IndexAdvisor enabled by EnableIndexAdvisor, loaded
+// without a lock by every SelectBuilder.ToSql call.
+var indexAdvisor atomic.Value
+
+// EnableIndexAdvisor makes every SelectBuilder.ToSql call feed a, until it is
+// called with nil.
+func EnableIndexAdvisor(a *IndexAdvisor) {
+	indexAdvisor.Store(a)
+}
+
+func enabledIndexAdvisor() *IndexAdvisor {
+	a, _ := indexAdvisor.Load().(*IndexAdvisor)
+	return a
+}
+
+// Observe analyzes the query built by b.
+func (a *IndexAdvisor) Observe(b SelectBuilder) {
+	data := b.get()
+	a.observe(&data)
+}
+
+// Suggestions returns the suggested indexes, the most used first.
+func (a *IndexAdvisor) Suggestions() []IndexSuggestion {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	suggestions := make([]IndexSuggestion, 0, len(a.suggestions))
+	for _, s := range a.suggestions {
+		s := *s
+		s.Columns = append([]string{}, s.Columns...)
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Queries != suggestions[j].Queries {
+			return suggestions[i].Queries > suggestions[j].Queries
+		}
+		return suggestions[i].String() < suggestions[j].String()
+	})
+	return suggestions
+}
+
+// Report calls f with every suggested index, the most used first.
+func (a *IndexAdvisor) Report(f func(IndexSuggestion)) {
+	for _, s := range a.Suggestions() {
+		f(s)
+	}
+}
+
+// Reset forgets the observed queries.
+func (a *IndexAdvisor) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.suggestions = map[string]*IndexSuggestion{}
+}
+
+// indexColumns collects the columns of a table used by a query.
+type indexColumns struct {
+	equal   []string
+	ranges  []string
+	orderBy []string
+}
+
+// indexedColumnRegexp matches a plain, possibly qualified, column.
+var indexedColumnRegexp = regexp.MustCompile(`^(?:([A-Za-z_][A-Za-z0-9_]*)\.)?([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// indexAnalysis resolves the columns of a query to the tables of its FROM and JOIN clauses.
+type indexAnalysis struct {
+	tables  map[string]string // name or alias -> table
+	single  string            // the table, if there is only one
+	columns map[string]*indexColumns
+}
+
+func (x *indexAnalysis) add(column string, kind func(*indexColumns) *[]string) {
+	m := indexedColumnRegexp.FindStringSubmatch(strings.TrimSpace(column))
+	if m == nil {
+		return
+	}
+	table := x.single
+	if len(m[1]) > 0 {
+		table = x.tables[strings.ToLower(m[1])]
+	}
+	if len(table) == 0 {
+		return
+	}
+
+	cols, ok := x.columns[table]
+	if !ok {
+		cols = &indexColumns{}
+		x.columns[table] = cols
+	}
+	list := kind(cols)
+	for _, c := range *list {
+		if c == m[2] {
+			return
+		}
+	}
+	*list = append(*list, m[2])
+}
+
+func equalColumns(c *indexColumns) *[]string   { return &c.equal }
+func rangeColumns(c *indexColumns) *[]string   { return &c.ranges }
+func orderByColumns(c *indexColumns) *[]string { return &c.orderBy }
+
+func (x *indexAnalysis) addPredicates(preds []Sqlizer) {
+	for _, pred := range preds {
+		switch p := pred.(type) {
+		case *wherePart:
+			if m, ok := p.pred.(map[string]any); ok {
+				x.addAll(getSortedKeys(m), equalColumns)
+			} else if s, ok := p.pred.(Sqlizer); ok {
+				x.addPredicates([]Sqlizer{s})
+			}
+		case And:
+			x.addPredicates(p)
+		case Eq:
+			x.addAll(getSortedKeys(p), equalColumns)
+		case EqNotEmpty:
+			x.addAll(getSortedKeys(p), equalColumns)
+		case inExpr:
+			x.add(p.column, equalColumns)
+		case Lt:
+			x.addAll(getSortedKeys(p), rangeColumns)
+		case LtOrEq:
+			x.addAll(getSortedKeys(p), rangeColumns)
+		case Gt:
+			x.addAll(getSortedKeys(p), rangeColumns)
+		case GtOrEq:
+			x.addAll(getSortedKeys(p), rangeColumns)
+		case rangeExpr:
+			x.add(p.column, rangeColumns)
+		}
+	}
+}
+
+func (x *indexAnalysis) addAll(columns []string, kind func(*indexColumns) *[]string) {
+	sort.Strings(columns)
+	for _, column := range columns {
+		x.add(column, kind)
+	}
+}
+
+func (a *IndexAdvisor) observe(d *selectData) {
+	x := &indexAnalysis{tables: map[string]string{}, columns: map[string]*indexColumns{}}
+	refs := append([]Sqlizer{d.From}, d.Joins...)
+	for _, ref := range refs {
+		for _, table := range tableRefs(ref) {
+			if len(table.table) > 0 {
+				x.tables[strings.ToLower(table.name)] = table.table
+			}
+		}
+	}
+	if len(x.tables) == 0 {
+		return
+	}
+	if len(refs) == 1 && len(x.tables) == 1 {
+		for _, table := range x.tables {
+			x.single = table
+		}
+	}
+
+	x.addPredicates(d.WhereParts)
+	for _, orderBy := range d.OrderByParts {
+		p, ok := orderBy.(*part)
+		if !ok {
+			continue
+		}
+		s, ok := p.pred.(string)
+		if !ok {
+			continue
+		}
+		for _, item := range strings.Split(s, ",") {
+			if fields := strings.Fields(item); len(fields) > 0 {
+				x.add(fields[0], orderByColumns)
+			}
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for table, cols := range x.columns {
+		columns := append([]string{}, cols.equal...)
+		sort.Strings(columns)
+		if len(cols.ranges) > 0 {
+			columns = appendNew(columns, cols.ranges[0])
+		} else {
+			for _, column := range cols.orderBy {
+				columns = appendNew(columns, column)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+
+		s := IndexSuggestion{Table: table, Columns: columns}
+		key := s.String()
+		if existing, ok := a.suggestions[key]; ok {
+			existing.Queries++
+			continue
+		}
+		s.Queries = 1
+		a.suggestions[key] = &s
+	}
+}
+
+// appendNew appends s to list unless list already has it.
+func appendNew(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,44 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexAdvisor(t *testing.T) {
+	a := NewIndexAdvisor()
+	EnableIndexAdvisor(a)
+	defer EnableIndexAdvisor(nil)
+
+	for i := 0; i < 2; i++ {
+		_, _, err := Select("*").From("users").Where(Eq{"org_id": 1, "active": true}).OrderBy("created_at DESC").ToSql()
+		assert.NoError(t, err)
+	}
+	_, _, err := Select("o.id").From("orders o").Join("users u ON u.id = o.user_id").
+		Where(Eq{"u.email": "a@b.c"}).Where(Gt{"o.created_at": 1}).Where(Eq{"o.status": "paid"}).ToSql()
+	assert.NoError(t, err)
+	_, _, err = Select("*").From("users").Where(Or{Eq{"a": 1}, Eq{"b": 2}}).Where("name = ?", "x").ToSql()
+	assert.NoError(t, err)
+
+	var reported []string
+	a.Report(func(s IndexSuggestion) { reported = append(reported, s.String()) })
+	assert.Equal(t, []string{"users (active, org_id, created_at)", "orders (status, created_at)", "users (email)"}, reported)
+	assert.Equal(t, 2, a.Suggestions()[0].Queries)
+
+	a.Reset()
+	assert.Empty(t, a.Suggestions())
+
+	a.Observe(Select("*").From("events").Where(Range("ts", 1, 2)).Where(In("kind", []string{"a"})))
+	assert.Equal(t, []IndexSuggestion{{Table: "events", Columns: []string{"kind", "ts"}, Queries: 1}}, a.Suggestions())
+}
+
+func TestIndexAdvisorDisabled(t *testing.T) {
+	a := NewIndexAdvisor()
+	EnableIndexAdvisor(a)
+	EnableIndexAdvisor(nil)
+
+	_, _, err := Select("*").From("users").Where(Eq{"id": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Empty(t, a.Suggestions())
+}
@@ -0,0 +1,122 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// argBinders holds the map[reflect.Type]ArgBinder of the registered binders. The
+// map is replaced, never modified, so that building a query doesn't take a lock.
+var (
+	argBindersMutex sync.Mutex // serializes RegisterArgBinder
+	argBinders      atomic.Value
+)
+
+// ArgBinder declares how the values of a custom type, e.g. decimal.Decimal or
+// uuid.UUID, are bound as args and rendered as literals.
+type ArgBinder interface {
+	// BindArg returns the value passed to the driver in place of v.
+	BindArg(v any) (driver.Value, error)
+	// ArgLiteral returns v as a literal of the dialect d, used by DebugSqlizer,
+	// DebugSqlizerDialect and InlineArgs. InlineArgs only calls it when BindArg
+	// returns a value it could inline, and returns the error of BindArg otherwise.
+	ArgLiteral(v any, d Dialect) string
+}
+
+// ArgBinderFuncs is an ArgBinder made of funcs. Without Literal, the value
+// returned by Bind is rendered with Dialect.Literal.
+type ArgBinderFuncs struct {
+	Bind    func(v any) (driver.Value, error)
+	Literal func(v any, d Dialect) string
+}
+
+func (f ArgBinderFuncs) BindArg(v any) (driver.Value, error) {
+	return f.Bind(v)
+}
+
+func (f ArgBinderFuncs) ArgLiteral(v any, d Dialect) string {
+	if f.Literal != nil {
+		return f.Literal(v, d)
+	}
+	value, err := f.Bind(v)
+	if err != nil {
+		return fmt.Sprintf("[BindArg error: %s]", err)
+	}
+	return d.Literal(value)
+}
+
+// RegisterArgBinder registers how the values of the type of sample are bound and
+// rendered. Such values are always bound as a single arg, even if the type is an
+// array or a slice, and the statement builders return them as driver.Valuer
+// wrappers calling b.BindArg, so any driver accepts them.
+// Ex:
+//
+//	RegisterArgBinder(uuid.UUID{}, ArgBinderFuncs{
+//		Bind: func(v any) (driver.Value, error) { return v.(uuid.UUID).String(), nil },
+//	})
+//	Select("*").From("users").Where(Eq{"id": id}) // id = ? instead of id IN (?,?,...)
+func RegisterArgBinder(sample any, b ArgBinder) {
+	argBindersMutex.Lock()
+	defer argBindersMutex.Unlock()
+
+	binders := map[reflect.Type]ArgBinder{reflect.TypeOf(sample): b}
+	for t, b := range registeredArgBinders() {
+		if _, ok := binders[t]; !ok {
+			binders[t] = b
+		}
+	}
+	argBinders.Store(binders)
+}
+
+// registeredArgBinders returns the registered binders, which must not be modified.
+func registeredArgBinders() map[reflect.Type]ArgBinder {
+	binders, _ := argBinders.Load().(map[reflect.Type]ArgBinder)
+	return binders
+}
+
+func argBinderOf(v any) (ArgBinder, bool) {
+	binders := registeredArgBinders()
+	if len(binders) == 0 {
+		return nil, false
+	}
+	b, ok := binders[reflect.TypeOf(v)]
+	return b, ok
+}
+
+// boundArg is an arg of a type registered with RegisterArgBinder
+type boundArg struct {
+	v      any
+	binder ArgBinder
+}
+
+func (a boundArg) Value() (driver.Value, error) {
+	return a.binder.BindArg(a.v)
+}
+
+func (a boundArg) String() string {
+	return fmt.Sprint(a.v)
+}
+
+// bindArgs wraps the args of types registered with RegisterArgBinder. args is
+// copied first if one is wrapped, so the args of the builder parts are left as is.
+func bindArgs(args []any) []any {
+	binders := registeredArgBinders()
+	if len(binders) == 0 {
+		return args
+	}
+	bound := args
+	for i, arg := range args {
+		b, ok := binders[reflect.TypeOf(arg)]
+		if !ok {
+			continue
+		}
+		if &bound[0] == &args[0] {
+			bound = append([]any(nil), args...)
+		}
+		bound[i] = boundArg{v: arg, binder: b}
+	}
+	return bound
+}
@@ -0,0 +1,94 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUUID [4]byte
+
+type testMoney int64
+
+func registerTestArgBinders(t *testing.T) {
+	RegisterArgBinder(testUUID{}, ArgBinderFuncs{
+		Bind: func(v any) (driver.Value, error) { return fmt.Sprintf("%x", v.(testUUID)), nil },
+	})
+	RegisterArgBinder(testMoney(0), ArgBinderFuncs{
+		Bind: func(v any) (driver.Value, error) { return fmt.Sprintf("%.2f", float64(v.(testMoney))/100), nil },
+		Literal: func(v any, d Dialect) string {
+			return fmt.Sprintf("%.2f", float64(v.(testMoney))/100)
+		},
+	})
+	t.Cleanup(func() {
+		argBinders.Store(map[reflect.Type]ArgBinder(nil))
+	})
+}
+
+func TestArgBinder(t *testing.T) {
+	id := testUUID{1, 2, 3, 4}
+
+	sql, args, err := Select("*").From("users").Where(Eq{"id": id}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id IN (?,?,?,?)", sql)
+	assert.Len(t, args, 4)
+
+	registerTestArgBinders(t)
+
+	b := Select("*").From("users").Where(Eq{"id": id}).Where("balance > ?", testMoney(150))
+	sql, args, err = b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ? AND balance > ?", sql)
+	assert.Len(t, args, 2)
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		valuer, ok := arg.(driver.Valuer)
+		if assert.True(t, ok) {
+			values[i], err = valuer.Value()
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, []driver.Value{"01020304", "1.50"}, values)
+
+	assert.Equal(t, "SELECT * FROM users WHERE id = '01020304' AND balance > 1.50", DebugSqlizer(b))
+	assert.Equal(t, "SELECT * FROM users WHERE id = N'01020304' AND balance > 1.50", DebugSqlizerDialect(b, DialectMSSQL))
+
+	sql, err = InlineArgs(Expr("balance > ?", testMoney(150)), DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "balance > 1.50", sql)
+
+	sql, err = InlineArgs(Select("*").From("users").Where(Eq{"id": id}), DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = '01020304'", sql)
+
+	_, args, err = Insert("users").Columns("id").Values(id).ToSql()
+	assert.NoError(t, err)
+	assert.IsType(t, boundArg{}, args[0])
+}
+
+type testFailingArg int
+
+func TestArgBinderInlineErr(t *testing.T) {
+	registerTestArgBinders(t)
+	RegisterArgBinder(testFailingArg(0), ArgBinderFuncs{
+		Bind: func(v any) (driver.Value, error) { return nil, fmt.Errorf("cannot bind %d", v) },
+	})
+	RegisterArgBinder(struct{ X int }{}, ArgBinderFuncs{
+		Bind:    func(v any) (driver.Value, error) { return v, nil },
+		Literal: func(v any, d Dialect) string { return "x" },
+	})
+
+	_, err := InlineArgs(Expr("a = ?", testFailingArg(1)), DialectPostgres)
+	assert.EqualError(t, err, "arg 1: cannot bind 1")
+
+	_, err = InlineArgs(Select("*").From("t").Where(Eq{"a": testFailingArg(1)}), DialectPostgres)
+	assert.EqualError(t, err, "arg 1: cannot bind 1")
+
+	// the bound value is validated like any other arg
+	_, err = InlineArgs(Expr("a = ?", struct{ X int }{1}), DialectPostgres)
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package squirrel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bucketExpr helps to split numeric values into fixed-width buckets
+type bucketExpr struct {
+	column string
+	width  any
+	origin any
+}
+
+// Bucket allows to use the index of the width-wide bucket, counted from origin,
+// containing the value of column, e.g. to group values into a histogram.
+// Use float width or origin values with integer columns on engines doing integer
+// division (PostgreSQL, SQLite, MSSQL).
+// Ex: Select("COUNT(*)").Column(Alias(Bucket("price", 10.0, 0), "b")) -> "SELECT COUNT(*), (FLOOR((price - ?) / ?)) AS b"
+func Bucket(column string, width, origin any) bucketExpr {
+	return bucketExpr{column: column, width: width, origin: origin}
+}
+
+func (e bucketExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 {
+		return "", nil, fmt.Errorf("bucket requires a column")
+	}
+	return fmt.Sprintf("FLOOR((%s - ?) / ?)", e.column), []any{e.origin, e.width}, nil
+}
+
+// widthBucketExpr helps to split a range of values into equal-width buckets
+type widthBucketExpr struct {
+	column  string
+	low     any
+	high    any
+	count   int
+	dialect Dialect
+}
+
+// WidthBucket allows to use the width_bucket function: the index (1 to count) of
+// the bucket containing the value of column when the range [low, high) is split
+// into count buckets, 0 or count+1 for values out of the range.
+// It is available for PostgreSQL, DuckDB, Oracle and Snowflake.
+// Ex: WidthBucket("score", 0, 100, 10) -> "width_bucket(score, ?, ?, 10)"
+func WidthBucket(column string, low, high any, count int) widthBucketExpr {
+	return widthBucketExpr{column: column, low: low, high: high, count: count}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e widthBucketExpr) Dialect(d Dialect) widthBucketExpr {
+	e.dialect = d
+	return e
+}
+
+func (e widthBucketExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 || e.count < 1 {
+		return "", nil, fmt.Errorf("width bucket requires a column and a positive bucket count")
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectDuckDB, DialectOracle, DialectOracleLegacy, DialectSnowflake:
+		return fmt.Sprintf("width_bucket(%s, ?, ?, %d)", e.column, e.count), []any{e.low, e.high}, nil
+	}
+	return "", nil, e.dialect.unsupportedError("width_bucket")
+}
+
+// timeBucketExpr helps to truncate timestamps to the start of their interval
+type timeBucketExpr struct {
+	column   string
+	interval string
+	dialect  Dialect
+}
+
+// TimeBucket allows to truncate the timestamps of column to the start of their
+// interval, e.g. for time series grouping. interval is a unit (second, minute,
+// hour, day, week, month, quarter, year), optionally preceded by a count
+// ("15 minutes"); counts are supported for PostgreSQL (date_bin), DuckDB and
+// ClickHouse only. Weeks start on Monday.
+// Ex: TimeBucket("created_at", "hour").Dialect(DialectClickHouse) -> "toStartOfInterval(created_at, INTERVAL 1 hour)"
+func TimeBucket(column, interval string) timeBucketExpr {
+	return timeBucketExpr{column: column, interval: interval}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e timeBucketExpr) Dialect(d Dialect) timeBucketExpr {
+	e.dialect = d
+	return e
+}
+
+var timeBucketUnits = map[string]bool{
+	"second": true, "minute": true, "hour": true, "day": true,
+	"week": true, "month": true, "quarter": true, "year": true,
+}
+
+// parseInterval splits an interval like "15 minutes" into its count and unit.
+func parseInterval(interval string) (int, string, error) {
+	fields := strings.Fields(strings.ToLower(interval))
+	count := 1
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 1 {
+			return 0, "", fmt.Errorf("invalid interval %q", interval)
+		}
+		count, fields = n, fields[1:]
+	}
+	if len(fields) != 1 {
+		return 0, "", fmt.Errorf("invalid interval %q", interval)
+	}
+
+	unit := strings.TrimSuffix(fields[0], "s")
+	if !timeBucketUnits[unit] {
+		return 0, "", fmt.Errorf("invalid interval unit %q", fields[0])
+	}
+	return count, unit, nil
+}
+
+func (e timeBucketExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 {
+		return "", nil, fmt.Errorf("time bucket requires a column")
+	}
+	count, unit, err := parseInterval(e.interval)
+	if err != nil {
+		return "", nil, err
+	}
+
+	col, d := e.column, e.dialect
+	if count > 1 {
+		switch d { //nolint:exhaustive
+		case DialectDefault, DialectPostgres:
+			if unit == "month" || unit == "quarter" || unit == "year" {
+				return "", nil, fmt.Errorf("date_bin does not support %s intervals", unit)
+			}
+			return fmt.Sprintf("date_bin('%d %ss', %s, TIMESTAMP '1970-01-01')", count, unit, col), nil, nil
+		case DialectDuckDB:
+			return fmt.Sprintf("time_bucket(INTERVAL '%d %ss', %s)", count, unit, col), nil, nil
+		case DialectClickHouse:
+		default:
+			return "", nil, d.unsupportedError("TimeBucket with an interval count")
+		}
+	}
+
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectDuckDB, DialectSnowflake:
+		return fmt.Sprintf("date_trunc('%s', %s)", unit, col), nil, nil
+	case DialectClickHouse:
+		return fmt.Sprintf("toStartOfInterval(%s, INTERVAL %d %s)", col, count, unit), nil, nil
+	case DialectBigQuery:
+		if unit == "week" {
+			return fmt.Sprintf("TIMESTAMP_TRUNC(%s, ISOWEEK)", col), nil, nil
+		}
+		return fmt.Sprintf("TIMESTAMP_TRUNC(%s, %s)", col, strings.ToUpper(unit)), nil, nil
+	case DialectMSSQL:
+		if unit == "week" {
+			return fmt.Sprintf("DATETRUNC(iso_week, %s)", col), nil, nil
+		}
+		return fmt.Sprintf("DATETRUNC(%s, %s)", unit, col), nil, nil
+	case DialectOracle, DialectOracleLegacy:
+		formats := map[string]string{
+			"minute": "MI", "hour": "HH", "day": "DD", "week": "IW", "month": "MM", "quarter": "Q", "year": "YYYY",
+		}
+		if format, ok := formats[unit]; ok {
+			return fmt.Sprintf("TRUNC(%s, '%s')", col, format), nil, nil
+		}
+	case DialectMySQL, DialectMariaDB:
+		switch unit {
+		case "second":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:%%i:%%s')", col), nil, nil
+		case "minute":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:%%i:00')", col), nil, nil
+		case "hour":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00')", col), nil, nil
+		case "day":
+			return fmt.Sprintf("DATE(%s)", col), nil, nil
+		case "week":
+			return fmt.Sprintf("DATE_SUB(DATE(%s), INTERVAL WEEKDAY(%s) DAY)", col, col), nil, nil
+		case "month":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-01')", col), nil, nil
+		case "quarter":
+			return fmt.Sprintf("MAKEDATE(YEAR(%s), 1) + INTERVAL QUARTER(%s) - 1 QUARTER", col, col), nil, nil
+		case "year":
+			return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-01-01')", col), nil, nil
+		}
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		switch unit {
+		case "second":
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%S', %s)", col), nil, nil
+		case "minute":
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:00', %s)", col), nil, nil
+		case "hour":
+			return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:00:00', %s)", col), nil, nil
+		case "day":
+			return fmt.Sprintf("date(%s)", col), nil, nil
+		case "week":
+			return fmt.Sprintf("date(%s, '-6 days', 'weekday 1')", col), nil, nil
+		case "month":
+			return fmt.Sprintf("strftime('%%Y-%%m-01', %s)", col), nil, nil
+		case "year":
+			return fmt.Sprintf("strftime('%%Y-01-01', %s)", col), nil, nil
+		}
+	}
+	return "", nil, d.unsupportedError("TimeBucket by " + unit)
+}
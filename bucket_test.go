@@ -0,0 +1,71 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket(t *testing.T) {
+	sql, args, err := Select("COUNT(*)").Column(Alias(Bucket("price", 10.0, 0), "b")).
+		From("products").GroupBy("b").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*), (FLOOR((price - ?) / ?)) AS b FROM products GROUP BY b", sql)
+	assert.Equal(t, []any{0, 10.0}, args)
+
+	_, _, err = Bucket("", 10, 0).ToSql()
+	assert.Error(t, err)
+}
+
+func TestWidthBucket(t *testing.T) {
+	sql, args, err := WidthBucket("score", 0, 100, 10).Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "width_bucket(score, ?, ?, 10)", sql)
+	assert.Equal(t, []any{0, 100}, args)
+
+	_, _, err = WidthBucket("score", 0, 100, 10).Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = WidthBucket("score", 0, 100, 0).ToSql()
+	assert.Error(t, err)
+}
+
+func TestTimeBucket(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		interval string
+		want     string
+	}{
+		{DialectPostgres, "hour", "date_trunc('hour', ts)"},
+		{DialectPostgres, "15 minutes", "date_bin('15 minutes', ts, TIMESTAMP '1970-01-01')"},
+		{DialectDuckDB, "5 Minutes", "time_bucket(INTERVAL '5 minutes', ts)"},
+		{DialectClickHouse, "day", "toStartOfInterval(ts, INTERVAL 1 day)"},
+		{DialectClickHouse, "15 minutes", "toStartOfInterval(ts, INTERVAL 15 minute)"},
+		{DialectBigQuery, "month", "TIMESTAMP_TRUNC(ts, MONTH)"},
+		{DialectBigQuery, "week", "TIMESTAMP_TRUNC(ts, ISOWEEK)"},
+		{DialectSnowflake, "days", "date_trunc('day', ts)"},
+		{DialectMSSQL, "quarter", "DATETRUNC(quarter, ts)"},
+		{DialectOracle, "week", "TRUNC(ts, 'IW')"},
+		{DialectMySQL, "hour", "DATE_FORMAT(ts, '%Y-%m-%d %H:00:00')"},
+		{DialectMySQL, "week", "DATE_SUB(DATE(ts), INTERVAL WEEKDAY(ts) DAY)"},
+		{DialectSQLite, "month", "strftime('%Y-%m-01', ts)"},
+	}
+	for _, tt := range tests {
+		sql, args, err := TimeBucket("ts", tt.interval).Dialect(tt.dialect).ToSql()
+		assert.NoError(t, err, tt.dialect, tt.interval)
+		assert.Equal(t, tt.want, sql, tt.dialect, tt.interval)
+		assert.Empty(t, args)
+	}
+
+	_, _, err := TimeBucket("ts", "15 minutes").Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = TimeBucket("ts", "2 months").Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = TimeBucket("ts", "fortnight").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = TimeBucket("ts", "quarter").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// updateFromValuesQuery helps to update many rows with different values at once
+type updateFromValuesQuery struct {
+	table             string
+	keyColumns        []string
+	rows              []map[string]any
+	casts             map[string]string
+	dialect           Dialect
+	placeholderFormat PlaceholderFormat
+}
+
+// UpdateFromValues allows to update the rows of table identified by keyColumns
+// with the values of rows in a single statement instead of one UPDATE per row.
+// Every row must have a value for the key columns and for the same columns to set.
+//
+// For PostgreSQL the rows are joined from a VALUES list. Its parameters would be
+// typed as text, so the first row casts them to the SQL type of the Go type of
+// the column values (e.g. bigint for int, see Cast to set another type). Other
+// dialects use a CASE expression per column.
+// Ex:
+//
+//	UpdateFromValues("users", []string{"id"}, []map[string]any{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}})
+//	// UPDATE users SET name = v.name FROM (VALUES (?::bigint, ?), (?, ?)) AS v(id, name) WHERE users.id = v.id
+//	// MySQL: UPDATE users SET name = CASE WHEN id = ? THEN ? WHEN id = ? THEN ? ELSE name END WHERE id IN (?,?)
+func UpdateFromValues(table string, keyColumns []string, rows []map[string]any) updateFromValuesQuery {
+	return updateFromValuesQuery{table: table, keyColumns: keyColumns, rows: rows}
+}
+
+// Cast sets the type the values of column are cast to in the VALUES list
+// (PostgreSQL), instead of the type of its Go values: it is required for the
+// values whose type is unknown, e.g. driver.Valuer.
+// Ex: Cast("id", "integer") -> "(VALUES (?::integer, ?), ...)"
+func (q updateFromValuesQuery) Cast(column, typ string) updateFromValuesQuery {
+	casts := make(map[string]string, len(q.casts)+1)
+	for k, v := range q.casts {
+		casts[k] = v
+	}
+	casts[column] = typ
+	q.casts = casts
+	return q
+}
+
+// Dialect sets the dialect used to render the statement.
+// The placeholder format preferred by the dialect is set as well.
+func (q updateFromValuesQuery) Dialect(d Dialect) updateFromValuesQuery {
+	q.dialect = d
+	if f := d.PlaceholderFormat(); f != nil {
+		q.placeholderFormat = f
+	}
+	return q
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the statement.
+func (q updateFromValuesQuery) PlaceholderFormat(f PlaceholderFormat) updateFromValuesQuery {
+	q.placeholderFormat = f
+	return q
+}
+
+// setColumns returns the sorted columns to set, checking that every row has a
+// value for the key columns and the same columns to set.
+func (q updateFromValuesQuery) setColumns() ([]string, error) {
+	isKey := make(map[string]bool, len(q.keyColumns))
+	for _, key := range q.keyColumns {
+		isKey[key] = true
+	}
+
+	var columns []string
+	for column := range q.rows[0] {
+		if !isKey[column] {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("update from values requires at least one column to set")
+	}
+
+	for i, row := range q.rows {
+		if len(row) != len(columns)+len(q.keyColumns) {
+			return nil, fmt.Errorf("row %d has different columns than the first row", i+1)
+		}
+		for _, column := range append(q.keyColumns[:len(q.keyColumns):len(q.keyColumns)], columns...) {
+			if _, ok := row[column]; !ok {
+				return nil, fmt.Errorf("row %d has no value for column %s", i+1, column)
+			}
+		}
+	}
+	return columns, nil
+}
+
+func (q updateFromValuesQuery) toSqlRaw() (string, []any, error) {
+	if len(q.table) == 0 || len(q.keyColumns) == 0 {
+		return "", nil, fmt.Errorf("update from values requires a table and key columns")
+	}
+	if len(q.rows) == 0 {
+		return "", nil, fmt.Errorf("update from values requires at least one row")
+	}
+	columns, err := q.setColumns()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if q.dialect == DialectDefault || q.dialect == DialectPostgres {
+		return q.valuesToSqlRaw(columns)
+	}
+	return q.caseToSqlRaw(columns)
+}
+
+// castOf returns the type the values of column are cast to in the VALUES list:
+// the type set by Cast, or the SQL type of the first non nil value. Text values
+// and values of unknown type are not cast.
+func (q updateFromValuesQuery) castOf(column string) string {
+	if typ, ok := q.casts[column]; ok {
+		return typ
+	}
+	for _, row := range q.rows {
+		if row[column] == nil {
+			continue
+		}
+		t := reflect.TypeOf(row[column])
+		if t == reflect.TypeOf([]byte(nil)) {
+			return "bytea"
+		}
+		typ, err := sqlTypeNameHelper(t)
+		if err != nil || typ == "text" {
+			return ""
+		}
+		return typ
+	}
+	return ""
+}
+
+func (q updateFromValuesQuery) valuesToSqlRaw(columns []string) (string, []any, error) {
+	all := append(q.keyColumns[:len(q.keyColumns):len(q.keyColumns)], columns...)
+
+	sets := make([]string, len(columns))
+	for i, column := range columns {
+		sets[i] = fmt.Sprintf("%s = v.%s", column, column)
+	}
+	conds := make([]string, len(q.keyColumns))
+	for i, key := range q.keyColumns {
+		conds[i] = fmt.Sprintf("%s.%s = v.%s", q.table, key, key)
+	}
+
+	casts := make([]string, len(all))
+	for i, column := range all {
+		casts[i] = q.castOf(column)
+	}
+
+	var args []any
+	values := make([]string, len(q.rows))
+	for i, row := range q.rows {
+		placeholders := make([]string, len(all))
+		for j, column := range all {
+			placeholders[j] = "?"
+			if len(casts[j]) > 0 && i == 0 {
+				placeholders[j] += "::" + casts[j]
+			}
+			args = append(args, row[column])
+		}
+		values[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	sql := fmt.Sprintf("UPDATE %s SET %s FROM (VALUES %s) AS v(%s) WHERE %s",
+		q.table, strings.Join(sets, ", "), strings.Join(values, ", "), strings.Join(all, ", "), strings.Join(conds, " AND "))
+	return sql, args, nil
+}
+
+func (q updateFromValuesQuery) caseToSqlRaw(columns []string) (string, []any, error) {
+	keyConds := make([]string, len(q.keyColumns))
+	for i, key := range q.keyColumns {
+		keyConds[i] = key + " = ?"
+	}
+	keyCond := strings.Join(keyConds, " AND ")
+
+	sql := &bytes.Buffer{}
+	var args []any
+	_, _ = fmt.Fprintf(sql, "UPDATE %s SET ", q.table)
+	for i, column := range columns {
+		if i > 0 {
+			_, _ = sql.WriteString(", ")
+		}
+		_, _ = fmt.Fprintf(sql, "%s = CASE", column)
+		for _, row := range q.rows {
+			_, _ = fmt.Fprintf(sql, " WHEN %s THEN ?", keyCond)
+			for _, key := range q.keyColumns {
+				args = append(args, row[key])
+			}
+			args = append(args, row[column])
+		}
+		_, _ = fmt.Fprintf(sql, " ELSE %s END", column)
+	}
+
+	_, _ = sql.WriteString(" WHERE ")
+	if len(q.keyColumns) == 1 {
+		_, _ = fmt.Fprintf(sql, "%s IN (%s)", q.keyColumns[0], Placeholders(len(q.rows)))
+		for _, row := range q.rows {
+			args = append(args, row[q.keyColumns[0]])
+		}
+	} else {
+		for i, row := range q.rows {
+			if i > 0 {
+				_, _ = sql.WriteString(" OR ")
+			}
+			_, _ = fmt.Fprintf(sql, "(%s)", keyCond)
+			for _, key := range q.keyColumns {
+				args = append(args, row[key])
+			}
+		}
+	}
+	return sql.String(), args, nil
+}
+
+// ToSql builds the statement into a SQL string and bound args.
+func (q updateFromValuesQuery) ToSql() (string, []any, error) {
+	sql, args, err := q.toSqlRaw()
+	if err != nil || q.placeholderFormat == nil {
+		return sql, args, err
+	}
+
+	sql, err = q.placeholderFormat.ReplacePlaceholders(sql)
+	return sql, args, err
+}
@@ -0,0 +1,77 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bulkUpdateRows = []map[string]any{
+	{"id": 1, "name": "a", "rank": 10},
+	{"id": 2, "name": "b", "rank": 20},
+}
+
+func TestUpdateFromValuesPostgres(t *testing.T) {
+	sql, args, err := UpdateFromValues("users", []string{"id"}, bulkUpdateRows).
+		Cast("id", "bigint").Cast("rank", "int").Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = v.name, rank = v.rank "+
+		"FROM (VALUES ($1::bigint, $2, $3::int), ($4, $5, $6)) AS v(id, name, rank) WHERE users.id = v.id", sql)
+	assert.Equal(t, []any{1, "a", 10, 2, "b", 20}, args)
+}
+
+func TestUpdateFromValuesPostgresInferredCasts(t *testing.T) {
+	rows := []map[string]any{
+		{"id": int64(1), "name": "a", "score": nil, "active": true, "data": []byte("x")},
+		{"id": int64(2), "name": "b", "score": 1.5, "active": false, "data": nil},
+	}
+	sql, _, err := UpdateFromValues("users", []string{"id"}, rows).Cast("score", "numeric").Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET active = v.active, data = v.data, name = v.name, score = v.score "+
+		"FROM (VALUES ($1::bigint, $2::boolean, $3::bytea, $4, $5::numeric), ($6, $7, $8, $9, $10)) "+
+		"AS v(id, active, data, name, score) WHERE users.id = v.id", sql)
+
+	// nil and unknown values are not cast
+	rows = []map[string]any{{"id": 1, "name": nil, "at": struct{}{}}}
+	sql, _, err = UpdateFromValues("users", []string{"id"}, rows).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET at = v.at, name = v.name "+
+		"FROM (VALUES (?::bigint, ?, ?)) AS v(id, at, name) WHERE users.id = v.id", sql)
+}
+
+func TestUpdateFromValuesCase(t *testing.T) {
+	sql, args, err := UpdateFromValues("users", []string{"id"}, bulkUpdateRows).Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET "+
+		"name = CASE WHEN id = ? THEN ? WHEN id = ? THEN ? ELSE name END, "+
+		"rank = CASE WHEN id = ? THEN ? WHEN id = ? THEN ? ELSE rank END "+
+		"WHERE id IN (?,?)", sql)
+	assert.Equal(t, []any{1, "a", 2, "b", 1, 10, 2, 20, 1, 2}, args)
+
+	rows := []map[string]any{{"org": 1, "id": 1, "name": "a"}, {"org": 1, "id": 2, "name": "b"}}
+	sql, args, err = UpdateFromValues("users", []string{"org", "id"}, rows).Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET "+
+		"name = CASE WHEN org = ? AND id = ? THEN ? WHEN org = ? AND id = ? THEN ? ELSE name END "+
+		"WHERE (org = ? AND id = ?) OR (org = ? AND id = ?)", sql)
+	assert.Equal(t, []any{1, 1, "a", 1, 2, "b", 1, 1, 1, 2}, args)
+}
+
+func TestUpdateFromValuesErr(t *testing.T) {
+	_, _, err := UpdateFromValues("users", []string{"id"}, nil).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = UpdateFromValues("users", nil, bulkUpdateRows).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = UpdateFromValues("users", []string{"id"}, []map[string]any{{"id": 1}}).ToSql()
+	assert.Error(t, err)
+
+	rows := []map[string]any{{"id": 1, "name": "a"}, {"id": 2, "rank": 1}}
+	_, _, err = UpdateFromValues("users", []string{"id"}, rows).ToSql()
+	assert.Error(t, err)
+
+	rows = []map[string]any{{"id": 1, "name": "a"}, {"name": "b"}}
+	_, _, err = UpdateFromValues("users", []string{"id"}, rows).ToSql()
+	assert.Error(t, err)
+}
@@ -5,15 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
-
-	"github.com/lann/builder"
 )
 
-func init() {
-	builder.Register(CaseBuilder{}, caseData{})
-}
-
 // sqlizerBuffer is a helper that allows to write many Sqlizers one by one
 // without constant checks for errors that may come from Sqlizer
 type sqlizerBuffer struct {
@@ -165,11 +160,18 @@ func (d *caseData) ToSql() (sqlStr string, args []any, err error) {
 }
 
 // CaseBuilder builds SQL CASE construct which could be used as parts of queries.
-type CaseBuilder builder.Builder
+type CaseBuilder struct {
+	core[caseData]
+}
+
+// set returns a copy of b with the data changed by f.
+func (b CaseBuilder) set(f func(d *caseData)) CaseBuilder {
+	return CaseBuilder{b.with(f)}
+}
 
 // ToSql builds the query into a SQL string and bound args.
 func (b CaseBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(caseData)
+	data := b.get()
 	return data.ToSql()
 }
 
@@ -185,25 +187,135 @@ func (b CaseBuilder) MustSql() (string, []any) {
 
 // what sets optional value for CASE construct "CASE [value] ..."
 func (b CaseBuilder) what(e any) CaseBuilder {
-	return builder.Set(b, "What", newPart(e)).(CaseBuilder)
+	return b.set(func(d *caseData) { d.What = newPart(e) })
 }
 
 // When adds "WHEN ... THEN ..." part to CASE construct
 func (b CaseBuilder) When(when any, then any) CaseBuilder {
 	// TODO: performance hint: replace slice of WhenPart with just slice of parts
 	// where even indices of the slice belong to "when"s and odd indices belong to "then"s
-	return builder.Append(b, "WhenParts", newWhenPart(when, then)).(CaseBuilder)
+	return b.set(func(d *caseData) { d.WhenParts = appendTo(d.WhenParts, newWhenPart(when, then)) })
 }
 
 // Else What sets optional "ELSE ..." part for CASE construct
 func (b CaseBuilder) Else(e any) CaseBuilder {
 	switch e.(type) {
 	case Sqlizer:
-		return builder.Set(b, "Else", newPart(e)).(CaseBuilder)
+		return b.set(func(d *caseData) { d.Else = newPart(e) })
 	default:
 		if e == nil {
-			return builder.Set(b, "ElseNull", true).(CaseBuilder)
+			return b.set(func(d *caseData) { d.ElseNull = true })
 		}
-		return builder.Set(b, "ElseValue", e).(CaseBuilder)
+		return b.set(func(d *caseData) { d.ElseValue = e })
+	}
+}
+
+// MapCase returns a CASE construct mapping the values of column to other values,
+// as bound args, with else as the ELSE value (nil for NULL). The WHEN clauses are
+// sorted by key, so that the same map always gives the same SQL.
+//
+// Ex: MapCase("status", map[any]any{1: "active", 2: "banned"}, "unknown")
+// -> "CASE status WHEN ? THEN ? WHEN ? THEN ? ELSE ? END" with args [1 active 2 banned unknown]
+func MapCase(column string, values map[any]any, els any) CaseBuilder {
+	keys := make([]any, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+
+	b := Case(column)
+	for _, k := range keys {
+		wp := whenPart{when: Expr("?", k), thenValue: values[k], nullThen: values[k] == nil}
+		b = b.set(func(d *caseData) { d.WhenParts = appendTo(d.WhenParts, wp) })
+	}
+	return b.Else(els)
+}
+
+// lessKey orders map keys of any type: numbers by value, strings lexically and
+// others by type and formatted value.
+func lessKey(a, b any) bool {
+	if x, ok := numericKey(a); ok {
+		if y, ok := numericKey(b); ok {
+			return x < y
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return x < y
+		}
+	}
+	return fmt.Sprintf("%T %v", a, a) < fmt.Sprintf("%T %v", b, b)
+}
+
+func numericKey(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// ifExpr helps to use two-branch conditionals in SQL query
+type ifExpr struct {
+	pred    Sqlizer
+	then    any
+	els     any
+	dialect Dialect
+}
+
+// If allows to choose between then and els depending on pred, as a shorthand
+// for a CASE construct with a single WHEN clause. then and els are Sqlizers or
+// values bound to placeholders. It is rendered as IF on MySQL, IIF on SQL Server
+// and IFF on Snowflake.
+// Ex: If(Gt{"stock": 0}, "available", "sold out")
+// -> "CASE WHEN stock > ? THEN ? ELSE ? END" with args [0 available sold out]
+func If(pred Sqlizer, then, els any) ifExpr {
+	return ifExpr{pred: pred, then: then, els: els}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e ifExpr) Dialect(d Dialect) ifExpr {
+	e.dialect = d
+	return e
+}
+
+func (e ifExpr) ToSql() (string, []any, error) {
+	if e.pred == nil {
+		return "", nil, errors.New("if expression requires a predicate")
+	}
+
+	predSql, args, err := nestedToSql(e.pred)
+	if err != nil {
+		return "", nil, err
+	}
+	thenSql, thenArgs, err := operandToSql(e.then)
+	if err != nil {
+		return "", nil, err
+	}
+	elseSql, elseArgs, err := operandToSql(e.els)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(append(args, thenArgs...), elseArgs...)
+
+	function := ""
+	switch e.dialect { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB:
+		function = "IF"
+	case DialectMSSQL:
+		function = "IIF"
+	case DialectSnowflake:
+		function = "IFF"
+	}
+	if len(function) > 0 {
+		return fmt.Sprintf("%s(%s, %s, %s)", function, predSql, thenSql, elseSql), args, nil
 	}
+	return fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", predSql, thenSql, elseSql), args, nil
 }
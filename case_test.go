@@ -227,3 +227,48 @@ func TestSqlTypeNameHelper(t *testing.T) {
 		})
 	}
 }
+
+func TestMapCase(t *testing.T) {
+	sql, args, err := Select().
+		Column(Alias(MapCase("status", map[any]any{3: "deleted", 1: "active", 2: nil}, "unknown"), "label")).
+		From("users").
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT (CASE status WHEN $1 THEN $2 WHEN $3 THEN $4 WHEN $5 THEN $6 ELSE $7 END) AS label FROM users", sql)
+	assert.Equal(t, []any{1, "active", 2, nil, 3, "deleted", "unknown"}, args)
+
+	sql, args, err = MapCase("code", map[any]any{"b": 2, "a": 1}, nil).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CASE code WHEN ? THEN ? WHEN ? THEN ? ELSE ? END", sql)
+	assert.Equal(t, []any{"a", 1, "b", 2, nil}, args)
+
+	_, _, err = MapCase("code", map[any]any{}, nil).ToSql()
+	assert.Error(t, err)
+}
+
+func TestIf(t *testing.T) {
+	sql, args, err := Select().
+		Column(Alias(If(Gt{"stock": 0}, "available", "sold out"), "availability")).
+		From("products").
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT (CASE WHEN stock > ? THEN ? ELSE ? END) AS availability FROM products", sql)
+	assert.Equal(t, []any{0, "available", "sold out"}, args)
+
+	sql, args, err = If(Eq{"deleted": true}, Expr("NULL"), Expr("name")).Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "IF(deleted = ?, NULL, name)", sql)
+	assert.Equal(t, []any{true}, args)
+
+	sql, _, err = If(Expr("a > b"), 1, 0).Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "IIF(a > b, ?, ?)", sql)
+
+	sql, _, err = If(Expr("a > b"), 1, 0).Dialect(DialectSnowflake).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "IFF(a > b, ?, ?)", sql)
+
+	_, _, err = If(nil, 1, 0).ToSql()
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package squirrel
+
+import (
+	"fmt"
+)
+
+// claimQuery helps to claim rows of a table-backed job queue
+type claimQuery struct {
+	table             string
+	filter            Sqlizer
+	limit             uint64
+	set               map[string]any
+	idColumn          string
+	orderBys          []string
+	dialect           Dialect
+	placeholderFormat PlaceholderFormat
+}
+
+// ClaimRows allows workers to claim up to limit rows of table matching filter
+// (see SelectBuilder.Where) without waiting for, or claiming twice, the rows
+// claimed by other workers: the rows are locked with FOR UPDATE SKIP LOCKED and
+// updated with set, e.g. to mark them as running.
+//
+// ToSql builds a single UPDATE statement (PostgreSQL, SQLite). For MySQL and
+// MariaDB, which don't have RETURNING, run Select then Update with the selected
+// ids in a transaction.
+// Ex:
+//
+//	ClaimRows("jobs", Eq{"status": "queued"}, 10, map[string]any{"status": "running"}).OrderBy("id")
+//	// UPDATE jobs SET status = ? WHERE id IN (SELECT id FROM jobs WHERE status = ?
+//	// ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED) RETURNING *
+func ClaimRows(table string, filter any, limit uint64, set map[string]any) claimQuery {
+	return claimQuery{table: table, filter: newWherePart(filter), limit: limit, set: set, idColumn: "id"}
+}
+
+// IDColumn sets the key column of the rows, id by default.
+func (q claimQuery) IDColumn(column string) claimQuery {
+	q.idColumn = column
+	return q
+}
+
+// OrderBy sets the order the rows are claimed in, e.g. by priority or age.
+func (q claimQuery) OrderBy(orderBys ...string) claimQuery {
+	q.orderBys = append(q.orderBys[:len(q.orderBys):len(q.orderBys)], orderBys...)
+	return q
+}
+
+// Dialect sets the dialect used to render the queries.
+// The placeholder format preferred by the dialect is set as well.
+func (q claimQuery) Dialect(d Dialect) claimQuery {
+	q.dialect = d
+	if f := d.PlaceholderFormat(); f != nil {
+		q.placeholderFormat = f
+	}
+	return q
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the queries.
+func (q claimQuery) PlaceholderFormat(f PlaceholderFormat) claimQuery {
+	q.placeholderFormat = f
+	return q
+}
+
+// Select returns the query locking the ids of the rows to claim.
+func (q claimQuery) Select() (SelectBuilder, error) {
+	if len(q.table) == 0 || q.limit == 0 {
+		return SelectBuilder{}, fmt.Errorf("claim rows requires a table and a limit")
+	}
+
+	sel := Select(q.idColumn).From(q.table).Where(q.filter).OrderBy(q.orderBys...).Limit(q.limit)
+	switch q.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectMySQL, DialectMariaDB:
+		sel = sel.Suffix("FOR UPDATE SKIP LOCKED")
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		// writes are serialized, there are no row locks
+	default:
+		return SelectBuilder{}, q.dialect.unsupportedError("FOR UPDATE SKIP LOCKED")
+	}
+
+	sel = sel.Dialect(q.dialect)
+	if q.placeholderFormat != nil {
+		sel = sel.PlaceholderFormat(q.placeholderFormat)
+	}
+	return sel, nil
+}
+
+// Update returns the statement claiming the rows with the given ids, as returned
+// by the Select query.
+func (q claimQuery) Update(ids ...any) UpdateBuilder {
+	return q.update(Eq{q.idColumn: ids})
+}
+
+func (q claimQuery) update(pred Sqlizer) UpdateBuilder {
+	upd := Update(q.table).SetMap(q.set).Where(pred).Dialect(q.dialect)
+	if q.placeholderFormat != nil {
+		upd = upd.PlaceholderFormat(q.placeholderFormat)
+	}
+	return upd
+}
+
+// ToSql builds the single statement claiming the rows and returning them.
+func (q claimQuery) ToSql() (string, []any, error) {
+	if len(q.set) == 0 {
+		return "", nil, fmt.Errorf("claim rows requires at least one column to set")
+	}
+	sel, err := q.Select()
+	if err != nil {
+		return "", nil, err
+	}
+	if !q.dialect.supportsReturning() {
+		return "", nil, fmt.Errorf("claim rows for %s dialect requires Select and Update in a transaction", q.dialect)
+	}
+	return q.update(Eq{q.idColumn: sel}).Returning("*").ToSql()
+}
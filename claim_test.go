@@ -0,0 +1,52 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimRows(t *testing.T) {
+	q := ClaimRows("jobs", Eq{"status": "queued"}, 10, map[string]any{"status": "running"}).OrderBy("priority DESC", "id")
+
+	sql, args, err := q.Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE jobs SET status = $1 WHERE id IN (SELECT id FROM jobs WHERE status = $2 "+
+		"ORDER BY priority DESC, id LIMIT 10 FOR UPDATE SKIP LOCKED) RETURNING *", sql)
+	assert.Equal(t, []any{"running", "queued"}, args)
+
+	sql, _, err = q.Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE jobs SET status = ? WHERE id IN (SELECT id FROM jobs WHERE status = ? "+
+		"ORDER BY priority DESC, id LIMIT 10) RETURNING *", sql)
+}
+
+func TestClaimRowsSelectUpdate(t *testing.T) {
+	q := ClaimRows("jobs", "run_at <= NOW()", 5, map[string]any{"status": "running"}).
+		IDColumn("job_id").Dialect(DialectMySQL)
+
+	_, _, err := q.ToSql()
+	assert.Error(t, err)
+
+	sel, err := q.Select()
+	assert.NoError(t, err)
+	sql, _, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT job_id FROM jobs WHERE run_at <= NOW() LIMIT 5 FOR UPDATE SKIP LOCKED", sql)
+
+	sql, args, err := q.Update(1, 2).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE jobs SET status = ? WHERE job_id IN (?,?)", sql)
+	assert.Equal(t, []any{"running", 1, 2}, args)
+}
+
+func TestClaimRowsErr(t *testing.T) {
+	_, err := ClaimRows("jobs", nil, 0, nil).Select()
+	assert.Error(t, err)
+
+	_, _, err = ClaimRows("jobs", nil, 1, map[string]any{"status": "running"}).Dialect(DialectMSSQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = ClaimRows("jobs", nil, 1, nil).ToSql()
+	assert.Error(t, err)
+}
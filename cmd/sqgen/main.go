@@ -0,0 +1,54 @@
+// Command sqgen generates Go constants and typed Column handles for the tables
+// and columns of a DDL file, to be used in place of string literals in builders.
+//
+//	//go:generate go run github.com/ViniciusIth/squirrel/cmd/sqgen -ddl schema.sql -pkg models -o tables.go
+//
+// To generate them from a live database instead, query information_schema with
+// squirrel.SchemaColumnsQuery and pass the result of squirrel.ScanSchemaColumns to
+// squirrel.WriteGoTables from a generator of your own, which imports the driver.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	sq "github.com/ViniciusIth/squirrel"
+)
+
+func main() {
+	ddl := flag.String("ddl", "", "DDL file with the CREATE TABLE statements")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package of the generated file")
+	out := flag.String("o", "", "generated file (standard output if empty)")
+	flag.Parse()
+
+	if err := run(*ddl, *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "sqgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ddl, pkg, out string) error {
+	if ddl == "" || pkg == "" {
+		return fmt.Errorf("-ddl and -pkg are required")
+	}
+	src, err := os.ReadFile(ddl)
+	if err != nil {
+		return err
+	}
+	schema, err := sq.ParseSchemaDDLColumns(string(src))
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := sq.WriteGoTables(buf, pkg, schema); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = buf.WriteTo(os.Stdout)
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	ddl := filepath.Join(dir, "schema.sql")
+	out := filepath.Join(dir, "tables.go")
+	assert.NoError(t, os.WriteFile(ddl, []byte("CREATE TABLE users (id bigint PRIMARY KEY, email text);"), 0o644))
+
+	assert.NoError(t, run(ddl, "models", out))
+	src, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(src), "// Code generated by squirrel. DO NOT EDIT.\n\npackage models\n"))
+	assert.Contains(t, string(src), "const UsersTable = \"users\"\n")
+	assert.Contains(t, string(src), "sq.Column[int64]")
+
+	assert.Error(t, run(ddl, "", out))
+	assert.Error(t, run(filepath.Join(dir, "missing.sql"), "models", out))
+	assert.NoError(t, os.WriteFile(ddl, []byte("CREATE TABLE users (id bigint"), 0o644))
+	assert.Error(t, run(ddl, "models", out))
+}
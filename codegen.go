@@ -0,0 +1,259 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	queriesMutex sync.RWMutex
+	queries      = map[string]Sqlizer{}
+)
+
+// RegisterQuery registers q under name to be rendered by WriteSQLFiles and
+// WriteGoConstants, typically from a package-level var or an init function.
+// It panics if name is registered twice.
+// Ex:
+//
+//	var _ = RegisterQuery("UserByID", Select("id", "name").From("users").Where("id = ?"))
+func RegisterQuery(name string, q Sqlizer) Sqlizer {
+	queriesMutex.Lock()
+	defer queriesMutex.Unlock()
+
+	if _, ok := queries[name]; ok {
+		panic(fmt.Sprintf("squirrel: query %s registered twice", name))
+	}
+	queries[name] = q
+	return q
+}
+
+// renderQueries renders the registered queries, sorted by name.
+func renderQueries() ([]string, []string, error) {
+	queriesMutex.RLock()
+	defer queriesMutex.RUnlock()
+
+	names := make([]string, 0, len(queries))
+	for name := range queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sqls := make([]string, len(names))
+	for i, name := range names {
+		sql, _, err := queries[name].ToSql()
+		if err != nil {
+			return nil, nil, fmt.Errorf("query %s: %w", name, err)
+		}
+		sqls[i] = sql
+	}
+	return names, sqls, nil
+}
+
+// WriteSQLFiles renders every registered query into dir/<name>.sql, e.g. for review
+// or static analysis of the generated SQL. Bound args are kept as placeholders.
+// Ex:
+//
+//	//go:generate go run ./internal/gensql
+//	func main() { sq.WriteSQLFiles("sql") }
+func WriteSQLFiles(dir string) error {
+	names, sqls, err := renderQueries()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i, name := range names {
+		if strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("query name %s is not a valid file name", name)
+		}
+		path := filepath.Join(dir, name+".sql")
+		if err := os.WriteFile(path, []byte(sqls[i]+";\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteGoConstants renders every registered query as a Go string constant of
+// package pkg named after the query, so production builds can use SQL fixed at
+// generation time.
+func WriteGoConstants(w io.Writer, pkg string) error {
+	names, sqls, err := renderQueries()
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	_, _ = buf.WriteString("// Code generated by squirrel. DO NOT EDIT.\n\n")
+	_, _ = fmt.Fprintf(buf, "package %s\n", pkg)
+	if len(names) > 0 {
+		_, _ = buf.WriteString("\nconst (\n")
+		for i, name := range names {
+			if !token.IsIdentifier(name) {
+				return fmt.Errorf("query name %s is not a valid Go identifier", name)
+			}
+			_, _ = fmt.Fprintf(buf, "\t%s = %s\n", name, strconv.Quote(sqls[i]))
+		}
+		_, _ = buf.WriteString(")\n")
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// goInitialisms are the words written in upper case in Go identifiers.
+var goInitialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "UUID": true, "API": true, "HTTP": true,
+	"JSON": true, "SQL": true, "IP": true, "HTML": true, "XML": true,
+}
+
+// goIdentifier returns the exported Go identifier of a table or column name,
+// e.g. UserID for user_id.
+func goIdentifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	buf := &strings.Builder{}
+	for _, word := range words {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			_, _ = buf.WriteString(upper)
+		} else {
+			for i, r := range word {
+				if i == 0 {
+					_, _ = buf.WriteRune(unicode.ToUpper(r))
+				} else {
+					_, _ = buf.WriteRune(unicode.ToLower(r))
+				}
+			}
+		}
+	}
+	id := buf.String()
+	// an identifier is exported if it starts with an upper case letter, which
+	// digits and letters without case, e.g. Japanese, are not
+	if first, _ := utf8.DecodeRuneInString(id); !unicode.IsUpper(first) {
+		id = "X" + id
+	}
+	return id
+}
+
+// goType returns the Go type of the values of a column of SQL type sqlType,
+// any if unknown.
+func goType(sqlType string) string {
+	t := strings.ToLower(sqlType)
+	if strings.HasSuffix(t, "[]") || strings.HasPrefix(t, "array") {
+		return "any"
+	}
+	if i := strings.IndexAny(t, "( "); i >= 0 {
+		t = t[:i]
+	}
+	switch t {
+	case "int", "integer", "bigint", "smallint", "tinyint", "mediumint",
+		"int2", "int4", "int8", "serial", "bigserial", "smallserial":
+		return "int64"
+	case "real", "float", "float4", "float8", "double", "binary_float", "binary_double":
+		return "float64"
+	case "bool", "boolean", "bit":
+		return "bool"
+	case "text", "char", "character", "varchar", "nchar", "nvarchar", "varchar2", "nvarchar2",
+		"uuid", "citext", "tinytext", "mediumtext", "longtext", "clob", "nclob", "enum",
+		"decimal", "numeric", "json", "jsonb", "time", "interval",
+		"xml", "inet", "cidr", "macaddr":
+		return "string"
+	case "date", "datetime", "datetime2", "timestamp", "timestamptz", "smalldatetime", "datetimeoffset":
+		return "time.Time"
+	case "bytea", "blob", "binary", "varbinary", "tinyblob", "mediumblob", "longblob", "raw":
+		return "[]byte"
+	}
+	return "any"
+}
+
+// WriteGoTables renders the tables of schema, e.g. read by ParseSchemaDDLColumns
+// or ScanSchemaColumns, as Go code of package pkg: a constant with the name of
+// every table, and a variable with a Column handle per column, typed after the
+// SQL type of the column, so builder code doesn't need string literals.
+// Ex:
+//
+//	//go:generate go run github.com/ViniciusIth/squirrel/cmd/sqgen -ddl schema.sql -pkg models -o tables.go
+//
+//	// const UsersTable = "users"
+//	// var Users = struct {
+//	// 	ID    sq.Column[int64]
+//	// 	Email sq.Column[string]
+//	// }{
+//	// 	ID:    sq.Col[int64]("id"),
+//	// 	Email: sq.Col[string]("email"),
+//	// }
+func WriteGoTables(w io.Writer, pkg string, schema map[string][]SchemaColumn) error {
+	tables := make([]string, 0, len(schema))
+	for table := range schema {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	body := &bytes.Buffer{}
+	usesTime := false
+	idents := map[string]string{}
+	for _, table := range tables {
+		name := goIdentifier(table)
+		if other, ok := idents[name]; ok {
+			return fmt.Errorf("tables %s and %s are both named %s", other, table, name)
+		}
+		idents[name], idents[name+"Table"] = table, table
+
+		_, _ = fmt.Fprintf(body, "\n// %sTable is the name of the %s table.\nconst %sTable = %s\n", name, table, name, strconv.Quote(table))
+		_, _ = fmt.Fprintf(body, "\n// %s has the columns of the %s table.\nvar %s = struct {\n", name, table, name)
+		fields := make([]string, len(schema[table]))
+		types := make([]string, len(schema[table]))
+		seen := map[string]string{}
+		for i, column := range schema[table] {
+			fields[i], types[i] = goIdentifier(column.Name), goType(column.Type)
+			if other, ok := seen[fields[i]]; ok {
+				return fmt.Errorf("columns %s and %s of table %s are both named %s", other, column.Name, table, fields[i])
+			}
+			seen[fields[i]] = column.Name
+			usesTime = usesTime || types[i] == "time.Time"
+			_, _ = fmt.Fprintf(body, "\t%s sq.Column[%s]\n", fields[i], types[i])
+		}
+		_, _ = body.WriteString("}{\n")
+		for i, column := range schema[table] {
+			_, _ = fmt.Fprintf(body, "\t%s: sq.Col[%s](%s),\n", fields[i], types[i], strconv.Quote(column.Name))
+		}
+		_, _ = body.WriteString("}\n")
+	}
+
+	buf := &bytes.Buffer{}
+	_, _ = buf.WriteString("// Code generated by squirrel. DO NOT EDIT.\n\n")
+	_, _ = fmt.Fprintf(buf, "package %s\n", pkg)
+	if len(tables) > 0 {
+		_, _ = buf.WriteString("\nimport (\n")
+		if usesTime {
+			_, _ = buf.WriteString("\t\"time\"\n\n")
+		}
+		_, _ = buf.WriteString("\tsq \"github.com/ViniciusIth/squirrel\"\n)\n")
+		_, _ = body.WriteTo(buf)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
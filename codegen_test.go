@@ -0,0 +1,115 @@
+package squirrel
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func resetQueries(t *testing.T) {
+	t.Cleanup(func() {
+		queriesMutex.Lock()
+		queries = map[string]Sqlizer{}
+		queriesMutex.Unlock()
+	})
+}
+
+func TestWriteGoConstants(t *testing.T) {
+	resetQueries(t)
+
+	RegisterQuery("UserByID", Select("id", "name").From("users").Where("id = ?").PlaceholderFormat(Dollar))
+	RegisterQuery("DeleteUser", Delete("users").Where("id = ?"))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteGoConstants(buf, "queries"))
+
+	expected := "// Code generated by squirrel. DO NOT EDIT.\n\n" +
+		"package queries\n\n" +
+		"const (\n" +
+		"\tDeleteUser = \"DELETE FROM users WHERE id = ?\"\n" +
+		"\tUserByID   = \"SELECT id, name FROM users WHERE id = $1\"\n" +
+		")\n"
+	assert.Equal(t, expected, buf.String())
+
+	assert.Panics(t, func() { RegisterQuery("UserByID", Select("1")) })
+}
+
+func TestWriteGoConstantsErr(t *testing.T) {
+	resetQueries(t)
+
+	RegisterQuery("user-by-id", Select("id").From("users"))
+	assert.Error(t, WriteGoConstants(&bytes.Buffer{}, "queries"))
+}
+
+func TestWriteSQLFiles(t *testing.T) {
+	resetQueries(t)
+
+	RegisterQuery("user_by_id", Select("id").From("users").Where("id = ?"))
+
+	dir := filepath.Join(t.TempDir(), "sql")
+	assert.NoError(t, WriteSQLFiles(dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, "user_by_id.sql"))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE id = ?;\n", string(content))
+
+	RegisterQuery("broken", Select())
+	assert.Error(t, WriteSQLFiles(dir))
+}
+
+func TestWriteGoTables(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteGoTables(buf, "models", map[string][]SchemaColumn{
+		"users": {{"id", "bigint"}, {"email", "varchar(255)"}, {"created_at", "timestamp"}, {"tags", "text[]"}},
+		"orgs":  {{"id", "INT"}, {"api_url", "text"}},
+	}))
+
+	expected := "// Code generated by squirrel. DO NOT EDIT.\n\n" +
+		"package models\n\n" +
+		"import (\n" +
+		"\t\"time\"\n\n" +
+		"\tsq \"github.com/ViniciusIth/squirrel\"\n" +
+		")\n\n" +
+		"// OrgsTable is the name of the orgs table.\n" +
+		"const OrgsTable = \"orgs\"\n\n" +
+		"// Orgs has the columns of the orgs table.\n" +
+		"var Orgs = struct {\n" +
+		"\tID     sq.Column[int64]\n" +
+		"\tAPIURL sq.Column[string]\n" +
+		"}{\n" +
+		"\tID:     sq.Col[int64](\"id\"),\n" +
+		"\tAPIURL: sq.Col[string](\"api_url\"),\n" +
+		"}\n\n" +
+		"// UsersTable is the name of the users table.\n" +
+		"const UsersTable = \"users\"\n\n" +
+		"// Users has the columns of the users table.\n" +
+		"var Users = struct {\n" +
+		"\tID        sq.Column[int64]\n" +
+		"\tEmail     sq.Column[string]\n" +
+		"\tCreatedAt sq.Column[time.Time]\n" +
+		"\tTags      sq.Column[any]\n" +
+		"}{\n" +
+		"\tID:        sq.Col[int64](\"id\"),\n" +
+		"\tEmail:     sq.Col[string](\"email\"),\n" +
+		"\tCreatedAt: sq.Col[time.Time](\"created_at\"),\n" +
+		"\tTags:      sq.Col[any](\"tags\"),\n" +
+		"}\n"
+	assert.Equal(t, expected, buf.String())
+
+	err := WriteGoTables(&bytes.Buffer{}, "models", map[string][]SchemaColumn{
+		"users": {{"user_id", "int"}, {"USER_ID", "int"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestGoIdentifier(t *testing.T) {
+	assert.Equal(t, "UserID", goIdentifier("user_id"))
+	assert.Equal(t, "APIURL", goIdentifier("api-url"))
+	assert.Equal(t, "X2fa", goIdentifier("2fa"))
+	assert.Equal(t, "ÉtéÀ", goIdentifier("été_à"))
+	assert.Equal(t, "X名前", goIdentifier("名前"))
+	assert.Equal(t, "X", goIdentifier("__"))
+}
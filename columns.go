@@ -0,0 +1,99 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type starReplace struct {
+	column string
+	expr   Sqlizer
+}
+
+// starExpr helps to use * with EXCLUDE / REPLACE modifiers in SQL query
+type starExpr struct {
+	table   string
+	exclude []string
+	replace []starReplace
+	dialect Dialect
+	err     error
+}
+
+// Star allows to use * in the select list, optionally qualified with a table,
+// and with star modifiers (DuckDB, Snowflake, BigQuery, ClickHouse).
+// More than one table makes ToSql return an error.
+// Ex:
+//
+//	Select().Column(Star("u")) // SELECT u.*
+//	Select().Column(Star().Exclude("secret")) // SELECT * EXCLUDE (secret)
+//	Select().Column(Star().Replace("name", Expr("lower(name)"))) // SELECT * REPLACE (lower(name) AS name)
+func Star(table ...string) starExpr {
+	switch len(table) {
+	case 0:
+		return starExpr{}
+	case 1:
+		return starExpr{table: table[0]}
+	default:
+		return starExpr{err: fmt.Errorf("Star takes at most one table, got %d", len(table))}
+	}
+}
+
+// Exclude removes columns from the result.
+// Rendered as EXCEPT for DialectBigQuery and DialectClickHouse.
+func (e starExpr) Exclude(columns ...string) starExpr {
+	e.exclude = append(e.exclude[:len(e.exclude):len(e.exclude)], columns...)
+	return e
+}
+
+// Replace replaces the value of column with expr in the result.
+func (e starExpr) Replace(column string, expr Sqlizer) starExpr {
+	e.replace = append(e.replace[:len(e.replace):len(e.replace)], starReplace{column: column, expr: expr})
+	return e
+}
+
+// Dialect sets the dialect used to render the star modifiers.
+func (e starExpr) Dialect(d Dialect) starExpr {
+	e.dialect = d
+	return e
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (e starExpr) ToSql() (sql string, args []any, err error) {
+	if e.err != nil {
+		return "", nil, e.err
+	}
+	if (len(e.exclude) > 0 || len(e.replace) > 0) && !e.dialect.supportsStarModifiers() {
+		return "", nil, e.dialect.unsupportedError("star modifiers")
+	}
+
+	buf := &bytes.Buffer{}
+	if len(e.table) > 0 {
+		_, _ = buf.WriteString(e.table)
+		_, _ = buf.WriteString(".")
+	}
+	_, _ = buf.WriteString("*")
+
+	if len(e.exclude) > 0 {
+		keyword := "EXCLUDE"
+		if e.dialect == DialectBigQuery || e.dialect == DialectClickHouse {
+			keyword = "EXCEPT"
+		}
+		_, _ = fmt.Fprintf(buf, " %s (%s)", keyword, strings.Join(e.exclude, ", "))
+	}
+
+	if len(e.replace) > 0 {
+		replaces := make([]string, 0, len(e.replace))
+		for _, r := range e.replace {
+			rSql, rArgs, err := nestedToSql(r.expr)
+			if err != nil {
+				return "", nil, err
+			}
+			replaces = append(replaces, fmt.Sprintf("%s AS %s", rSql, r.column))
+			args = append(args, rArgs...)
+		}
+		_, _ = fmt.Fprintf(buf, " REPLACE (%s)", strings.Join(replaces, ", "))
+	}
+
+	return buf.String(), args, nil
+}
@@ -0,0 +1,41 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStar(t *testing.T) {
+	sql, args, err := Star().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "*", sql)
+	assert.Empty(t, args)
+
+	s := Star().Exclude("secret", "token").Replace("name", Expr("upper(?)", "x"))
+
+	sql, args, err = s.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "* EXCLUDE (secret, token) REPLACE (upper(?) AS name)", sql)
+	assert.Equal(t, []any{"x"}, args)
+
+	sql, _, err = s.Dialect(DialectBigQuery).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "* EXCEPT (secret, token) REPLACE (upper(?) AS name)", sql)
+
+	_, _, err = s.Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+}
+
+func TestStarTable(t *testing.T) {
+	sql, _, err := Select().Column(Star("u")).Column("o.total").From("users u").Join("orders o ON o.user_id = u.id").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT u.*, o.total FROM users u JOIN orders o ON o.user_id = u.id", sql)
+
+	sql, _, err = Star("u").Exclude("secret").Dialect(DialectDuckDB).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "u.* EXCLUDE (secret)", sql)
+
+	_, _, err = Select().Column(Star("u", "o")).From("users u").ToSql()
+	assert.EqualError(t, err, "Star takes at most one table, got 2")
+}
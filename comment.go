@@ -0,0 +1,70 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// commentStatement helps to set the comment of schema objects
+type commentStatement struct {
+	objectType string
+	name       string
+	text       string
+	dialect    Dialect
+}
+
+// CommentOn allows to set the comment of a schema object: objectType is e.g.
+// TABLE, VIEW or COLUMN (with a "table.column" name). The text is rendered as a
+// string literal of the dialect; an empty text removes the comment.
+// MySQL and ClickHouse use ALTER TABLE; MySQL column comments are not supported,
+// as they require the whole column definition.
+// Ex: CommentOn("COLUMN", "users.email", "Login, unique") -> "COMMENT ON COLUMN users.email IS 'Login, unique'"
+func CommentOn(objectType, name, text string) commentStatement {
+	return commentStatement{objectType: objectType, name: name, text: text}
+}
+
+// Dialect sets the dialect used to render the statement.
+func (s commentStatement) Dialect(d Dialect) commentStatement {
+	s.dialect = d
+	return s
+}
+
+var commentObjectTypeRegexp = regexp.MustCompile(`^[A-Za-z]+( [A-Za-z]+)*$`)
+
+func (s commentStatement) ToSql() (string, []any, error) {
+	if len(s.name) == 0 || !commentObjectTypeRegexp.MatchString(s.objectType) {
+		return "", nil, fmt.Errorf("comment requires an object type and a name")
+	}
+
+	objectType := strings.ToUpper(s.objectType)
+	text := s.dialect.stringLiteral(s.text)
+	table, column := s.name, ""
+	if objectType == "COLUMN" {
+		i := strings.LastIndex(s.name, ".")
+		if i < 0 {
+			return "", nil, fmt.Errorf("column comment requires a table.column name")
+		}
+		table, column = s.name[:i], s.name[i+1:]
+	}
+
+	switch s.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectOracle, DialectOracleLegacy, DialectSnowflake, DialectDuckDB:
+		if len(s.text) == 0 && !s.dialect.isOracle() {
+			text = "NULL"
+		}
+		return fmt.Sprintf("COMMENT ON %s %s IS %s", objectType, s.name, text), nil, nil
+	case DialectMySQL, DialectMariaDB:
+		if objectType == "TABLE" {
+			return fmt.Sprintf("ALTER TABLE %s COMMENT = %s", table, text), nil, nil
+		}
+	case DialectClickHouse:
+		switch objectType {
+		case "TABLE":
+			return fmt.Sprintf("ALTER TABLE %s MODIFY COMMENT %s", table, text), nil, nil
+		case "COLUMN":
+			return fmt.Sprintf("ALTER TABLE %s COMMENT COLUMN %s %s", table, column, text), nil, nil
+		}
+	}
+	return "", nil, s.dialect.unsupportedError("COMMENT ON " + objectType)
+}
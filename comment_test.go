@@ -0,0 +1,42 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentOn(t *testing.T) {
+	tests := []struct {
+		s    commentStatement
+		want string
+	}{
+		{CommentOn("table", "users", "App users"), "COMMENT ON TABLE users IS 'App users'"},
+		{CommentOn("COLUMN", "users.email", "User's login"), "COMMENT ON COLUMN users.email IS 'User''s login'"},
+		{CommentOn("TABLE", "users", "").Dialect(DialectPostgres), "COMMENT ON TABLE users IS NULL"},
+		{CommentOn("TABLE", "users", "").Dialect(DialectOracle), "COMMENT ON TABLE users IS ''"},
+		{CommentOn("TABLE", "users", `C:\data`).Dialect(DialectMySQL), `ALTER TABLE users COMMENT = 'C:\\data'`},
+		{CommentOn("TABLE", "db.users", "x").Dialect(DialectClickHouse), "ALTER TABLE db.users MODIFY COMMENT 'x'"},
+		{CommentOn("COLUMN", "db.users.email", "x").Dialect(DialectClickHouse), "ALTER TABLE db.users COMMENT COLUMN email 'x'"},
+	}
+	for _, tt := range tests {
+		sql, args, err := tt.s.ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, sql)
+		assert.Empty(t, args)
+	}
+}
+
+func TestCommentOnErr(t *testing.T) {
+	_, _, err := CommentOn("COLUMN", "users.email", "x").Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = CommentOn("COLUMN", "email", "x").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = CommentOn("TABLE; DROP", "users", "x").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = CommentOn("TABLE", "users", "x").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+}
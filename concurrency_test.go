@@ -0,0 +1,133 @@
+package squirrel
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runConcurrently calls f from n goroutines at once and returns the errors.
+func runConcurrently(n int, f func(i int) error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// checkSql returns an error if s doesn't build into sql and args.
+func checkSql(s Sqlizer, sql string, args ...any) error {
+	gotSql, gotArgs, err := s.ToSql()
+	if err != nil {
+		return err
+	}
+	if gotSql != sql || !reflect.DeepEqual(gotArgs, args) {
+		return fmt.Errorf("got %q %v, want %q %v", gotSql, gotArgs, sql, args)
+	}
+	return nil
+}
+
+func TestConcurrentSelectBranches(t *testing.T) {
+	base := StatementBuilder.PlaceholderFormat(Dollar).
+		Select("id", "name").From("users").
+		Where(Eq{"org_id": 7}).OrderBy("created_at DESC", "id")
+
+	errs := runConcurrently(64, func(i int) error {
+		b := base.Where(Gt{"score": i}).Column(fmt.Sprintf("%d AS n", i))
+		if i%2 == 0 {
+			b = b.Join("teams t ON t.id = users.team_id").Limit(uint64(i + 1))
+			return checkSql(b,
+				fmt.Sprintf("SELECT id, name, %d AS n FROM users JOIN teams t ON t.id = users.team_id "+
+					"WHERE org_id = $1 AND score > $2 ORDER BY created_at DESC, id LIMIT %d", i, i+1),
+				7, i)
+		}
+		b = b.PaginateByKeyset(10, "2024-01-01", i)
+		return checkSql(b,
+			fmt.Sprintf("SELECT id, name, %d AS n FROM users WHERE org_id = $1 AND score > $2 AND "+
+				"(created_at < $3 OR (created_at = $4 AND id > $5)) ORDER BY created_at DESC, id LIMIT 10", i),
+			7, i, "2024-01-01", "2024-01-01", i)
+	})
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, checkSql(base,
+		"SELECT id, name FROM users WHERE org_id = $1 ORDER BY created_at DESC, id", 7))
+}
+
+func TestConcurrentWriteBranches(t *testing.T) {
+	update := Update("users").Set("active", false).Where(Eq{"org_id": 7})
+	insert := Insert("users").Columns("id", "name")
+	del := Delete("users").Where(Eq{"org_id": 7})
+
+	errs := runConcurrently(64, func(i int) error {
+		switch i % 3 {
+		case 0:
+			return checkSql(update.Set("score", i).Where(Eq{"id": i}),
+				"UPDATE users SET active = ?, score = ? WHERE org_id = ? AND id = ?", false, i, 7, i)
+		case 1:
+			return checkSql(insert.Values(i, "n").Suffix("RETURNING id"),
+				"INSERT INTO users (id,name) VALUES (?,?) RETURNING id", i, "n")
+		default:
+			return checkSql(del.Where(Eq{"id": i}).Limit(1),
+				"DELETE FROM users WHERE org_id = ? AND id = ? LIMIT 1", 7, i)
+		}
+	})
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestConcurrentUnionBranches(t *testing.T) {
+	base := UnionAll(Select("id").From("a"), Select("id").From("b")).OrderBy("id")
+
+	errs := runConcurrently(64, func(i int) error {
+		return checkSql(base.UnionAll(Select("id").From("c").Where(Eq{"x": i})).Limit(uint64(i)),
+			fmt.Sprintf("(SELECT id FROM a) UNION ALL (SELECT id FROM b) UNION ALL "+
+				"(SELECT id FROM c WHERE x = ?) ORDER BY id LIMIT %d", i), i)
+	})
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestConcurrentRegistries(t *testing.T) {
+	defer RegisterIdents()
+
+	errs := runConcurrently(32, func(i int) error {
+		if i%4 == 0 {
+			RegisterIdents("events_2024_01", "events_2024_02")
+			return nil
+		}
+		_, _ = SafeIdent("events_2024_01")
+		return checkSql(Select("id").From("events").Where(Eq{"id": i}), "SELECT id FROM events WHERE id = ?", i)
+	})
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestBuilderArgsNotAliased(t *testing.T) {
+	cursor := []any{"2024-01-01", 1}
+	b := Select("*").From("posts").OrderBy("created_at", "id").PaginateByKeyset(10, cursor...)
+	cursor[1] = 2
+	_, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"2024-01-01", 1}, args)
+
+	columns := []string{"id"}
+	u := UnionAll(Select("id", "name").From("a")).Align(columns...)
+	columns[0] = "name"
+	sql, _, err := u.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a)", sql)
+}
@@ -0,0 +1,80 @@
+package squirrel
+
+// CondBuilder accumulates conditions into an And / Or tree imperatively.
+// Unlike the other builders it is mutable: every method changes the receiver.
+// An empty CondBuilder evaluates to true, like And{}.
+//
+// Ex:
+//
+//	c := Cond(Eq{"active": true})
+//	c.AndIf(name != "", Like{"name": name + "%"})
+//	c.Or(Eq{"role": "admin"})
+//	Select("*").From("users").Where(c)
+//	// SELECT * FROM users WHERE ((active = ? AND name LIKE ?) OR role = ?)
+type CondBuilder struct {
+	expr Sqlizer
+}
+
+// Cond returns a new CondBuilder starting with the conjunction of preds.
+func Cond(preds ...Sqlizer) *CondBuilder {
+	c := &CondBuilder{}
+	return c.And(preds...)
+}
+
+// And combines the current condition and preds with AND.
+func (c *CondBuilder) And(preds ...Sqlizer) *CondBuilder {
+	if len(preds) == 0 {
+		return c
+	}
+	if c.expr == nil {
+		if len(preds) == 1 {
+			c.expr = preds[0]
+		} else {
+			c.expr = And(preds)
+		}
+		return c
+	}
+	c.expr = append(And{c.expr}, preds...)
+	return c
+}
+
+// Or combines the current condition and preds with OR.
+func (c *CondBuilder) Or(preds ...Sqlizer) *CondBuilder {
+	if len(preds) == 0 {
+		return c
+	}
+	if c.expr == nil {
+		if len(preds) == 1 {
+			c.expr = preds[0]
+		} else {
+			c.expr = Or(preds)
+		}
+		return c
+	}
+	c.expr = append(Or{c.expr}, preds...)
+	return c
+}
+
+// AndIf calls And only if cond is true.
+func (c *CondBuilder) AndIf(cond bool, preds ...Sqlizer) *CondBuilder {
+	if !cond {
+		return c
+	}
+	return c.And(preds...)
+}
+
+// OrIf calls Or only if cond is true.
+func (c *CondBuilder) OrIf(cond bool, preds ...Sqlizer) *CondBuilder {
+	if !cond {
+		return c
+	}
+	return c.Or(preds...)
+}
+
+// ToSql builds the condition into a SQL string and bound args.
+func (c *CondBuilder) ToSql() (string, []any, error) {
+	if c.expr == nil {
+		return sqlTrue, []any{}, nil
+	}
+	return nestedToSql(c.expr)
+}
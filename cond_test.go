@@ -0,0 +1,34 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCond(t *testing.T) {
+	name := "jo"
+	c := Cond(Eq{"active": true})
+	c.AndIf(name != "", Like{"name": name + "%"})
+	c.AndIf(false, Eq{"ignored": 1})
+	c.Or(Eq{"role": "admin"})
+
+	sql, args, err := Select("*").From("users").Where(c).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE ((active = ? AND name LIKE ?) OR role = ?)", sql)
+	assert.Equal(t, []any{true, "jo%", "admin"}, args)
+}
+
+func TestCondOrIf(t *testing.T) {
+	sql, args, err := Cond().OrIf(true, Eq{"a": 1}, Eq{"b": 2}).OrIf(false, Eq{"c": 3}).And(Eq{"d": 4}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "((a = ? OR b = ?) AND d = ?)", sql)
+	assert.Equal(t, []any{1, 2, 4}, args)
+}
+
+func TestCondEmpty(t *testing.T) {
+	sql, args, err := Cond().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=1)", sql)
+	assert.Empty(t, args)
+}
@@ -0,0 +1,35 @@
+package squirrel
+
+import (
+	"context"
+	"fmt"
+)
+
+// TooManyPartsError is returned at build time when a clause of a statement has
+// more parts than the MaxParts of the builder, e.g. for a union of tens of
+// thousands of generated subqueries.
+type TooManyPartsError struct {
+	Clause string
+	Parts  int
+	Max    int
+}
+
+func (e *TooManyPartsError) Error() string {
+	return fmt.Sprintf("squirrel: %s has %d parts, more than the maximum of %d", e.Clause, e.Parts, e.Max)
+}
+
+// checkParts returns a TooManyPartsError if max is set and parts is above it.
+func checkParts(clause string, parts, max int) error {
+	if max > 0 && parts > max {
+		return &TooManyPartsError{Clause: clause, Parts: parts, Max: max}
+	}
+	return nil
+}
+
+// ctxErr returns the error of ctx, nil if ctx is nil or not done.
+func ctxErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,118 @@
+package squirrel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cancelingSqlizer cancels its context when it is built.
+type cancelingSqlizer struct {
+	cancel context.CancelFunc
+}
+
+func (s cancelingSqlizer) ToSql() (string, []any, error) {
+	s.cancel()
+	return "SELECT 1", nil, nil
+}
+
+func TestToSqlContext(t *testing.T) {
+	sql, _, err := Union(Select("1"), Select("2")).ToSqlContext(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT 1) UNION (SELECT 2)", sql)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, _, err = UnionAll(cancelingSqlizer{cancel}, Select("2"), Select("3")).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = Select("*").From("t").Where(Eq{"a": 1}).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	_, _, err = Select("*").From("t").Where(cancelingSqlizer{cancel}).Where(Eq{"a": 1}).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = Insert("t").Values(1).Values(2).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = Update("t").Set("a", 1).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, _, err = Delete("t").ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestToSqlContextNested(t *testing.T) {
+	canceling := func() (context.Context, SelectBuilder) {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, Select("a").From("t").Where(cancelingSqlizer{cancel}).Where(Eq{"b": 1})
+	}
+
+	ctx, sub := canceling()
+	_, _, err := Select("*").FromSelect(sub, "s").ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, sub = canceling()
+	_, _, err = Select("*").From("u").Where(sub).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, sub = canceling()
+	_, _, err = Insert("t2").Columns("a").Select(sub).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, sub = canceling()
+	_, _, err = With("x", Select("1")).Select(sub).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, sub = canceling()
+	_, _, err = Delete("t").Where(Expr("id IN (?)", Select("id").From("x"))).Where(sub).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the ROWNUM emulation of LIMIT
+	ctx, sub = canceling()
+	_, _, err = Select("*").From("u").Limit(5).PrefixExpr(sub).Dialect(DialectOracleLegacy).ToSqlContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMaxParts(t *testing.T) {
+	u := Union(Select("1"), Select("2"), Select("3")).MaxParts(2)
+	_, _, err := u.ToSql()
+	var partsErr *TooManyPartsError
+	if assert.True(t, errors.As(err, &partsErr)) {
+		assert.Equal(t, &TooManyPartsError{Clause: "UNION", Parts: 3, Max: 2}, partsErr)
+	}
+	assert.EqualError(t, err, "squirrel: UNION has 3 parts, more than the maximum of 2")
+
+	_, _, err = u.MaxParts(3).ToSql()
+	assert.NoError(t, err)
+
+	b := StatementBuilder.MaxParts(1)
+	_, _, err = b.Select("a", "b").From("t").ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+	assert.Equal(t, "SELECT", partsErr.Clause)
+
+	_, _, err = b.Select("a").From("t").Where("a = 1").Where("b = 2").ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+	assert.Equal(t, "WHERE", partsErr.Clause)
+
+	_, _, err = b.Insert("t").Values(1).Values(2).ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+	assert.Equal(t, "VALUES", partsErr.Clause)
+
+	_, _, err = b.Union(Select("1"), Select("2")).ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+
+	_, _, err = b.Update("t").Set("a", 1).Set("b", 2).ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+	assert.Equal(t, "SET", partsErr.Clause)
+
+	_, _, err = b.Delete("t").Where("a = 1").Where("b = 2").ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+	assert.Equal(t, "WHERE", partsErr.Clause)
+
+	_, _, err = b.With("x", Select("1")).Cte("y").As(Select("2")).Select(Select("*").From("x")).ToSql()
+	assert.True(t, errors.As(err, &partsErr))
+	assert.Equal(t, "WITH", partsErr.Clause)
+}
@@ -0,0 +1,29 @@
+package squirrel
+
+// core is the immutable state of a builder: every change is made on a copy of
+// data, so a builder can be branched and shared between goroutines.
+type core[D any] struct {
+	data *D
+}
+
+// get returns a copy of the data, the zero data for a zero value builder.
+func (c core[D]) get() D {
+	if c.data == nil {
+		var d D
+		return d
+	}
+	return *c.data
+}
+
+// with returns a core with a copy of the data changed by f.
+func (c core[D]) with(f func(d *D)) core[D] {
+	d := c.get()
+	f(&d)
+	return core[D]{data: &d}
+}
+
+// appendTo returns s with vs appended. The array of s may be shared with other
+// builders, so it is never written to.
+func appendTo[T any](s []T, vs ...T) []T {
+	return append(s[:len(s):len(s)], vs...)
+}
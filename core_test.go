@@ -0,0 +1,48 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoreWith(t *testing.T) {
+	var c core[selectData]
+	assert.Equal(t, selectData{}, c.get())
+
+	c1 := c.with(func(d *selectData) { d.Limit = "1" })
+	c2 := c1.with(func(d *selectData) { d.Limit = "2" })
+	assert.Equal(t, "", c.get().Limit)
+	assert.Equal(t, "1", c1.get().Limit)
+	assert.Equal(t, "2", c2.get().Limit)
+}
+
+func TestAppendToDoesNotShareArrays(t *testing.T) {
+	base := make([]string, 1, 10)
+	base[0] = "a"
+
+	b1 := appendTo(base, "b")
+	b2 := appendTo(base, "c")
+	assert.Equal(t, []string{"a", "b"}, b1)
+	assert.Equal(t, []string{"a", "c"}, b2)
+	assert.Equal(t, []string{"a"}, base)
+}
+
+func BenchmarkSelect(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = Select("id", "name").From("users").
+			Where(Eq{"org_id": 1}).Where("deleted_at IS NULL").
+			OrderBy("id").Limit(10).
+			PlaceholderFormat(Dollar).ToSql()
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = Insert("users").Columns("id", "name").
+			Values(1, "a").Values(2, "b").
+			PlaceholderFormat(Dollar).ToSql()
+	}
+}
@@ -2,9 +2,10 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-
-	"github.com/lann/builder"
+	"strings"
+	"time"
 )
 
 // Common Table Expressions helper
@@ -18,13 +19,24 @@ import (
 
 type commonTableExpressionsData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	TimeLocation      *time.Location
+	SizeLimit         SizeLimit
 	Recursive         bool
 	CurrentCteName    string
+	Prefixes          []Sqlizer
 	Ctes              []Sqlizer
 	Statement         Sqlizer
+	Suffixes          []Sqlizer
+	MaxParts          int
+	Err               error           // first error recorded by a builder method
+	ctx               context.Context // set by ToSqlContext
 }
 
 func (d *commonTableExpressionsData) toSql() (sqlStr string, args []any, err error) {
+	if d.Err != nil {
+		return "", nil, d.Err
+	}
 	if len(d.Ctes) == 0 {
 		err = fmt.Errorf("common table expressions statements must have at least one label and subquery")
 		return "", nil, err
@@ -35,25 +47,93 @@ func (d *commonTableExpressionsData) toSql() (sqlStr string, args []any, err err
 		return "", nil, err
 	}
 
+	if err = ctxErr(d.ctx); err != nil {
+		return "", nil, err
+	}
+	if err = checkParts("WITH", len(d.Ctes), d.MaxParts); err != nil {
+		return "", nil, err
+	}
+
+	names := make(map[string]bool, len(d.Ctes))
+	for _, cte := range d.Ctes {
+		c, ok := cte.(cteExpr)
+		if !ok {
+			continue
+		}
+		name := c.cte
+		if i := strings.Index(name, "("); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.TrimSpace(name)
+		if names[strings.ToLower(name)] {
+			return "", nil, fmt.Errorf("common table expression %s is specified more than once", name)
+		}
+		names[strings.ToLower(name)] = true
+	}
+
+	size := newSizeTracker(d.SizeLimit)
 	sql := &bytes.Buffer{}
 
+	if len(d.Prefixes) > 0 {
+		args, err = appendToSqlContext(d.ctx, d.Prefixes, sql, " ", args)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = size.clause("prefix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+
+		_, _ = sql.WriteString(" ")
+	}
+
 	_, _ = sql.WriteString("WITH ")
 	if d.Recursive {
 		_, _ = sql.WriteString("RECURSIVE ")
 	}
 
-	args, err = appendToSql(d.Ctes, sql, ", ", args)
+	args, err = appendToSqlContext(d.ctx, d.Ctes, sql, ", ", args)
 	if err != nil {
 		return "", nil, err
 	}
+	if err = size.clause("WITH", sql.Len(), len(args)); err != nil {
+		return "", nil, err
+	}
+
+	// The final statement is the top level one, so it gets its DefaultLimit.
+	statement := d.Statement
+	if s, ok := statement.(SelectBuilder); ok {
+		data := s.get()
+		statement = SelectBuilder{core[selectData]{data: data.withDefaultLimit()}}
+	}
 
 	_, _ = sql.WriteString(" ")
-	args, err = appendToSql([]Sqlizer{d.Statement}, sql, "", args)
+	args, err = appendToSqlContext(d.ctx, []Sqlizer{statement}, sql, "", args)
 	if err != nil {
 		return "", nil, err
 	}
+	if err = size.clause("statement", sql.Len(), len(args)); err != nil {
+		return "", nil, err
+	}
+
+	if len(d.Suffixes) > 0 {
+		_, _ = sql.WriteString(" ")
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, sql, " ", args)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = size.clause("suffix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
+	args = bindArgs(normalizeTimes(args, d.TimeLocation))
+	if d.PlaceholderFormat != nil { // zero value builder
+		sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	}
+	if err == nil {
+		err = size.check(len(sqlStr), len(args))
+	}
 	return sqlStr, args, err
 }
 
@@ -64,10 +144,22 @@ func (d *commonTableExpressionsData) ToSql() (sql string, args []any, err error)
 // Builder
 
 // CommonTableExpressionsBuilder builds CTE (Common Table Expressions) SQL statements.
-type CommonTableExpressionsBuilder builder.Builder
+type CommonTableExpressionsBuilder struct {
+	core[commonTableExpressionsData]
+}
 
-func init() {
-	builder.Register(CommonTableExpressionsBuilder{}, commonTableExpressionsData{})
+// set returns a copy of b with the data changed by f.
+func (b CommonTableExpressionsBuilder) set(f func(d *commonTableExpressionsData)) CommonTableExpressionsBuilder {
+	return CommonTableExpressionsBuilder{b.with(f)}
+}
+
+// withErr records err on the builder, to be returned by ToSql.
+// Only the first recorded error is kept.
+func (b CommonTableExpressionsBuilder) withErr(err error) CommonTableExpressionsBuilder {
+	if b.get().Err != nil {
+		return b
+	}
+	return b.set(func(d *commonTableExpressionsData) { d.Err = err })
 }
 
 // Format methods
@@ -75,14 +167,52 @@ func init() {
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
 // query.
 func (b CommonTableExpressionsBuilder) PlaceholderFormat(f PlaceholderFormat) CommonTableExpressionsBuilder {
-	return builder.Set(b, "PlaceholderFormat", f).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.PlaceholderFormat = f })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when there are more than n
+// ctes. 0 means no limit.
+func (b CommonTableExpressionsBuilder) MaxParts(n int) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.MaxParts = n })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement, ctes included, is over l, e.g. SizeLimit{Args: 65535} for
+// PostgreSQL. The SizeLimit of the final statement only applies to itself.
+func (b CommonTableExpressionsBuilder) SizeLimit(l SizeLimit) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.SizeLimit = l })
+}
+
+// TimeLocation converts the time.Time args of the query to loc, e.g. time.UTC,
+// when it is built. A nil loc leaves them as is.
+func (b CommonTableExpressionsBuilder) TimeLocation(loc *time.Location) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.TimeLocation = loc })
+}
+
+// Dialect sets the target database engine (e.g. DialectSQLite) for the query.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b CommonTableExpressionsBuilder) Dialect(d Dialect) CommonTableExpressionsBuilder {
+	b = b.set(func(data *commonTableExpressionsData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
 }
 
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
 func (b CommonTableExpressionsBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(commonTableExpressionsData)
+	data := b.get()
+	return data.ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done, the ctes and the final statement being built with ctx as well.
+func (b CommonTableExpressionsBuilder) ToSqlContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.ToSql()
 }
 
@@ -96,29 +226,51 @@ func (b CommonTableExpressionsBuilder) MustSql() (string, []any) {
 	return sql, args
 }
 
+// Prefix adds an expression to the beginning of the query
+func (b CommonTableExpressionsBuilder) Prefix(sql string, args ...any) CommonTableExpressionsBuilder {
+	return b.PrefixExpr(Expr(sql, args...))
+}
+
+// PrefixExpr adds an expression to the very beginning of the query
+func (b CommonTableExpressionsBuilder) PrefixExpr(e Sqlizer) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.Prefixes = appendTo(d.Prefixes, orNilSqlizer(e, "prefix")) })
+}
+
+// Suffix adds an expression to the end of the query
+func (b CommonTableExpressionsBuilder) Suffix(sql string, args ...any) CommonTableExpressionsBuilder {
+	return b.SuffixExpr(Expr(sql, args...))
+}
+
+// SuffixExpr adds an expression to the end of the query
+func (b CommonTableExpressionsBuilder) SuffixExpr(e Sqlizer) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.Suffixes = appendTo(d.Suffixes, orNilSqlizer(e, "suffix")) })
+}
+
 func (b CommonTableExpressionsBuilder) Recursive(recursive bool) CommonTableExpressionsBuilder {
-	return builder.Set(b, "Recursive", recursive).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.Recursive = recursive })
 }
 
 // Cte starts a new cte
 func (b CommonTableExpressionsBuilder) Cte(cte string) CommonTableExpressionsBuilder {
-	return builder.Set(b, "CurrentCteName", cte).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.CurrentCteName = cte })
 }
 
 // As sets the expression for the Cte
 func (b CommonTableExpressionsBuilder) As(as Sqlizer) CommonTableExpressionsBuilder {
-	data := builder.GetStruct(b).(commonTableExpressionsData)
-	return builder.Append(b, "Ctes", cteExpr{as, data.CurrentCteName}).(CommonTableExpressionsBuilder)
+	data := b.get()
+	return b.set(func(d *commonTableExpressionsData) {
+		d.Ctes = appendTo[Sqlizer](d.Ctes, cteExpr{as, data.CurrentCteName})
+	})
 }
 
 // Select finalizes the CommonTableExpressionsBuilder with a SELECT
 func (b CommonTableExpressionsBuilder) Select(statement SelectBuilder) CommonTableExpressionsBuilder {
-	return builder.Set(b, "Statement", statement).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
 }
 
 // Insert finalizes the CommonTableExpressionsBuilder with an INSERT
 func (b CommonTableExpressionsBuilder) Insert(statement InsertBuilder) CommonTableExpressionsBuilder {
-	return builder.Set(b, "Statement", statement).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
 }
 
 // Replace finalizes the CommonTableExpressionsBuilder with a REPLACE
@@ -128,10 +280,25 @@ func (b CommonTableExpressionsBuilder) Replace(statement InsertBuilder) CommonTa
 
 // Update finalizes the CommonTableExpressionsBuilder with an UPDATE
 func (b CommonTableExpressionsBuilder) Update(statement UpdateBuilder) CommonTableExpressionsBuilder {
-	return builder.Set(b, "Statement", statement).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
 }
 
 // Delete finalizes the CommonTableExpressionsBuilder with a DELETE
 func (b CommonTableExpressionsBuilder) Delete(statement DeleteBuilder) CommonTableExpressionsBuilder {
-	return builder.Set(b, "Statement", statement).(CommonTableExpressionsBuilder)
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
+}
+
+// Union finalizes the CommonTableExpressionsBuilder with a UNION
+func (b CommonTableExpressionsBuilder) Union(statement UnionBuilder) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
+}
+
+// Intersect finalizes the CommonTableExpressionsBuilder with an INTERSECT
+func (b CommonTableExpressionsBuilder) Intersect(statement IntersectBuilder) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
+}
+
+// Except finalizes the CommonTableExpressionsBuilder with an EXCEPT
+func (b CommonTableExpressionsBuilder) Except(statement ExceptBuilder) CommonTableExpressionsBuilder {
+	return b.set(func(d *commonTableExpressionsData) { d.Statement = statement })
 }
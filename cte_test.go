@@ -151,3 +151,43 @@ func TestCTEPlaceholderFormat(t *testing.T) {
 	expectedSql = "WITH table1 AS (SELECT col1, col2 FROM table1 WHERE col1 = $1) UPDATE table2 SET col3 = $2"
 	assert.Equal(t, expectedSql, sql)
 }
+
+func TestCTEPrefixSuffix(t *testing.T) {
+	w := With("lab").As(
+		Select("col").From("tab").Where("a = ?", 2),
+	).Select(
+		Select("col").From("lab").Where("b = ?", 3),
+	).Prefix("/* ? */", 1).Suffix("FOR UPDATE OF ?", 4).PlaceholderFormat(Dollar)
+
+	q, args, err := w.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "/* $1 */ WITH lab AS (SELECT col FROM tab WHERE a = $2) SELECT col FROM lab WHERE b = $3 FOR UPDATE OF $4"
+	assert.Equal(t, expectedSql, q)
+	assert.Equal(t, []any{1, 2, 3, 4}, args)
+}
+
+func TestCTEDuplicateName(t *testing.T) {
+	_, _, err := With("lab").As(Select("a").From("t1")).
+		Cte("LAB(a)").As(Select("a").From("t2")).
+		Select(Select("a").From("lab")).ToSql()
+	assert.EqualError(t, err, "common table expression LAB is specified more than once")
+}
+
+func TestCTETooManyExpressions(t *testing.T) {
+	_, _, err := With("lab", Select("a").From("t1"), Select("a").From("t2")).
+		Select(Select("a").From("lab")).ToSql()
+	assert.EqualError(t, err, "With takes at most one expression for cte lab, got 2")
+}
+
+func TestCTENilSqlizer(t *testing.T) {
+	b := With("a", Select("1")).Select(Select("*").From("a"))
+
+	_, _, err := b.PrefixExpr(nil).ToSql()
+	assert.ErrorIs(t, err, ErrNilSqlizer)
+	assert.EqualError(t, err, "nil Sqlizer in prefix")
+
+	_, _, err = b.SuffixExpr(nil).ToSql()
+	assert.ErrorIs(t, err, ErrNilSqlizer)
+	assert.EqualError(t, err, "nil Sqlizer in suffix")
+}
@@ -0,0 +1,138 @@
+package squirrel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// cursorVersion is the first byte of the encoded cursors, to change the encoding
+// without misreading the cursors already handed out.
+const cursorVersion = 1
+
+// type tags of the encoded cursor values
+const (
+	cursorInt64  = 'i'
+	cursorString = 's'
+	cursorTime   = 't'
+)
+
+// EncodeCursor encodes the keyset cursor values (e.g. the ORDER BY values of the
+// last row of a page) into an opaque URL-safe string for API responses, to be
+// read back with DecodeCursor. Integers, strings and times are supported: it
+// returns an error for values of other types. Integers are decoded as int64 and
+// times in UTC.
+// Ex: EncodeCursor(time.Now(), 42)
+func EncodeCursor(values ...any) (string, error) {
+	buf := []byte{cursorVersion}
+	tmp := make([]byte, binary.MaxVarintLen64)
+
+	putVarint := func(n int64) {
+		buf = append(buf, tmp[:binary.PutVarint(tmp, n)]...)
+	}
+	putUvarint := func(n uint64) {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp, n)]...)
+	}
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			buf = append(buf, cursorString)
+			putUvarint(uint64(len(v)))
+			buf = append(buf, v...)
+		case time.Time:
+			buf = append(buf, cursorTime)
+			putVarint(v.Unix())
+			putUvarint(uint64(v.Nanosecond()))
+		default:
+			rv := reflect.ValueOf(value)
+			switch rv.Kind() { //nolint:exhaustive
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				buf = append(buf, cursorInt64)
+				putVarint(rv.Int())
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				if rv.Uint() > math.MaxInt64 {
+					return "", fmt.Errorf("cursor value %d overflows int64", rv.Uint())
+				}
+				buf = append(buf, cursorInt64)
+				putVarint(int64(rv.Uint()))
+			default:
+				return "", fmt.Errorf("unsupported cursor value type %T", value)
+			}
+		}
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// DecodeCursor decodes a cursor encoded with EncodeCursor. It returns an error if
+// cursor is malformed, e.g. tampered with by the client.
+func DecodeCursor(cursor string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if len(data) == 0 || data[0] != cursorVersion {
+		return nil, errors.New("invalid cursor: unknown version")
+	}
+
+	r := bytes.NewReader(data[1:])
+	var values []any
+	for r.Len() > 0 {
+		tag, _ := r.ReadByte()
+		switch tag {
+		case cursorInt64:
+			n, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor: %w", err)
+			}
+			values = append(values, n)
+		case cursorString:
+			n, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor: %w", err)
+			}
+			if n > uint64(r.Len()) {
+				return nil, errors.New("invalid cursor: truncated string")
+			}
+			s := make([]byte, n)
+			_, _ = r.Read(s)
+			values = append(values, string(s))
+		case cursorTime:
+			sec, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor: %w", err)
+			}
+			nsec, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor: %w", err)
+			}
+			if nsec >= uint64(time.Second) {
+				return nil, errors.New("invalid cursor: invalid time")
+			}
+			values = append(values, time.Unix(sec, int64(nsec)).UTC())
+		default:
+			return nil, fmt.Errorf("invalid cursor: unknown type tag %q", tag)
+		}
+	}
+	return values, nil
+}
+
+// PaginateByCursor is PaginateByKeyset with the cursor values encoded with
+// EncodeCursor, e.g. read from a request parameter. An empty cursor selects the
+// first page. ToSql returns an error if the cursor can't be decoded.
+// Ex: Select("*").From("posts").OrderBy("created_at DESC", "id").PaginateByCursor(20, r.URL.Query().Get("cursor"))
+func (b SelectBuilder) PaginateByCursor(limit uint64, cursor string) SelectBuilder {
+	if len(cursor) == 0 {
+		return b.PaginateByKeyset(limit)
+	}
+
+	values, err := DecodeCursor(cursor)
+	b = b.Limit(limit)
+	return b.set(func(d *selectData) { d.Keyset = &keysetPage{cursor: values, err: err} })
+}
@@ -0,0 +1,66 @@
+package squirrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	ts := time.Date(2024, time.March, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+
+	cursor, err := EncodeCursor(ts, 42, "o'reilly", uint8(7), int64(-1))
+	assert.NoError(t, err)
+	assert.NotContains(t, cursor, "=")
+	assert.NotContains(t, cursor, "+")
+	assert.NotContains(t, cursor, "/")
+
+	values, err := DecodeCursor(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{ts.UTC(), int64(42), "o'reilly", int64(7), int64(-1)}, values)
+
+	cursor, err = EncodeCursor()
+	assert.NoError(t, err)
+	values, err = DecodeCursor(cursor)
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestCursorErr(t *testing.T) {
+	abc, err := EncodeCursor("abc")
+	assert.NoError(t, err)
+	for _, cursor := range []string{"", "not base64!", "AA", abc[:4], "AXg"} {
+		_, err := DecodeCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+
+	_, err = EncodeCursor(1.5)
+	assert.EqualError(t, err, "unsupported cursor value type float64")
+	_, err = EncodeCursor(uint64(1 << 63))
+	assert.EqualError(t, err, "cursor value 9223372036854775808 overflows int64")
+}
+
+func TestPaginateByCursor(t *testing.T) {
+	ts := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	q := Select("*").From("posts").OrderBy("created_at DESC", "id")
+
+	cursor, err := EncodeCursor(ts, 42)
+	assert.NoError(t, err)
+	sql, args, err := q.PaginateByCursor(20, cursor).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts WHERE (created_at < ? OR (created_at = ? AND id > ?)) ORDER BY created_at DESC, id LIMIT 20", sql)
+	assert.Equal(t, []any{ts, ts, int64(42)}, args)
+
+	sql, _, err = q.PaginateByCursor(20, "").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts ORDER BY created_at DESC, id LIMIT 20", sql)
+
+	_, _, err = q.PaginateByCursor(20, "tampered").ToSql()
+	assert.Error(t, err)
+
+	cursor, err = EncodeCursor(42)
+	assert.NoError(t, err)
+	_, _, err = q.PaginateByCursor(20, cursor).ToSql()
+	assert.Error(t, err)
+}
@@ -2,14 +2,17 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
-
-	"github.com/lann/builder"
+	"time"
 )
 
 type deleteData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	TimeLocation      *time.Location
+	SizeLimit         SizeLimit
 	Prefixes          []Sqlizer
 	From              string
 	WhereParts        []Sqlizer
@@ -17,6 +20,10 @@ type deleteData struct {
 	Limit             string
 	Offset            string
 	Suffixes          []Sqlizer
+	Returning         []string
+	MaxParts          int
+	ctx               context.Context // set by ToSqlContext
+	size              *sizeTracker    // set by ToSql
 }
 
 func (d *deleteData) ToSql() (sqlStr string, args []any, err error) {
@@ -24,27 +31,48 @@ func (d *deleteData) ToSql() (sqlStr string, args []any, err error) {
 		err = fmt.Errorf("delete statements must specify a From table")
 		return "", nil, err
 	}
+	if err = ctxErr(d.ctx); err != nil {
+		return "", nil, err
+	}
+	if err = checkParts("WHERE", len(d.WhereParts), d.MaxParts); err != nil {
+		return "", nil, err
+	}
+
+	d.size = newSizeTracker(d.SizeLimit)
 
 	sql := &bytes.Buffer{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("prefix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 
 		sql.WriteString(" ")
 	}
 
 	sql.WriteString("DELETE FROM ")
 	sql.WriteString(d.From)
+	if err = d.size.clause("FROM", sql.Len(), len(args)); err != nil {
+		return "", nil, err
+	}
 
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSqlContext(d.ctx, d.WhereParts, sql, " AND ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("WHERE", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if (len(d.OrderBys) > 0 || len(d.Limit) > 0 || len(d.Offset) > 0) && !d.Dialect.supportsUpdateDeleteLimit() {
+		return "", nil, d.Dialect.unsupportedError("ORDER BY, LIMIT and OFFSET in DELETE statements")
 	}
 
 	if len(d.OrderBys) > 0 {
@@ -64,23 +92,46 @@ func (d *deleteData) ToSql() (sqlStr string, args []any, err error) {
 
 	if len(d.Suffixes) > 0 {
 		_, _ = sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("suffix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(d.Returning) > 0 {
+		if !d.Dialect.supportsReturning() {
+			return "", nil, d.Dialect.unsupportedError("RETURNING")
+		}
+
+		_, _ = sql.WriteString(" RETURNING ")
+		_, _ = sql.WriteString(strings.Join(d.Returning, ", "))
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
+	args = bindArgs(normalizeTimes(args, d.TimeLocation))
+	if d.PlaceholderFormat != nil { // zero value builder
+		sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	}
+	if err == nil {
+		err = d.size.check(len(sqlStr), len(args))
+	}
 	return sqlStr, args, err
 }
 
 // Builder
 
 // DeleteBuilder builds SQL DELETE statements.
-type DeleteBuilder builder.Builder
+// The zero value is an empty statement using ? placeholders.
+type DeleteBuilder struct {
+	core[deleteData]
+}
 
-func init() {
-	builder.Register(DeleteBuilder{}, deleteData{})
+// set returns a copy of b with the data changed by f.
+func (b DeleteBuilder) set(f func(d *deleteData)) DeleteBuilder {
+	return DeleteBuilder{b.with(f)}
 }
 
 // Format methods
@@ -88,14 +139,51 @@ func init() {
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
 // query.
 func (b DeleteBuilder) PlaceholderFormat(f PlaceholderFormat) DeleteBuilder {
-	return builder.Set(b, "PlaceholderFormat", f).(DeleteBuilder)
+	return b.set(func(d *deleteData) { d.PlaceholderFormat = f })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when there are more than n
+// WHERE parts. 0 means no limit.
+func (b DeleteBuilder) MaxParts(n int) DeleteBuilder {
+	return b.set(func(d *deleteData) { d.MaxParts = n })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement is over l, e.g. SizeLimit{Args: 65535} for PostgreSQL.
+func (b DeleteBuilder) SizeLimit(l SizeLimit) DeleteBuilder {
+	return b.set(func(d *deleteData) { d.SizeLimit = l })
+}
+
+// TimeLocation converts the time.Time args of the query to loc, e.g. time.UTC,
+// when it is built. A nil loc leaves them as is.
+func (b DeleteBuilder) TimeLocation(loc *time.Location) DeleteBuilder {
+	return b.set(func(d *deleteData) { d.TimeLocation = loc })
+}
+
+// Dialect sets the target database engine (e.g. DialectSQLite) for the query.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b DeleteBuilder) Dialect(d Dialect) DeleteBuilder {
+	b = b.set(func(data *deleteData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
 }
 
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
 func (b DeleteBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(deleteData)
+	data := b.get()
+	return data.ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done, e.g. for deletes with thousands of generated conditions.
+func (b DeleteBuilder) ToSqlContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.ToSql()
 }
 
@@ -116,34 +204,43 @@ func (b DeleteBuilder) Prefix(sql string, args ...any) DeleteBuilder {
 
 // PrefixExpr adds an expression to the very beginning of the query
 func (b DeleteBuilder) PrefixExpr(e Sqlizer) DeleteBuilder {
-	return builder.Append(b, "Prefixes", e).(DeleteBuilder)
+	return b.set(func(d *deleteData) {
+		d.Prefixes = appendTo(d.Prefixes, orNilSqlizer(e, "prefix"))
+	})
 }
 
 // From sets the table to be deleted from.
 func (b DeleteBuilder) From(from string) DeleteBuilder {
-	return builder.Set(b, "From", from).(DeleteBuilder)
+	return b.set(func(d *deleteData) { d.From = from })
 }
 
 // Where adds WHERE expressions to the query.
 //
 // See SelectBuilder.Where for more information.
 func (b DeleteBuilder) Where(pred any, args ...any) DeleteBuilder {
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(DeleteBuilder)
+	if pred == nil { // skipping it would widen the statement
+		return b.set(func(d *deleteData) {
+			d.WhereParts = appendTo[Sqlizer](d.WhereParts, nilSqlizer{clause: "WHERE"})
+		})
+	}
+	return b.set(func(d *deleteData) {
+		d.WhereParts = appendTo(d.WhereParts, newWherePart(pred, args...))
+	})
 }
 
 // OrderBy adds ORDER BY expressions to the query.
 func (b DeleteBuilder) OrderBy(orderBys ...string) DeleteBuilder {
-	return builder.Extend(b, "OrderBys", orderBys).(DeleteBuilder)
+	return b.set(func(d *deleteData) { d.OrderBys = appendTo(d.OrderBys, orderBys...) })
 }
 
 // Limit sets a LIMIT clause on the query.
 func (b DeleteBuilder) Limit(limit uint64) DeleteBuilder {
-	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(DeleteBuilder)
+	return b.set(func(d *deleteData) { d.Limit = fmt.Sprintf("%d", limit) })
 }
 
 // Offset sets a OFFSET clause on the query.
 func (b DeleteBuilder) Offset(offset uint64) DeleteBuilder {
-	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(DeleteBuilder)
+	return b.set(func(d *deleteData) { d.Offset = fmt.Sprintf("%d", offset) })
 }
 
 // Suffix adds an expression to the end of the query
@@ -153,5 +250,13 @@ func (b DeleteBuilder) Suffix(sql string, args ...any) DeleteBuilder {
 
 // SuffixExpr adds an expression to the end of the query
 func (b DeleteBuilder) SuffixExpr(e Sqlizer) DeleteBuilder {
-	return builder.Append(b, "Suffixes", e).(DeleteBuilder)
+	return b.set(func(d *deleteData) {
+		d.Suffixes = appendTo(d.Suffixes, orNilSqlizer(e, "suffix"))
+	})
+}
+
+// Returning adds a RETURNING clause to the query.
+// It is rendered after all suffixes.
+func (b DeleteBuilder) Returning(columns ...string) DeleteBuilder {
+	return b.set(func(d *deleteData) { d.Returning = appendTo(d.Returning, columns...) })
 }
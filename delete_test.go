@@ -51,3 +51,41 @@ func TestDeleteBuilderPlaceholders(t *testing.T) {
 	sql, _, _ = b.PlaceholderFormat(Dollar).ToSql()
 	assert.Equal(t, "DELETE FROM test WHERE x = $1 AND y = $2", sql)
 }
+
+func TestDeleteBuilderReturning(t *testing.T) {
+	sql, args, err := Delete("a").Where("b = ?", 1).Returning("id").PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM a WHERE b = $1 RETURNING id", sql)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestDeleteBuilderSQLiteLimit(t *testing.T) {
+	b := Delete("a").Where("b = ?", 1).OrderBy("c").Limit(2)
+
+	_, _, err := b.Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	sql, _, err := b.Dialect(DialectSQLiteUpdateDeleteLimit).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM a WHERE b = ? ORDER BY c LIMIT 2", sql)
+
+	for _, d := range []Dialect{DialectPostgres, DialectDuckDB, DialectBigQuery, DialectSnowflake, DialectClickHouse} {
+		_, _, err = Delete("t").Limit(3).Dialect(d).ToSql()
+		assert.Error(t, err, d)
+	}
+	for _, d := range []Dialect{DialectDefault, DialectMySQL, DialectMariaDB} {
+		_, _, err = Delete("t").Limit(3).Dialect(d).ToSql()
+		assert.NoError(t, err, d)
+	}
+}
+
+func TestDeleteBuilderNilSqlizer(t *testing.T) {
+	var pred Sqlizer
+
+	_, _, err := Delete("t").Where(pred).ToSql()
+	assert.ErrorIs(t, err, ErrNilSqlizer)
+	assert.EqualError(t, err, "nil Sqlizer in WHERE")
+
+	_, _, err = StatementBuilder.RejectNilPredicates().Delete("t").PrefixExpr(pred).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in prefix")
+}
@@ -0,0 +1,85 @@
+package squirrel
+
+import (
+	"reflect"
+	"testing"
+)
+
+// builds is the number of times a Sqlizer is built to check its output, enough
+// for the randomized iteration order of maps to show.
+const builds = 50
+
+func checkDeterministic(t *testing.T, name string, s Sqlizer) {
+	t.Helper()
+	sql, args, err := s.ToSql()
+	for i := 1; i < builds; i++ {
+		sql2, args2, err2 := s.ToSql()
+		if sql2 != sql || !reflect.DeepEqual(args2, args) || !reflect.DeepEqual(err2, err) {
+			t.Fatalf("%s: build %d differs:\n%q %v %v\n%q %v %v", name, i, sql, args, err, sql2, args2, err2)
+		}
+	}
+}
+
+func TestDeterministicSql(t *testing.T) {
+	m := map[string]any{"a": 1, "b": "x", "c": []int{1, 2}, "d": nil, "e": 2.5, "f": true}
+	like := map[string]any{"a": "%x", "b": "y%", "c": "%z%", "d": "w"}
+	cmp := map[string]any{"a": 1, "b": 2, "c": 3, "d": 4}
+
+	sqlizers := map[string]Sqlizer{
+		"Eq":          Eq(m),
+		"NotEq":       NotEq(m),
+		"EqNotEmpty":  EqNotEmpty(m),
+		"EqFold":      EqFold(like),
+		"Like":        Like(like),
+		"NotLike":     NotLike(like),
+		"ILike":       ILike(like),
+		"NotILike":    NotILike(like),
+		"Lt":          Lt(cmp),
+		"LtOrEq":      LtOrEq(cmp),
+		"Gt":          Gt(cmp),
+		"GtOrEq":      GtOrEq(cmp),
+		"Cmp":         Cmp("LIKE", like),
+		"Not":         Not(Eq(m)),
+		"Update":      Update("t").SetMap(m).Where(Eq(cmp)),
+		"Insert":      Insert("t").SetMap(m),
+		"Select":      Select("*").From("t").Where(Eq(m)).Having(Gt(cmp)),
+		"MapCase":     MapCase("status", map[any]any{1: "a", 2: "b", 3: "c", "x": "d"}, nil),
+		"UpdateFrom":  UpdateFromValues("t", []string{"id"}, []map[string]any{{"id": 1, "a": 1, "b": 2, "c": 3}}),
+		"ClaimRows":   ClaimRows("jobs", Eq(cmp), 10, map[string]any{"a": 1, "b": 2, "c": 3}),
+		"Facets":      Facets(Select().From("t"), map[string]Sqlizer{"a": Eq{"a": 1}, "b": Eq{"b": 1}, "c": Eq{"c": 1}}),
+		"Dialect":     Select("*").From("t").Where(Eq(m)).Dialect(DialectMSSQL),
+		"Union":       Union(Select("*").From("t").Where(Eq(m)), Select("*").From("u").Where(Eq(m))),
+		"UnionSorted": Union(Select("*").From("t").Where(Like(like))).OrderBy("a"),
+	}
+	for name, s := range sqlizers {
+		checkDeterministic(t, name, s)
+	}
+}
+
+func TestDeterministicValidate(t *testing.T) {
+	registerTestSchema(t)
+
+	b := Select("id").From("users").Where(Eq{"x": 1, "y": 2, "z": 3})
+	err := b.Validate()
+	for i := 1; i < builds; i++ {
+		if err2 := b.Validate(); err2.Error() != err.Error() {
+			t.Fatalf("build %d differs: %v, %v", i, err, err2)
+		}
+	}
+}
+
+func FuzzDeterministicSql(f *testing.F) {
+	f.Add("a", "b", "c", "x", int64(1))
+	f.Add("users.id", "name", "", "O'Brien", int64(-1))
+	f.Add("a", "a", "a", "?", int64(0))
+	f.Fuzz(func(t *testing.T, k1, k2, k3, s string, n int64) {
+		m := map[string]any{k1: s, k2: n, k3: []int64{n, n + 1}}
+		str := map[string]any{k1: s, k2: s + "%", k3: "%" + s}
+
+		checkDeterministic(t, "Eq", Select("*").From("t").Where(Eq(m)).Where(NotEq(m)))
+		checkDeterministic(t, "Like", Select("*").From("t").Where(Like(str)).Where(NotILike(str)))
+		checkDeterministic(t, "Lt", Select("*").From("t").Where(Lt(str)).Where(GtOrEq(str)))
+		checkDeterministic(t, "Update", Update("t").SetMap(m).Where(Eq(m)))
+		checkDeterministic(t, "Insert", Insert("t").SetMap(str))
+	})
+}
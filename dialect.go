@@ -0,0 +1,196 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect is used to specify the target database engine of the query.
+//
+// DialectDefault (the zero value) keeps the generic behavior of the builders:
+// nothing is validated and the SQL is rendered as written. Other dialects make
+// builders return an error from ToSql for constructs the engine does not
+// support, instead of emitting SQL that is rejected at runtime.
+type Dialect int
+
+const (
+	DialectDefault  Dialect = iota
+	DialectPostgres         // PostgreSQL
+	DialectMySQL            // MySQL
+	DialectSQLite           // SQLite 3.35+
+	// DialectSQLiteUpdateDeleteLimit is SQLite compiled with
+	// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, which allows ORDER BY and LIMIT on
+	// UPDATE and DELETE statements.
+	DialectSQLiteUpdateDeleteLimit
+	DialectClickHouse // ClickHouse
+	DialectBigQuery   // BigQuery (GoogleSQL)
+	DialectSnowflake  // Snowflake
+	DialectDuckDB     // DuckDB
+	DialectOracle     // Oracle 12c+
+	// DialectOracleLegacy is Oracle before 12c, which has no OFFSET / FETCH clauses:
+	// pagination is emulated with ROWNUM.
+	DialectOracleLegacy
+	DialectMSSQL   // Microsoft SQL Server
+	DialectMariaDB // MariaDB, a MySQL dialect with system-versioned tables
+)
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case DialectDefault:
+		return "default"
+	case DialectPostgres:
+		return "PostgreSQL"
+	case DialectMySQL:
+		return "MySQL"
+	case DialectMariaDB:
+		return "MariaDB"
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		return "SQLite"
+	case DialectClickHouse:
+		return "ClickHouse"
+	case DialectBigQuery:
+		return "BigQuery"
+	case DialectSnowflake:
+		return "Snowflake"
+	case DialectDuckDB:
+		return "DuckDB"
+	case DialectOracle, DialectOracleLegacy:
+		return "Oracle"
+	case DialectMSSQL:
+		return "MSSQL"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// PlaceholderFormat returns the placeholder format used by the dialect by default.
+// DialectDefault returns nil, meaning the current format must be kept.
+func (d Dialect) PlaceholderFormat() PlaceholderFormat {
+	switch d { //nolint:exhaustive
+	case DialectPostgres:
+		return Dollar
+	case DialectMySQL, DialectMariaDB, DialectSQLite, DialectSQLiteUpdateDeleteLimit, DialectClickHouse, DialectSnowflake, DialectDuckDB:
+		return Question
+	case DialectBigQuery, DialectMSSQL:
+		return AtP
+	case DialectOracle, DialectOracleLegacy:
+		return Colon
+	}
+	return nil
+}
+
+// QuoteIdent quotes a possibly qualified identifier (e.g. "schema.table") for the dialect.
+// Every part is quoted separately, except for BigQuery, where the whole path is
+// quoted at once (`project.dataset.table`).
+func (d Dialect) QuoteIdent(name string) string {
+	quote := "\""
+	if d.isMySQL() || d == DialectClickHouse || d == DialectBigQuery {
+		quote = "`"
+	}
+
+	if d == DialectBigQuery {
+		return quote + strings.ReplaceAll(name, quote, "\\"+quote) + quote
+	}
+
+	openQuote, closeQuote := quote, quote
+	if d == DialectMSSQL {
+		openQuote, closeQuote = "[", "]"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = openQuote + strings.ReplaceAll(part, closeQuote, closeQuote+closeQuote) + closeQuote
+	}
+	return strings.Join(parts, ".")
+}
+
+func (d Dialect) isMySQL() bool {
+	return d == DialectMySQL || d == DialectMariaDB
+}
+
+func (d Dialect) isSQLite() bool {
+	return d == DialectSQLite || d == DialectSQLiteUpdateDeleteLimit
+}
+
+func (d Dialect) isOracle() bool {
+	return d == DialectOracle || d == DialectOracleLegacy
+}
+
+// supportsReturning reports whether INSERT/UPDATE/DELETE ... RETURNING can be used.
+func (d Dialect) supportsReturning() bool {
+	switch d { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB, DialectClickHouse, DialectBigQuery, DialectSnowflake, DialectOracle, DialectOracleLegacy, DialectMSSQL:
+		return false
+	}
+	return true
+}
+
+// supportsUpdateDeleteLimit reports whether ORDER BY, LIMIT and OFFSET can be used
+// in UPDATE and DELETE statements.
+func (d Dialect) supportsUpdateDeleteLimit() bool {
+	return d == DialectDefault || d.isMySQL() || d == DialectSQLiteUpdateDeleteLimit
+}
+
+// supportsLateral reports whether LATERAL subqueries can be used.
+func (d Dialect) supportsLateral() bool {
+	return !d.isSQLite() && d != DialectClickHouse && d != DialectBigQuery && d != DialectMSSQL
+}
+
+// supportsPartitionSelection reports whether explicit PARTITION (...) selection can
+// be used in table references.
+func (d Dialect) supportsPartitionSelection() bool {
+	return d == DialectDefault || d.isMySQL()
+}
+
+// supportsClickHouseModifiers reports whether ClickHouse-only clauses
+// (FINAL, SAMPLE, SETTINGS) can be used.
+func (d Dialect) supportsClickHouseModifiers() bool {
+	return d == DialectDefault || d == DialectClickHouse
+}
+
+// hasBooleanLiterals reports whether TRUE and FALSE literals can be used.
+func (d Dialect) hasBooleanLiterals() bool {
+	return d != DialectMSSQL && !d.isOracle()
+}
+
+// fetchPagination reports whether pagination is rendered with OFFSET / FETCH instead of LIMIT.
+func (d Dialect) fetchPagination() bool {
+	return d == DialectOracle || d == DialectMSSQL
+}
+
+// supportsQualify reports whether the QUALIFY clause can be used.
+func (d Dialect) supportsQualify() bool {
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectBigQuery, DialectSnowflake, DialectDuckDB, DialectClickHouse:
+		return true
+	}
+	return false
+}
+
+// supportsStarModifiers reports whether * EXCLUDE / REPLACE (or EXCEPT) can be used.
+func (d Dialect) supportsStarModifiers() bool {
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectDuckDB, DialectSnowflake, DialectBigQuery, DialectClickHouse:
+		return true
+	}
+	return false
+}
+
+// supportsRowValueComparison reports whether row values can be compared with < and >.
+func (d Dialect) supportsRowValueComparison() bool {
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectMySQL, DialectMariaDB, DialectSQLite, DialectSQLiteUpdateDeleteLimit, DialectDuckDB:
+		return true
+	}
+	return false
+}
+
+// parenthesizedUnions reports whether the parts of UNION can be wrapped in parentheses.
+func (d Dialect) parenthesizedUnions() bool {
+	return !d.isSQLite() && d != DialectClickHouse
+}
+
+// unsupportedError returns an error for a construct not supported by the dialect.
+func (d Dialect) unsupportedError(construct string) error {
+	return fmt.Errorf("%s is not supported by %s dialect", construct, d)
+}
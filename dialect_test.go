@@ -0,0 +1,68 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialectPlaceholderFormat(t *testing.T) {
+	sql, _, err := StatementBuilder.Dialect(DialectPostgres).Select("a").From("t").Where(Eq{"b": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE b = $1", sql)
+
+	sql, _, err = StatementBuilder.Dialect(DialectSQLite).Delete("t").Where(Eq{"b": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE b = ?", sql)
+
+	// explicit placeholder format overrides the one of the dialect
+	sql, _, err = StatementBuilder.Dialect(DialectPostgres).PlaceholderFormat(Question).Select("a").Where(Eq{"b": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a WHERE b = ?", sql)
+
+	// default dialect keeps the current format
+	sql, _, err = Select("a").PlaceholderFormat(Colon).Dialect(DialectDefault).Where(Eq{"b": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a WHERE b = :1", sql)
+}
+
+func TestDialectSQLiteLateral(t *testing.T) {
+	subQ := Select("c").From("d")
+
+	_, _, err := Select("a").FromSelectLateral(subQ, "s").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("a").From("t").CrossJoinLateralSelect(subQ, "s").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("a").From("t").CrossJoinLateralSelect(subQ, "s").Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+}
+
+func TestDialectString(t *testing.T) {
+	assert.Equal(t, "SQLite", DialectSQLiteUpdateDeleteLimit.String())
+	assert.Equal(t, "PostgreSQL", DialectPostgres.String())
+	assert.Equal(t, "MariaDB", DialectMariaDB.String())
+	assert.Equal(t, "Dialect(100)", Dialect(100).String())
+}
+
+func TestDialectQuoteIdent(t *testing.T) {
+	assert.Equal(t, `"public"."users"`, DialectPostgres.QuoteIdent("public.users"))
+	assert.Equal(t, `"a""b"`, DialectDefault.QuoteIdent(`a"b`))
+	assert.Equal(t, "`db`.`users`", DialectMySQL.QuoteIdent("db.users"))
+	assert.Equal(t, "`project.dataset.table`", DialectBigQuery.QuoteIdent("project.dataset.table"))
+}
+
+func TestDialectMSSQL(t *testing.T) {
+	assert.Equal(t, "[dbo].[users]", DialectMSSQL.QuoteIdent("dbo.users"))
+
+	sql, args, err := Select("id").From("users").Where(Eq{"a": 1}).OrderBy("id").Limit(10).Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE a = @p1 ORDER BY id OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+	assert.Equal(t, []any{1}, args)
+
+	// OFFSET requires an ORDER BY clause
+	sql, _, err = Select("id").From("users").Limit(10).Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+}
@@ -0,0 +1,56 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// doBlock helps to run anonymous code blocks
+type doBlock struct {
+	language string
+	body     string
+	args     []any
+}
+
+var doLanguageRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Do allows to run an anonymous code block with the PostgreSQL DO statement, e.g.
+// a small server-side script for admin tooling. DO doesn't take parameters, so
+// the ? placeholders of body are replaced with the args rendered as literals (see
+// InlineArgs, which also lists the arg types accepted) and the body is enclosed in
+// dollar quotes with a tag which doesn't occur in it. language may be empty for
+// the default, plpgsql.
+// Ex:
+//
+//	Do("plpgsql", "BEGIN UPDATE users SET active = false WHERE org_id = ?; END", 42)
+//	// DO LANGUAGE plpgsql $$BEGIN UPDATE users SET active = false WHERE org_id = 42; END$$
+func Do(language, body string, args ...any) doBlock {
+	return doBlock{language: language, body: body, args: args}
+}
+
+// ToSql builds the statement into a SQL string. There are never bound args.
+func (b doBlock) ToSql() (string, []any, error) {
+	if len(strings.TrimSpace(b.body)) == 0 {
+		return "", nil, fmt.Errorf("do block requires a body")
+	}
+	if len(b.language) > 0 && !doLanguageRegexp.MatchString(b.language) {
+		return "", nil, fmt.Errorf("invalid do block language %q", b.language)
+	}
+
+	body, err := InlineArgs(Expr(b.body, b.args...), DialectPostgres)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tag := "$$"
+	for i := 1; strings.Contains(body, tag); i++ {
+		tag = fmt.Sprintf("$do%d$", i)
+	}
+
+	sql := "DO "
+	if len(b.language) > 0 {
+		sql += "LANGUAGE " + b.language + " "
+	}
+	return sql + tag + body + tag, nil, nil
+}
@@ -0,0 +1,39 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	sql, args, err := Do("plpgsql", "BEGIN UPDATE users SET name = ? WHERE id = ?; END", "O'Brien", 1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DO LANGUAGE plpgsql $$BEGIN UPDATE users SET name = 'O''Brien' WHERE id = 1; END$$", sql)
+	assert.Empty(t, args)
+
+	sql, _, err = Do("", "BEGIN RAISE NOTICE ?; END", "costs $$").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DO $do1$BEGIN RAISE NOTICE 'costs $$'; END$do1$", sql)
+
+	sql, _, err = Do("", "BEGIN RAISE NOTICE '?'; END").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DO $$BEGIN RAISE NOTICE '?'; END$$", sql)
+}
+
+func TestDoErr(t *testing.T) {
+	_, _, err := Do("plpgsql", " ").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Do("plpgsql; DROP", "BEGIN END").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Do("", "BEGIN PERFORM ?; END", struct{}{}).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Do("", "BEGIN PERFORM ?, ?; END", 1).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Do("", "BEGIN PERFORM ?; END", 1, 2).ToSql()
+	assert.Error(t, err)
+}
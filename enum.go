@@ -0,0 +1,145 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// enums holds the map[string][]any of the registered values, replaced on every
+// registration so that building a query doesn't take a lock.
+var (
+	enumsMutex sync.Mutex // serializes RegisterEnum
+	enums      atomic.Value
+)
+
+// RegisterEnum registers the values allowed for column, e.g. "status" or
+// "orders.status". Eq and NotEq conditions on the column, and Enum values for
+// it, then return an error at build time for any other value, instead of a query
+// silently matching nothing. A qualified Eq key, e.g. "o.status", is checked
+// against the values of its column name when it isn't registered itself, so an
+// unqualified registration applies to the status column of every table: register
+// "orders.status" instead if other tables have a status column of their own.
+// Values are compared after their conversion to driver values, so int64(1) is
+// allowed by RegisterEnum("prio", 1, 2). Subqueries and other Sqlizer values
+// aren't checked.
+// Ex: RegisterEnum("status", "active", "closed")
+func RegisterEnum(column string, allowed ...any) {
+	enumsMutex.Lock()
+	defer enumsMutex.Unlock()
+
+	registered, _ := enums.Load().(map[string][]any)
+	values := make(map[string][]any, len(registered)+1)
+	for c, v := range registered {
+		values[c] = v
+	}
+	values[column] = append([]any(nil), allowed...)
+	enums.Store(values)
+}
+
+// enumValues returns the values registered for column.
+func enumValues(column string) ([]any, bool) {
+	registered, _ := enums.Load().(map[string][]any)
+	if len(registered) == 0 {
+		return nil, false
+	}
+	if allowed, ok := registered[column]; ok {
+		return allowed, true
+	}
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		allowed, ok := registered[column[i+1:]]
+		return allowed, ok
+	}
+	return nil, false
+}
+
+// checkEnum returns an error if v isn't one of allowed.
+func checkEnum(column string, v any, allowed []any) error {
+	value := enumDriverValue(v)
+	for _, a := range allowed {
+		if reflect.DeepEqual(value, enumDriverValue(a)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value %v for %s, allowed: %v", v, column, allowed)
+}
+
+// enumDriverValue returns v converted to a driver value, e.g. int64 for all the
+// integer types, or v itself if it can't be converted.
+func enumDriverValue(v any) any {
+	value, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return v
+	}
+	return value
+}
+
+// checkRegisteredEnum checks v, or every item of v if it is a list, against the
+// values registered for column, if any. NULL and Sqlizer values, e.g. subqueries,
+// are always allowed.
+func checkRegisteredEnum(column string, v any) error {
+	if _, ok := v.(Sqlizer); ok {
+		return nil
+	}
+	allowed, ok := enumValues(column)
+	if !ok {
+		return nil
+	}
+	if isListType(v) {
+		list := reflect.ValueOf(v)
+		for i := 0; i < list.Len(); i++ {
+			if err := checkRegisteredEnum(column, list.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if v == nil {
+		return nil
+	}
+	return checkEnum(column, v, allowed)
+}
+
+// enumArg helps to bind values checked against a set of allowed values
+type enumArg struct {
+	column  string
+	v       any
+	allowed []any
+}
+
+// Enum allows to bind v, checked against allowed, or against the values
+// registered for column with RegisterEnum when allowed is empty. An error is
+// returned at build time if v isn't allowed.
+// Ex: Update("orders").Set("status", Enum("status", s, "active", "closed"))
+func Enum(column string, v any, allowed ...any) enumArg {
+	return enumArg{column: column, v: v, allowed: allowed}
+}
+
+func (e enumArg) check() error {
+	allowed := e.allowed
+	if len(allowed) == 0 {
+		var ok bool
+		if allowed, ok = enumValues(e.column); !ok {
+			return fmt.Errorf("no allowed values registered for %s", e.column)
+		}
+	}
+	return checkEnum(e.column, e.v, allowed)
+}
+
+func (e enumArg) ToSql() (string, []any, error) {
+	if err := e.check(); err != nil {
+		return "", nil, err
+	}
+	return "?", []any{e.v}, nil
+}
+
+// Value makes Enum values usable in Eq and the other comparison maps.
+func (e enumArg) Value() (driver.Value, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e.v, nil
+}
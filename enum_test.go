@@ -0,0 +1,86 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func registerTestEnums(t *testing.T) {
+	RegisterEnum("status", "active", "closed")
+	RegisterEnum("orders.status", "pending", "paid")
+	t.Cleanup(func() {
+		enums.Store(map[string][]any(nil))
+	})
+}
+
+func TestRegisteredEnum(t *testing.T) {
+	registerTestEnums(t)
+
+	sql, args, err := Select("*").From("users u").Where(Eq{"u.status": []string{"active", "closed"}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u WHERE u.status IN (?,?)", sql)
+	assert.Equal(t, []any{"active", "closed"}, args)
+
+	_, _, err = Select("*").From("users").Where(Eq{"status": "actve"}).ToSql()
+	assert.EqualError(t, err, "invalid value actve for status, allowed: [active closed]")
+
+	_, _, err = Select("*").From("users").Where(NotEq{"status": []string{"active", "cloesd"}}).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("orders").Where(Eq{"orders.status": "active"}).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("users").Where(Eq{"status": nil}).ToSql()
+	assert.NoError(t, err)
+}
+
+func TestRegisteredEnumSubquery(t *testing.T) {
+	registerTestEnums(t)
+
+	sql, _, err := Select("*").From("users").
+		Where(Eq{"status": Select("s").From("x")}).
+		Where(NotEq{"status": Select("s").From("y")}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE status IN (SELECT s FROM x) AND status NOT IN (SELECT s FROM y)", sql)
+}
+
+func TestRegisteredEnumConversion(t *testing.T) {
+	registerTestEnums(t)
+	RegisterEnum("prio", 1, 2)
+
+	_, _, err := Select("*").From("tasks").Where(Eq{"prio": int64(1)}).ToSql()
+	assert.NoError(t, err)
+
+	_, _, err = Select("*").From("tasks").Where(Eq{"prio": []int16{2, 1}}).ToSql()
+	assert.NoError(t, err)
+
+	_, _, err = Select("*").From("tasks").Where(Eq{"prio": int32(3)}).ToSql()
+	assert.EqualError(t, err, "invalid value 3 for prio, allowed: [1 2]")
+
+	_, _, err = Select("*").From("tasks").Where(Eq{"prio": "1"}).ToSql()
+	assert.Error(t, err)
+}
+
+func TestEnum(t *testing.T) {
+	sql, args, err := Update("orders").Set("status", Enum("status", "paid", "pending", "paid")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE orders SET status = ?", sql)
+	assert.Equal(t, []any{"paid"}, args)
+
+	_, _, err = Insert("orders").Columns("status").Values(Enum("status", "piad", "pending", "paid")).ToSql()
+	assert.EqualError(t, err, "invalid value piad for status, allowed: [pending paid]")
+
+	_, _, err = Select("*").From("orders").Where(Eq{"status": Enum("status", "piad", "pending", "paid")}).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Expr("status = ?", Enum("status", "active")).ToSql()
+	assert.EqualError(t, err, "no allowed values registered for status")
+
+	registerTestEnums(t)
+
+	sql, args, err = Expr("status = ?", Enum("status", "active")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "status = ?", sql)
+	assert.Equal(t, []any{"active"}, args)
+}
@@ -0,0 +1,121 @@
+package squirrel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// estimateQuery helps to read the planner's row estimate of a query
+type estimateQuery struct {
+	query   Sqlizer
+	dialect Dialect
+}
+
+// EstimateCount allows to get an approximate number of rows of q from the query
+// planner, e.g. to show totals in paginated UIs without a COUNT(*) scan.
+// ToSql renders EXPLAIN (FORMAT JSON) for PostgreSQL and EXPLAIN FORMAT=JSON for
+// MySQL; the plan returned by the database is read by ParseEstimate.
+// Ex:
+//
+//	e := EstimateCount(Select("*").From("events").Where(Eq{"kind": "click"})).Dialect(DialectPostgres)
+//	var plan []byte
+//	err := db.QueryRow(e.MustSql()).Scan(&plan)
+//	rows, err := e.ParseEstimate(plan)
+func EstimateCount(q Sqlizer) estimateQuery {
+	return estimateQuery{query: q}
+}
+
+// Dialect sets the dialect used to render the EXPLAIN statement and to read the plan.
+func (e estimateQuery) Dialect(d Dialect) estimateQuery {
+	e.dialect = d
+	return e
+}
+
+// ToSql builds the EXPLAIN statement into a SQL string and bound args.
+func (e estimateQuery) ToSql() (string, []any, error) {
+	sql, args, err := e.query.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres:
+		return "EXPLAIN (FORMAT JSON) " + sql, args, nil
+	case DialectMySQL, DialectMariaDB:
+		return "EXPLAIN FORMAT=JSON " + sql, args, nil
+	}
+	return "", nil, e.dialect.unsupportedError("EstimateCount")
+}
+
+// MustSql builds the EXPLAIN statement into a SQL string and bound args.
+// It panics if there are any errors.
+func (e estimateQuery) MustSql() (string, []any) {
+	sql, args, err := e.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// ParseEstimate returns the estimated number of rows of the query from plan, the
+// JSON output of the EXPLAIN statement.
+func (e estimateQuery) ParseEstimate(plan []byte) (int64, error) {
+	if e.dialect.isMySQL() {
+		return parseMySQLEstimate(plan)
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows *float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &plans); err != nil {
+		return 0, fmt.Errorf("parsing plan: %w", err)
+	}
+	if len(plans) == 0 || plans[0].Plan.Rows == nil {
+		return 0, fmt.Errorf("no row estimate in the plan")
+	}
+	return int64(math.Round(*plans[0].Plan.Rows)), nil
+}
+
+// mysqlPlanTable is a table of a MySQL (rows_produced_per_join) or MariaDB
+// (rows, filtered) JSON plan.
+type mysqlPlanTable struct {
+	RowsProduced *float64 `json:"rows_produced_per_join"`
+	Rows         *float64 `json:"rows"`
+	Filtered     *float64 `json:"filtered"`
+}
+
+func parseMySQLEstimate(plan []byte) (int64, error) {
+	var p struct {
+		QueryBlock struct {
+			Table      *mysqlPlanTable `json:"table"`
+			NestedLoop []struct {
+				Table *mysqlPlanTable `json:"table"`
+			} `json:"nested_loop"`
+		} `json:"query_block"`
+	}
+	if err := json.Unmarshal(plan, &p); err != nil {
+		return 0, fmt.Errorf("parsing plan: %w", err)
+	}
+
+	// the rows produced by the last table of a join are the rows of the query
+	table := p.QueryBlock.Table
+	if n := len(p.QueryBlock.NestedLoop); n > 0 {
+		table = p.QueryBlock.NestedLoop[n-1].Table
+	}
+
+	switch {
+	case table == nil:
+	case table.RowsProduced != nil:
+		return int64(math.Round(*table.RowsProduced)), nil
+	case table.Rows != nil:
+		rows := *table.Rows
+		if table.Filtered != nil {
+			rows = rows * *table.Filtered / 100
+		}
+		return int64(math.Round(rows)), nil
+	}
+	return 0, fmt.Errorf("no row estimate in the plan")
+}
@@ -0,0 +1,59 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateCountToSql(t *testing.T) {
+	q := Select("*").From("events").Where(Eq{"kind": "click"})
+
+	sql, args, err := EstimateCount(q.PlaceholderFormat(Dollar)).Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPLAIN (FORMAT JSON) SELECT * FROM events WHERE kind = $1", sql)
+	assert.Equal(t, []any{"click"}, args)
+
+	sql, _, err = EstimateCount(q).Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXPLAIN FORMAT=JSON SELECT * FROM events WHERE kind = ?", sql)
+
+	_, _, err = EstimateCount(q).Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+}
+
+func TestEstimateCountParsePostgres(t *testing.T) {
+	plan := `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "events", "Plan Rows": 1523, "Plan Width": 40}}]`
+
+	rows, err := EstimateCount(Select("*").From("events")).Dialect(DialectPostgres).ParseEstimate([]byte(plan))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1523), rows)
+
+	_, err = EstimateCount(Select("*").From("events")).ParseEstimate([]byte(`[]`))
+	assert.Error(t, err)
+
+	_, err = EstimateCount(Select("*").From("events")).ParseEstimate([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestEstimateCountParseMySQL(t *testing.T) {
+	e := EstimateCount(Select("*").From("events")).Dialect(DialectMySQL)
+
+	rows, err := e.ParseEstimate([]byte(`{"query_block": {"select_id": 1,
+		"table": {"table_name": "events", "rows_examined_per_scan": 1000, "rows_produced_per_join": 100.5}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), rows)
+
+	rows, err = e.ParseEstimate([]byte(`{"query_block": {"nested_loop": [
+		{"table": {"table_name": "u", "rows_produced_per_join": 10}},
+		{"table": {"table_name": "e", "rows_produced_per_join": 250}}]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), rows)
+
+	rows, err = e.Dialect(DialectMariaDB).ParseEstimate([]byte(`{"query_block": {"table": {"rows": 1000, "filtered": 25}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(250), rows)
+
+	_, err = e.ParseEstimate([]byte(`{"query_block": {}}`))
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"reflect"
@@ -22,9 +23,15 @@ type expr struct {
 
 // Expr builds an expression from a SQL fragment and arguments.
 //
+// Slice args of "IN (?)" are expanded into one placeholder per item, the other
+// slice args (e.g. of ANY(?) or "tags @> ?") are bound as a single arg, as are
+// driver.Valuer args such as pq.StringArray. An empty slice turns "col IN (?)"
+// into a false condition and "col NOT IN (?)" into a true one, like Eq does.
+//
 // Ex:
 //
 //	Expr("FROM_UNIXTIME(?)", t)
+//	Expr("id IN (?)", []int{1, 2, 3}) // id IN (?,?,?)
 func Expr(sql string, args ...any) Sqlizer {
 	return expr{sql: sql, args: args}
 }
@@ -43,12 +50,31 @@ func (e expr) ToSql() (sql string, args []any, err error) {
 		return e.sql, e.args, nil
 	}
 
-	buf := &bytes.Buffer{}
-	ap := e.args
-	sp := e.sql
+	return interpolateArgs(e.sql, e.args, true)
+}
 
-	var isql string
+// hasListArg reports whether one of args must be expanded by interpolateArgs.
+func hasListArg(args []any) bool {
+	for _, arg := range args {
+		if isListType(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// interpolateArgs expands the slice args of sql into placeholders and, if
+// inlineSqlizers is true, replaces the placeholders of Sqlizer args with their SQL.
+func interpolateArgs(sql string, args []any, inlineSqlizers bool) (string, []any, error) {
+	var err error
 	var iargs []any
+	var isql string
+
+	buf := &bytes.Buffer{}
+	ap := args
+	sp := sql
+	args = nil
+	pos := 0
 
 	for err == nil && len(ap) > 0 && len(sp) > 0 {
 		i := strings.Index(sp, "?")
@@ -63,12 +89,28 @@ func (e expr) ToSql() (sql string, args []any, err error) {
 			continue
 		}
 
-		if as, ok := ap[0].(Sqlizer); ok {
+		if as, ok := ap[0].(Sqlizer); ok && inlineSqlizers {
 			// sqlizer argument; expand it and append the result
-			isql, iargs, err = as.ToSql()
+			isql, iargs, err = nestedToSql(as)
 			buf.WriteString(sp[:i])
 			buf.WriteString(isql)
 			args = append(args, iargs...)
+		} else if isListType(ap[0]) && isInListArg(sp[:i]) {
+			// list argument; one placeholder per item
+			buf.WriteString(sp[:i])
+			list := reflect.ValueOf(ap[0])
+			if list.Len() == 0 {
+				sp, err = emptyListCondition(buf, sp[i+1:], pos)
+			} else {
+				buf.WriteString(Placeholders(list.Len()))
+				for j := 0; j < list.Len(); j++ {
+					args = append(args, list.Index(j).Interface())
+				}
+				sp = sp[i+1:]
+			}
+			ap = ap[1:]
+			pos++
+			continue
 		} else {
 			// normal argument; append it and the placeholder
 			buf.WriteString(sp[:i+1])
@@ -78,6 +120,7 @@ func (e expr) ToSql() (sql string, args []any, err error) {
 		// step past the argument and placeholder
 		ap = ap[1:]
 		sp = sp[i+1:]
+		pos++
 	}
 
 	// append the remaining sql and arguments
@@ -85,6 +128,55 @@ func (e expr) ToSql() (sql string, args []any, err error) {
 	return buf.String(), append(args, ap...), err
 }
 
+func hasSuffixFold(s, suffix string) bool {
+	return len(s) >= len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix)
+}
+
+// isInListArg reports whether the placeholder following sql is the list of
+// "IN (?)", the only place where slice args are expanded.
+func isInListArg(sql string) bool {
+	s := strings.TrimRight(sql, " ")
+	if !strings.HasSuffix(s, "(") {
+		return false
+	}
+	s = strings.TrimRight(s[:len(s)-1], " ")
+	return hasSuffixFold(s, "IN") && (len(s) == 2 || !isIdentifierChar(s[len(s)-3]))
+}
+
+// emptyListCondition replaces the "col [NOT] IN (" written at the end of buf and
+// the ")" starting rest with the condition used by Eq for empty lists.
+// It returns the SQL following the condition.
+func emptyListCondition(buf *bytes.Buffer, rest string, pos int) (string, error) {
+	tail := strings.TrimLeft(rest, " ")
+	written := strings.TrimRight(buf.String(), " ")
+
+	if strings.HasPrefix(tail, ")") && strings.HasSuffix(written, "(") {
+		written = strings.TrimRight(written[:len(written)-1], " ")
+		cond := ""
+		switch {
+		case hasSuffixFold(written, " NOT IN"):
+			cond, written = sqlTrue, written[:len(written)-len(" NOT IN")]
+		case hasSuffixFold(written, " IN"):
+			cond, written = sqlFalse, written[:len(written)-len(" IN")]
+		}
+
+		if cond != "" {
+			column := strings.TrimRight(written, " ")
+			start := len(column)
+			for start > 0 && isIdentifierChar(column[start-1]) {
+				start--
+			}
+			if start < len(column) {
+				buf.Truncate(start)
+				buf.WriteString(cond)
+				return tail[1:], nil
+			}
+		}
+	}
+
+	return rest, fmt.Errorf("empty list arg %d can only be used in \"column IN (?)\" or \"column NOT IN (?)\"", pos)
+}
+
 type concatExpr []any
 
 func (ce concatExpr) ToSql() (sql string, args []any, err error) {
@@ -93,7 +185,7 @@ func (ce concatExpr) ToSql() (sql string, args []any, err error) {
 		case string:
 			sql += p
 		case Sqlizer:
-			pSql, pArgs, err := p.ToSql()
+			pSql, pArgs, err := nestedToSql(p)
 			if err != nil {
 				return "", nil, err
 			}
@@ -116,6 +208,51 @@ func ConcatExpr(parts ...any) concatExpr {
 	return concatExpr(parts)
 }
 
+// stringConcatExpr helps to concatenate strings in SQL query
+type stringConcatExpr struct {
+	parts   []any
+	dialect Dialect
+}
+
+// Concat allows to concatenate strings in SQL query: strings are SQL (e.g.
+// columns), Sqlizers are nested and other values are bound to placeholders.
+// It is rendered with the || operator, or with the CONCAT function on MySQL, SQL
+// Server, ClickHouse and BigQuery.
+// Ex: Concat("first_name", Expr("?", " "), "last_name").Dialect(DialectMySQL)
+// -> "CONCAT(first_name, ?, last_name)"
+func Concat(parts ...any) stringConcatExpr {
+	return stringConcatExpr{parts: parts}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e stringConcatExpr) Dialect(d Dialect) stringConcatExpr {
+	e.dialect = d
+	return e
+}
+
+func (e stringConcatExpr) ToSql() (sql string, args []any, err error) {
+	if len(e.parts) == 0 {
+		return "", nil, fmt.Errorf("concat requires at least one part")
+	}
+
+	parts := make([]string, 0, len(e.parts))
+	for _, part := range e.parts {
+		partSql, partArgs, err := columnOperandToSql(part)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, partSql)
+		args = append(args, partArgs...)
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB, DialectMSSQL, DialectClickHouse, DialectBigQuery:
+		// || is a logical OR on MySQL and is not supported by the others
+		return fmt.Sprintf("CONCAT(%s)", strings.Join(parts, ", ")), args, nil
+	}
+	return strings.Join(parts, " || "), args, nil
+}
+
 // aliasExpr helps to alias part of SQL query generated with underlying "expr"
 type aliasExpr struct {
 	expr  Sqlizer
@@ -132,7 +269,15 @@ func Alias(e Sqlizer, a string) aliasExpr {
 }
 
 func (e aliasExpr) ToSql() (sql string, args []any, err error) {
-	sql, args, err = e.expr.ToSql()
+	return e.toSqlRawContext(context.Background())
+}
+
+func (e aliasExpr) toSqlRawContext(ctx context.Context) (sql string, args []any, err error) {
+	if c, ok := e.expr.(sqlizerContext); ok && ctx != nil {
+		sql, args, err = c.ToSqlContext(ctx)
+	} else {
+		sql, args, err = e.expr.ToSql()
+	}
 	if err == nil {
 		sql = fmt.Sprintf("(%s) AS %s", sql, e.alias)
 	}
@@ -167,22 +312,12 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
 	sortedKeys := getSortedKeys(eq)
 	for _, key := range sortedKeys {
 		var expr1 string
-		val := eq[key]
-
-		switch v := val.(type) {
-		case driver.Valuer:
-			if val, err = v.Value(); err != nil {
-				return "", nil, err
-			}
+		val, err := eqValue(eq[key])
+		if err != nil {
+			return "", nil, err
 		}
-
-		r := reflect.ValueOf(val)
-		if r.Kind() == reflect.Ptr {
-			if r.IsNil() {
-				val = nil
-			} else {
-				val = r.Elem().Interface()
-			}
+		if err := checkRegisteredEnum(key, val); err != nil {
+			return "", nil, err
 		}
 
 		if val == nil {
@@ -223,6 +358,26 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
 	return sql, args, nil
 }
 
+// eqValue returns the value of a driver.Valuer, or the value pointed to, nil for
+// nil pointers.
+func eqValue(val any) (any, error) {
+	if v, ok := val.(driver.Valuer); ok {
+		var err error
+		if val, err = v.Value(); err != nil {
+			return nil, err
+		}
+	}
+
+	r := reflect.ValueOf(val)
+	if r.Kind() == reflect.Ptr {
+		if r.IsNil() {
+			return nil, nil
+		}
+		return r.Elem().Interface(), nil
+	}
+	return val, nil
+}
+
 func (eq Eq) ToSql() (sql string, args []any, err error) {
 	return eq.toSQL(false)
 }
@@ -237,6 +392,120 @@ func (neq NotEq) ToSql() (sql string, args []any, err error) {
 	return Eq(neq).toSQL(true)
 }
 
+// NullSafe returns the NotEq conditions with NULLs treated as values, like IS
+// DISTINCT FROM: rows where the column is NULL match unless NULL is one of the
+// values, and NULLs in value lists don't make NOT IN always false.
+// NULLs returned by a subquery still do: they must be filtered out in the subquery.
+// Ex:
+//
+//	.Where(NotEq{"status": []any{"banned", nil}}.NullSafe()) // status NOT IN (?)
+//	.Where(NotEq{"status": []string{"banned"}}.NullSafe())   // (status NOT IN (?) OR status IS NULL)
+func (neq NotEq) NullSafe() Sqlizer {
+	return notEqNullSafe(neq)
+}
+
+// notEqNullSafe is NotEq treating NULLs as values
+type notEqNullSafe NotEq
+
+func (neq notEqNullSafe) ToSql() (sql string, args []any, err error) {
+	if len(neq) == 0 {
+		return sqlTrue, args, nil
+	}
+
+	exprs := make([]string, 0, len(neq))
+	for _, key := range getSortedKeys(neq) {
+		val, err := eqValue(neq[key])
+		if err != nil {
+			return "", nil, err
+		}
+		if err := checkRegisteredEnum(key, val); err != nil {
+			return "", nil, err
+		}
+
+		hasNull := val == nil
+		if isListType(val) {
+			list := reflect.ValueOf(val)
+			values := make([]any, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				item := list.Index(i).Interface()
+				if item == nil || (reflect.ValueOf(item).Kind() == reflect.Ptr && reflect.ValueOf(item).IsNil()) {
+					hasNull = true
+					continue
+				}
+				values = append(values, item)
+			}
+			if len(values) == 0 {
+				if hasNull {
+					exprs = append(exprs, fmt.Sprintf("%s IS NOT NULL", key))
+				}
+				continue
+			}
+			val = values
+		}
+
+		exprSql, exprArgs, err := NotEq{key: val}.ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+		if !hasNull {
+			exprSql = fmt.Sprintf("(%s OR %s IS NULL)", exprSql, key)
+		}
+		exprs = append(exprs, exprSql)
+		args = append(args, exprArgs...)
+	}
+
+	if len(exprs) == 0 {
+		return sqlTrue, args, nil
+	}
+	return strings.Join(exprs, " AND "), args, nil
+}
+
+// EqFold is syntactic sugar for case-insensitive equality conditions, e.g. for
+// unique lookups of emails or user names. LOWER is applied to both sides, so it
+// works with every dialect; an index on LOWER(column) makes the lookup fast.
+// Slices are rendered as IN lists and nil as IS NULL, like with Eq.
+// Ex:
+//
+//	.Where(EqFold{"email": email}) == "LOWER(email) = LOWER(?)"
+type EqFold map[string]any
+
+func (eq EqFold) ToSql() (sql string, args []any, err error) {
+	if len(eq) == 0 {
+		return sqlTrue, args, nil
+	}
+
+	exprs := make([]string, 0, len(eq))
+	for _, key := range getSortedKeys(eq) {
+		val := eq[key]
+		if v, ok := val.(driver.Valuer); ok {
+			if val, err = v.Value(); err != nil {
+				return "", nil, err
+			}
+		}
+
+		switch {
+		case val == nil:
+			exprs = append(exprs, fmt.Sprintf("%s IS NULL", key))
+		case isListType(val):
+			valVal := reflect.ValueOf(val)
+			if valVal.Len() == 0 {
+				exprs = append(exprs, sqlFalse)
+				continue
+			}
+			placeholders := make([]string, valVal.Len())
+			for i := range placeholders {
+				placeholders[i] = "LOWER(?)"
+				args = append(args, valVal.Index(i).Interface())
+			}
+			exprs = append(exprs, fmt.Sprintf("LOWER(%s) IN (%s)", key, strings.Join(placeholders, ",")))
+		default:
+			exprs = append(exprs, fmt.Sprintf("LOWER(%s) = LOWER(?)", key))
+			args = append(args, val)
+		}
+	}
+	return strings.Join(exprs, " AND "), args, nil
+}
+
 // Like is syntactic sugar for use with LIKE conditions.
 // Ex:
 //
@@ -245,8 +514,9 @@ type Like map[string]any
 
 func (lk Like) toSql(opr string) (sql string, args []any, err error) {
 	exprs := make([]string, 0, len(lk))
-	for key, val := range lk {
+	for _, key := range getSortedKeys(lk) {
 		var expr1 string
+		val := lk[key]
 
 		switch v := val.(type) {
 		case driver.Valuer:
@@ -390,14 +660,91 @@ func (gtOrEq GtOrEq) ToSql() (sql string, args []any, err error) {
 	return Lt(gtOrEq).toSql(true, true)
 }
 
+// Op is syntactic sugar for a condition with an operator chosen at runtime, e.g.
+// by a filter UI. The operator must be a comparison (=, <>, !=, <, <=, >, >=),
+// [NOT] IN or [NOT] [I]LIKE, and the column a plain identifier as accepted by
+// SafeIdent (registered identifiers aside): ToSql returns an error otherwise.
+// The Value is handled as by Eq, Lt, Like etc.
+// Ex:
+//
+//	.Where(Op{Col: "score", Operator: ">=", Value: 10}) == "score >= ?"
+type Op struct {
+	Col      string
+	Operator string
+	Value    any
+}
+
+func (op Op) ToSql() (sql string, args []any, err error) {
+	return Cmp(op.Operator, map[string]any{op.Col: op.Value}).ToSql()
+}
+
+// cmpExpr helps to use an operator chosen at runtime in SQL query
+type cmpExpr struct {
+	operator string
+	values   map[string]any
+}
+
+// Cmp allows to compare the columns of values with their values using operator,
+// see Op for the supported operators.
+// Ex: Cmp(">=", map[string]any{"score": 10, "level": 2}) -> "level >= ? AND score >= ?"
+func Cmp(operator string, values map[string]any) Sqlizer {
+	return cmpExpr{operator: operator, values: values}
+}
+
+func (e cmpExpr) ToSql() (string, []any, error) {
+	for _, column := range getSortedKeys(e.values) {
+		if !safeIdentRegexp.MatchString(column) {
+			return "", nil, fmt.Errorf("invalid column %q", column)
+		}
+	}
+
+	switch strings.ToUpper(strings.Join(strings.Fields(e.operator), " ")) {
+	case "=", "IN":
+		return Eq(e.values).ToSql()
+	case "<>", "!=", "NOT IN":
+		return NotEq(e.values).ToSql()
+	case "<":
+		return Lt(e.values).ToSql()
+	case "<=":
+		return LtOrEq(e.values).ToSql()
+	case ">":
+		return Gt(e.values).ToSql()
+	case ">=":
+		return GtOrEq(e.values).ToSql()
+	case "LIKE":
+		return Like(e.values).ToSql()
+	case "NOT LIKE":
+		return NotLike(e.values).ToSql()
+	case "ILIKE":
+		return ILike(e.values).ToSql()
+	case "NOT ILIKE":
+		return NotILike(e.values).ToSql()
+	}
+	return "", nil, fmt.Errorf("unsupported operator %q", e.operator)
+}
+
 type conj []Sqlizer
 
-func (c conj) join(sep, defaultExpr string) (sql string, args []any, err error) {
+func (c conj) join(sep, defaultExpr string, strict bool) (sql string, args []any, err error) {
 	if len(c) == 0 {
 		return defaultExpr, []any{}, nil
 	}
 	var sqlParts []string
 	for _, sqlizer := range c {
+		if sqlizer == nil {
+			continue
+		}
+		if strict {
+			switch s := sqlizer.(type) {
+			case And:
+				sqlizer = strictConj{conj: conj(s), sep: " AND ", defaultExpr: sqlTrue}
+			case Or:
+				sqlizer = strictConj{conj: conj(s), sep: " OR ", defaultExpr: sqlFalse}
+			case strictConj:
+			default:
+				sqlizer = Paren(sqlizer)
+			}
+		}
 		partSQL, partArgs, err := nestedToSql(sqlizer)
 		if err != nil {
 			return "", nil, err
@@ -417,14 +764,80 @@ func (c conj) join(sep, defaultExpr string) (sql string, args []any, err error)
 type And conj
 
 func (a And) ToSql() (string, []any, error) {
-	return conj(a).join(" AND ", sqlTrue)
+	return conj(a).join(" AND ", sqlTrue, false)
+}
+
+// Strict parenthesizes every condition of the conjunction, and of the nested And
+// and Or, so that raw SQL conditions like Expr("a = ? OR b = ?") can't change
+// the precedence of the operators.
+// Ex: And{Expr("a OR b"), Eq{"c": 1}}.Strict() -> "((a OR b) AND (c = ?))"
+func (a And) Strict() Sqlizer {
+	return strictConj{conj: conj(a), sep: " AND ", defaultExpr: sqlTrue}
 }
 
 // Or conjunction Sqlizers
 type Or conj
 
 func (o Or) ToSql() (string, []any, error) {
-	return conj(o).join(" OR ", sqlFalse)
+	return conj(o).join(" OR ", sqlFalse, false)
+}
+
+// Strict parenthesizes every condition of the disjunction, see And.Strict.
+func (o Or) Strict() Sqlizer {
+	return strictConj{conj: conj(o), sep: " OR ", defaultExpr: sqlFalse}
+}
+
+// strictConj is an And or Or parenthesizing its conditions
+type strictConj struct {
+	conj        conj
+	sep         string
+	defaultExpr string
+}
+
+func (c strictConj) ToSql() (string, []any, error) {
+	return c.conj.join(c.sep, c.defaultExpr, true)
+}
+
+// parenExpr helps to parenthesize a Sqlizer
+type parenExpr struct {
+	expr Sqlizer
+}
+
+// Paren wraps the SQL of s in parentheses, unless it is empty or already enclosed
+// in a pair of parentheses.
+// Ex: Paren(Expr("a = ? OR b = ?", 1, 2)) -> "(a = ? OR b = ?)"
+func Paren(s Sqlizer) Sqlizer {
+	return parenExpr{expr: s}
+}
+
+func (e parenExpr) ToSql() (string, []any, error) {
+	sql, args, err := nestedToSql(e.expr)
+	if err != nil || sql == "" || isParenthesized(sql) {
+		return sql, args, err
+	}
+	return "(" + sql + ")", args, nil
+}
+
+// isParenthesized reports whether sql is enclosed in a pair of parentheses,
+// i.e. "(a) OR (b)" is not.
+func isParenthesized(sql string) bool {
+	if !strings.HasPrefix(sql, "(") || !strings.HasSuffix(sql, ")") {
+		return false
+	}
+	depth, enclosed := 0, true
+	scanSql(sql, func(i int) int {
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(sql)-1 {
+				enclosed = false
+			}
+		}
+		return 0
+	})
+	return enclosed && depth == 0
 }
 
 func getSortedKeys(exp map[string]any) []string {
@@ -440,6 +853,12 @@ func isListType(val any) bool {
 	if driver.IsValue(val) {
 		return false
 	}
+	if _, ok := val.(driver.Valuer); ok {
+		return false
+	}
+	if _, ok := argBinderOf(val); ok {
+		return false
+	}
 	valVal := reflect.ValueOf(val)
 	return valVal.Kind() == reflect.Array || valVal.Kind() == reflect.Slice
 }
@@ -539,6 +958,119 @@ func (e avgExpr) ToSql() (sql string, args []any, err error) {
 	return
 }
 
+// groupConcatExpr helps to use string aggregation in SQL query
+type groupConcatExpr struct {
+	expr      Sqlizer
+	orderBys  []string
+	separator *string
+	distinct  bool
+	dialect   Dialect
+}
+
+// GroupConcat allows to use string aggregation in SQL query. It is rendered as
+// STRING_AGG, GROUP_CONCAT or LISTAGG depending on the dialect.
+// Ex: GroupConcat(Expr("name")).OrderBy("name").Separator(", ").Dialect(DialectMySQL)
+// -> "GROUP_CONCAT(name ORDER BY name SEPARATOR ', ')"
+func GroupConcat(e Sqlizer) groupConcatExpr {
+	return groupConcatExpr{expr: e}
+}
+
+// OrderBy sets the order of the aggregated values.
+func (e groupConcatExpr) OrderBy(orderBys ...string) groupConcatExpr {
+	e.orderBys = append(e.orderBys[:len(e.orderBys):len(e.orderBys)], orderBys...)
+	return e
+}
+
+// Separator sets the string put between the aggregated values (default ",").
+func (e groupConcatExpr) Separator(separator string) groupConcatExpr {
+	e.separator = &separator
+	return e
+}
+
+// Distinct aggregates distinct values only.
+func (e groupConcatExpr) Distinct() groupConcatExpr {
+	e.distinct = true
+	return e
+}
+
+// Dialect sets the dialect used to render the aggregation.
+func (e groupConcatExpr) Dialect(d Dialect) groupConcatExpr {
+	e.dialect = d
+	return e
+}
+
+func (e groupConcatExpr) ToSql() (sql string, args []any, err error) {
+	sql, args, err = nestedToSql(e.expr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	d := e.dialect
+	if d == DialectClickHouse {
+		return "", nil, d.unsupportedError("GroupConcat")
+	}
+	if e.distinct && d == DialectMSSQL {
+		return "", nil, d.unsupportedError("STRING_AGG(DISTINCT ...)")
+	}
+	if e.distinct && e.separator != nil && d.isSQLite() {
+		return "", nil, d.unsupportedError("GROUP_CONCAT(DISTINCT ...) with a separator")
+	}
+
+	if e.distinct {
+		sql = "DISTINCT " + sql
+	}
+
+	separator := ","
+	if e.separator != nil {
+		separator = *e.separator
+	}
+	separatorSql := d.stringLiteral(separator)
+
+	orderBy := ""
+	if len(e.orderBys) > 0 {
+		orderBy = "ORDER BY " + strings.Join(e.orderBys, ", ")
+	}
+
+	buf := &bytes.Buffer{}
+	switch d { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB:
+		_, _ = fmt.Fprintf(buf, "GROUP_CONCAT(%s", sql)
+		if len(orderBy) > 0 {
+			_, _ = fmt.Fprintf(buf, " %s", orderBy)
+		}
+		if e.separator != nil {
+			_, _ = fmt.Fprintf(buf, " SEPARATOR %s", separatorSql)
+		}
+		_, _ = buf.WriteString(")")
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		_, _ = fmt.Fprintf(buf, "GROUP_CONCAT(%s", sql)
+		if e.separator != nil {
+			_, _ = fmt.Fprintf(buf, ", %s", separatorSql)
+		}
+		if len(orderBy) > 0 {
+			_, _ = fmt.Fprintf(buf, " %s", orderBy)
+		}
+		_, _ = buf.WriteString(")")
+	case DialectOracle, DialectOracleLegacy, DialectSnowflake, DialectMSSQL:
+		function := "LISTAGG"
+		if d == DialectMSSQL {
+			function = "STRING_AGG"
+		}
+		_, _ = fmt.Fprintf(buf, "%s(%s, %s)", function, sql, separatorSql)
+		if len(orderBy) > 0 {
+			_, _ = fmt.Fprintf(buf, " WITHIN GROUP (%s)", orderBy)
+		}
+	default:
+		_, _ = fmt.Fprintf(buf, "STRING_AGG(%s, %s", sql, separatorSql)
+		if len(orderBy) > 0 {
+			_, _ = fmt.Fprintf(buf, " %s", orderBy)
+		}
+		_, _ = buf.WriteString(")")
+	}
+
+	return buf.String(), args, nil
+}
+
 // existsExpr helps to use EXISTS in SQL query
 type existsExpr struct {
 	expr Sqlizer
@@ -577,6 +1109,72 @@ func (e notExistsExpr) ToSql() (sql string, args []any, err error) {
 	return
 }
 
+// isExpr helps to use IS TRUE, IS FALSE, IS NULL and IS NOT NULL in SQL query
+type isExpr struct {
+	expr    any
+	value   string
+	dialect Dialect
+}
+
+// IsTrue allows to test whether a boolean column (a string) or expression (a
+// Sqlizer) is true, NULL excluded. It is rendered as "= 1" for dialects without
+// boolean literals (Oracle, MSSQL) and as "= TRUE" for ClickHouse and Snowflake.
+// Ex: SelectBuilder.Where(IsTrue("active")) -> "active IS TRUE"
+func IsTrue(e any) isExpr {
+	return isExpr{expr: e, value: "TRUE"}
+}
+
+// IsFalse allows to test whether a boolean column or expression is false, NULL
+// excluded, see IsTrue.
+// Ex: SelectBuilder.Where(IsFalse(Expr("deleted_at < ?", t))) -> "(deleted_at < ?) IS FALSE"
+func IsFalse(e any) isExpr {
+	return isExpr{expr: e, value: "FALSE"}
+}
+
+// IsNullExpr allows to test whether an expression is NULL.
+// Ex: SelectBuilder.Where(IsNullExpr(Coalesce(0, Expr("a"), Expr("b")))) -> "(COALESCE((a), (b), ?)) IS NULL"
+func IsNullExpr(e Sqlizer) isExpr {
+	return isExpr{expr: e, value: "NULL"}
+}
+
+// IsNotNullExpr allows to test whether an expression is not NULL.
+// Ex: SelectBuilder.Where(IsNotNullExpr(Expr("data->>'email'"))) -> "(data->>'email') IS NOT NULL"
+func IsNotNullExpr(e Sqlizer) isExpr {
+	return isExpr{expr: e, value: "NOT NULL"}
+}
+
+// Dialect sets the dialect used to render IsTrue and IsFalse.
+func (e isExpr) Dialect(d Dialect) isExpr {
+	e.dialect = d
+	return e
+}
+
+func (e isExpr) ToSql() (sql string, args []any, err error) {
+	if e.expr == nil {
+		return "", nil, fmt.Errorf("IS %s requires an expression", e.value)
+	}
+	sql, args, err = columnOperandToSql(e.expr)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := e.expr.(Sqlizer); ok {
+		sql = "(" + sql + ")"
+	}
+
+	if e.value == "TRUE" || e.value == "FALSE" {
+		switch {
+		case !e.dialect.hasBooleanLiterals():
+			if e.value == "TRUE" {
+				return sql + " = 1", args, nil
+			}
+			return sql + " = 0", args, nil
+		case e.dialect == DialectClickHouse || e.dialect == DialectSnowflake:
+			return sql + " = " + e.value, args, nil
+		}
+	}
+	return sql + " IS " + e.value, args, nil
+}
+
 // equalExpr helps to use = in SQL query
 type equalExpr struct {
 	expr  Sqlizer
@@ -703,7 +1301,7 @@ func In(column string, e any) inExpr {
 func (e inExpr) ToSql() (sql string, args []any, err error) {
 	switch v := e.expr.(type) {
 	case Sqlizer:
-		sql, args, err = v.ToSql()
+		sql, args, err = nestedToSql(v)
 		if err == nil && sql != "" {
 			sql = fmt.Sprintf("%s IN (%s)", e.column, sql)
 		}
@@ -741,7 +1339,7 @@ func NotIn(column string, e any) notInExpr {
 func (e notInExpr) ToSql() (sql string, args []any, err error) {
 	switch v := e.expr.(type) {
 	case Sqlizer:
-		sql, args, err = v.ToSql()
+		sql, args, err = nestedToSql(v)
 		if err == nil && sql != "" {
 			sql = fmt.Sprintf("%s NOT IN (%s)", e.column, sql)
 		}
@@ -865,7 +1463,11 @@ func Cte(e Sqlizer, cte string) cteExpr {
 
 // ToSql builds the query into a SQL string and bound args.
 func (e cteExpr) ToSql() (sql string, args []any, err error) {
-	sql, args, err = e.expr.ToSql()
+	return e.toSqlRawContext(context.Background())
+}
+
+func (e cteExpr) toSqlRawContext(ctx context.Context) (sql string, args []any, err error) {
+	sql, args, err = nestedToSqlContext(ctx, e.expr)
 	if err == nil {
 		sql = fmt.Sprintf("%s AS (%s)", e.cte, sql)
 	}
@@ -878,7 +1480,7 @@ type notExpr struct {
 
 // ToSql builds the query into a SQL string and bound args.
 func (e notExpr) ToSql() (sql string, args []any, err error) {
-	sql, args, err = e.expr.ToSql()
+	sql, args, err = nestedToSql(e.expr)
 	if err == nil {
 		sql = fmt.Sprintf("NOT (%s)", sql)
 	}
@@ -886,10 +1488,53 @@ func (e notExpr) ToSql() (sql string, args []any, err error) {
 }
 
 // Not is a helper function to negate a condition.
+// Single column Eq, NotEq, Lt, LtOrEq, Gt, GtOrEq and [Not][I]Like conditions are
+// flipped to the opposite operator, other conditions are rendered as NOT (<cond>).
+// Ex: Not(Eq{"id": 1}) -> "id <> ?", Not(Or{...}) -> "NOT (... OR ...)"
 func Not(e Sqlizer) Sqlizer {
-	// check nested NOT
-	if n, ok := e.(notExpr); ok {
-		return n.expr
+	switch e := e.(type) {
+	case notExpr: // check nested NOT
+		return e.expr
+	case Eq:
+		if len(e) == 1 {
+			return NotEq(e)
+		}
+	case NotEq:
+		if len(e) == 1 {
+			return Eq(e)
+		}
+	case Lt:
+		if len(e) == 1 {
+			return GtOrEq(e)
+		}
+	case GtOrEq:
+		if len(e) == 1 {
+			return Lt(e)
+		}
+	case Gt:
+		if len(e) == 1 {
+			return LtOrEq(e)
+		}
+	case LtOrEq:
+		if len(e) == 1 {
+			return Gt(e)
+		}
+	case Like:
+		if len(e) == 1 {
+			return NotLike(e)
+		}
+	case NotLike:
+		if len(e) == 1 {
+			return Like(e)
+		}
+	case ILike:
+		if len(e) == 1 {
+			return NotILike(e)
+		}
+	case NotILike:
+		if len(e) == 1 {
+			return ILike(e)
+		}
 	}
 
 	return notExpr{e}
@@ -2,7 +2,9 @@ package squirrel
 
 import (
 	dbsql "database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -337,6 +339,22 @@ func TestEmptyOrToSql(t *testing.T) {
 	assert.Equal(t, expectedArgs, args)
 }
 
+func TestEqFoldToSql(t *testing.T) {
+	b := EqFold{"email": "Bob@Example.com", "login": []string{"Bob", "bobby"}, "deleted_at": nil}
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "deleted_at IS NULL AND LOWER(email) = LOWER(?) AND LOWER(login) IN (LOWER(?),LOWER(?))"
+	assert.Equal(t, expectedSql, sql)
+
+	expectedArgs := []any{"Bob@Example.com", "Bob", "bobby"}
+	assert.Equal(t, expectedArgs, args)
+
+	sql, _, err = EqFold{"login": []string{}}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=0)", sql)
+}
+
 func TestLikeToSql(t *testing.T) {
 	b := Like{"name": "%irrel"}
 	sql, args, err := b.ToSql()
@@ -722,11 +740,41 @@ func TestNotExprToSql(t *testing.T) {
 	sql, args, err := n.ToSql()
 	assert.NoError(t, err)
 
-	expectedSql := "NOT (id = ?)"
+	expectedSql := "id <> ?"
 	assert.Equal(t, expectedSql, sql)
 
 	expectedArgs := []any{1}
 	assert.Equal(t, expectedArgs, args)
+
+	sql, args, err = Not(Or{Expr("a = ?", 1), Eq{"b": 2}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT ((a = ? OR b = ?))", sql)
+	assert.Equal(t, []any{1, 2}, args)
+}
+
+func TestNotExprFlip(t *testing.T) {
+	tests := []struct {
+		pred Sqlizer
+		sql  string
+	}{
+		{Eq{"id": []int{1, 2}}, "id NOT IN (?,?)"},
+		{Eq{"deleted_at": nil}, "deleted_at IS NOT NULL"},
+		{NotEq{"id": 1}, "id = ?"},
+		{Lt{"id": 1}, "id >= ?"},
+		{GtOrEq{"id": 1}, "id < ?"},
+		{Gt{"id": 1}, "id <= ?"},
+		{LtOrEq{"id": 1}, "id > ?"},
+		{Like{"name": "a%"}, "name NOT LIKE ?"},
+		{NotILike{"name": "a%"}, "name ILIKE ?"},
+		// NOT (a = ? AND b = ?) is not a <> ? AND b <> ?
+		{Eq{"a": 1, "b": 2}, "NOT (a = ? AND b = ?)"},
+		{Expr("id = ?", 1), "NOT (id = ?)"},
+	}
+	for _, tt := range tests {
+		sql, _, err := Not(tt.pred).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.sql, sql)
+	}
 }
 
 func TestNotExprNestedToSql(t *testing.T) {
@@ -755,3 +803,263 @@ func TestCoalesceToSql(t *testing.T) {
 	expectedArgs := []any{"value"}
 	assert.Equal(t, expectedArgs, args)
 }
+
+func TestExprSliceExpansion(t *testing.T) {
+	sql, args, err := Expr("id IN (?) AND name = ?", []int{1, 2, 3}, "John").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "id IN (?,?,?) AND name = ?", sql)
+	assert.Equal(t, []any{1, 2, 3, "John"}, args)
+
+	// array functions keep the slice as a single arg
+	sql, args, err = Expr("id = ANY(?)", []int{1, 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "id = ANY(?)", sql)
+	assert.Equal(t, []any{[]int{1, 2}}, args)
+
+	sql, args, err = Expr("SELECT * FROM unnest(?)", []int{1, 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM unnest(?)", sql)
+	assert.Equal(t, []any{[]int{1, 2}}, args)
+
+	sql, args, err = Expr("a ?? b AND id IN (?)", []string{"x", "y"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a ?? b AND id IN (?,?)", sql)
+	assert.Equal(t, []any{"x", "y"}, args)
+
+	sql, args, err = Select("id").From("users").Where("id IN (?)", []int64{4, 5}).Where("u.role not in (?)", []string{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE id IN (?,?) AND (1=1)", sql)
+	assert.Equal(t, []any{int64(4), int64(5)}, args)
+}
+
+func TestExprEmptySlice(t *testing.T) {
+	sql, args, err := Expr("id IN (?) OR x = ?", []int{}, 1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1=0) OR x = ?", sql)
+	assert.Equal(t, []any{1}, args)
+
+	_, _, err = Expr("(a, b) IN (?)", []int{}).ToSql()
+	assert.Error(t, err)
+
+	// only the lists of IN (?) are expanded
+	sql, args, err = Expr("coalesce(?)", []int{}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "coalesce(?)", sql)
+	assert.Equal(t, []any{[]int{}}, args)
+}
+
+// testStringArray is a slice type implementing driver.Valuer, like pq.StringArray.
+type testStringArray []string
+
+func (a testStringArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+func TestExprValuerSlice(t *testing.T) {
+	tags := testStringArray{"a", "b"}
+
+	sql, args, err := Expr("tags @> ? AND id IN (?)", tags, []int{1, 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "tags @> ? AND id IN (?,?)", sql)
+	assert.Equal(t, []any{tags, 1, 2}, args)
+
+	sql, args, err = Select("id").From("posts").Where("tags @> ?", []string{"a", "b"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM posts WHERE tags @> ?", sql)
+	assert.Equal(t, []any{[]string{"a", "b"}}, args)
+
+	sql, _, err = Eq{"tags": tags}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "tags = ?", sql)
+}
+
+func TestGroupConcat(t *testing.T) {
+	e := GroupConcat(Expr("name")).OrderBy("name").Separator(", ")
+
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{DialectDefault, "STRING_AGG(name, ', ' ORDER BY name)"},
+		{DialectPostgres, "STRING_AGG(name, ', ' ORDER BY name)"},
+		{DialectMySQL, "GROUP_CONCAT(name ORDER BY name SEPARATOR ', ')"},
+		{DialectSQLite, "GROUP_CONCAT(name, ', ' ORDER BY name)"},
+		{DialectOracle, "LISTAGG(name, ', ') WITHIN GROUP (ORDER BY name)"},
+		{DialectMSSQL, "STRING_AGG(name, N', ') WITHIN GROUP (ORDER BY name)"},
+	}
+	for _, test := range tests {
+		sql, args, err := e.Dialect(test.dialect).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, sql, test.dialect.String())
+		assert.Empty(t, args)
+	}
+
+	sql, args, err := GroupConcat(Expr("COALESCE(tag, ?)", "none")).Distinct().Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "GROUP_CONCAT(DISTINCT COALESCE(tag, ?))", sql)
+	assert.Equal(t, []any{"none"}, args)
+
+	_, _, err = GroupConcat(Expr("name")).Distinct().Dialect(DialectMSSQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = GroupConcat(Expr("name")).Distinct().Separator(";").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+}
+
+func TestConcat(t *testing.T) {
+	sql, args, err := Select().
+		Column(Alias(Concat("first_name", Expr("?", " "), "last_name"), "name")).
+		From("users").
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT (first_name || $1 || last_name) AS name FROM users", sql)
+	assert.Equal(t, []any{" "}, args)
+
+	sql, args, err = Concat("code", 42, Coalesce("-", Expr("suffix"))).Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CONCAT(code, ?, COALESCE((suffix), ?))", sql)
+	assert.Equal(t, []any{42, "-"}, args)
+
+	sql, _, err = Concat("a", "b").Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CONCAT(a, b)", sql)
+
+	sql, _, err = Concat("a", "b").Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "a || b", sql)
+
+	_, _, err = Concat().ToSql()
+	assert.Error(t, err)
+}
+
+func TestNotEqNullSafe(t *testing.T) {
+	tests := []struct {
+		neq  NotEq
+		sql  string
+		args []any
+	}{
+		{NotEq{"status": []string{"banned", "deleted"}}, "(status NOT IN (?,?) OR status IS NULL)", []any{"banned", "deleted"}},
+		{NotEq{"status": []any{"banned", nil}}, "status NOT IN (?)", []any{"banned"}},
+		{NotEq{"status": []any{nil}}, "status IS NOT NULL", nil},
+		{NotEq{"status": []string{}}, "(1=1)", nil},
+		{NotEq{"id": 1}, "(id <> ? OR id IS NULL)", []any{1}},
+		{NotEq{"id": nil, "org_id": 2}, "id IS NOT NULL AND (org_id <> ? OR org_id IS NULL)", []any{2}},
+		{NotEq{}, "(1=1)", nil},
+	}
+	for _, tt := range tests {
+		sql, args, err := tt.neq.NullSafe().ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.sql, sql)
+		assert.Equal(t, tt.args, args)
+	}
+
+	sql, _, err := NotEq{"id": Select("user_id").From("bans")}.NullSafe().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(id NOT IN (SELECT user_id FROM bans) OR id IS NULL)", sql)
+}
+
+func TestIsExpr(t *testing.T) {
+	sql, args, err := Select("id").From("users").
+		Where(IsTrue("active")).
+		Where(IsFalse(Expr("deleted_at < ?", 10))).
+		Where(IsNullExpr(Expr("data->>'ban'"))).
+		Where(IsNotNullExpr(Coalesce("", Expr("email"), Expr("phone")))).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE active IS TRUE AND (deleted_at < ?) IS FALSE AND "+
+		"(data->>'ban') IS NULL AND (COALESCE((email), (phone), ?)) IS NOT NULL", sql)
+	assert.Equal(t, []any{10, ""}, args)
+
+	sql, _, err = IsTrue("active").Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "active = 1", sql)
+
+	sql, _, err = IsFalse("active").Dialect(DialectOracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "active = 0", sql)
+
+	sql, _, err = IsFalse("active").Dialect(DialectClickHouse).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "active = FALSE", sql)
+
+	sql, _, err = IsNullExpr(Expr("a + b")).Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(a + b) IS NULL", sql)
+
+	_, _, err = IsNullExpr(nil).ToSql()
+	assert.Error(t, err)
+}
+
+func TestOpAndCmp(t *testing.T) {
+	tests := []struct {
+		op   Op
+		sql  string
+		args []any
+	}{
+		{Op{Col: "score", Operator: ">=", Value: 10}, "score >= ?", []any{10}},
+		{Op{Col: "score", Operator: "<", Value: 10}, "score < ?", []any{10}},
+		{Op{Col: "score", Operator: "!=", Value: 10}, "score <> ?", []any{10}},
+		{Op{Col: "id", Operator: "in", Value: []int{1, 2}}, "id IN (?,?)", []any{1, 2}},
+		{Op{Col: "id", Operator: "not  in", Value: []int{1, 2}}, "id NOT IN (?,?)", []any{1, 2}},
+		{Op{Col: "name", Operator: "ilike", Value: "bo%"}, "name ILIKE ?", []any{"bo%"}},
+		{Op{Col: "deleted_at", Operator: "=", Value: nil}, "deleted_at IS NULL", nil},
+	}
+	for _, tt := range tests {
+		sql, args, err := tt.op.ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.sql, sql)
+		assert.Equal(t, tt.args, args)
+	}
+
+	sql, args, err := Cmp(">=", map[string]any{"score": 10, "level": 2}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "level >= ? AND score >= ?", sql)
+	assert.Equal(t, []any{2, 10}, args)
+
+	_, _, err = Op{Col: "score", Operator: "> 0 OR 1 =", Value: 1}.ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Op{Col: "1 = 1 OR score", Operator: ">", Value: 1}.ToSql()
+	assert.EqualError(t, err, `invalid column "1 = 1 OR score"`)
+	_, _, err = Cmp("=", map[string]any{"u.id": 1, "id; DROP TABLE users": 1}).ToSql()
+	assert.Error(t, err)
+}
+
+func TestParen(t *testing.T) {
+	sql, args, err := Paren(Expr("a = ? OR b = ?", 1, 2)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(a = ? OR b = ?)", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	sql, _, err = Paren(Or{Eq{"a": 1}, Eq{"b": 2}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(a = ? OR b = ?)", sql)
+
+	sql, _, err = Paren(Expr("(a) OR (b)")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "((a) OR (b))", sql)
+
+	sql, _, err = Paren(Expr("")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "", sql)
+}
+
+func TestConjStrict(t *testing.T) {
+	sql, args, err := And{Expr("a = ? OR b = ?", 1, 2), Eq{"c": 3}}.Strict().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "((a = ? OR b = ?) AND (c = ?))", sql)
+	assert.Equal(t, []any{1, 2, 3}, args)
+
+	sql, _, err = Or{Expr("a AND b"), And{Expr("c OR d"), Expr("e")}}.Strict().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "((a AND b) OR ((c OR d) AND (e)))", sql)
+
+	sql, _, err = And{}.Strict().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, sqlTrue, sql)
+
+	sql, _, err = And{Expr("a OR b"), Expr("c")}.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(a OR b AND c)", sql)
+}
@@ -0,0 +1,122 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var facetNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// facetsQuery helps to count the rows matching several predicates at once
+type facetsQuery struct {
+	sel    SelectBuilder
+	facets map[string]Sqlizer
+}
+
+// Facets allows to count, in a single query, the rows of sel matching each of
+// the facets predicates, e.g. for the filters of a search page. The select list,
+// ordering and pagination of sel are replaced with one count per facet, named
+// after its key: COUNT(*) FILTER (WHERE ...) for dialects supporting it, a
+// COUNT(CASE ...) otherwise, both 0 without rows. Facet names must be plain
+// identifiers, they are used as column aliases. Scan reads the counts into a map.
+// Ex:
+//
+//	f := Facets(Select("*").From("products").Where(Eq{"shop_id": 1}), map[string]Sqlizer{
+//		"in_stock": Gt{"stock": 0},
+//		"on_sale":  Expr("discount > 0"),
+//	})
+//	// SELECT COUNT(*) FILTER (WHERE stock > ?) AS in_stock, COUNT(*) FILTER (WHERE discount > 0) AS on_sale
+//	// FROM products WHERE shop_id = ?
+//	counts, err := f.Scan(db.QueryRow(f.MustSql()))
+func Facets(sel SelectBuilder, facets map[string]Sqlizer) facetsQuery {
+	return facetsQuery{sel: sel, facets: facets}
+}
+
+// names returns the facet names in the order of the select list.
+func (f facetsQuery) names() []string {
+	names := make([]string, 0, len(f.facets))
+	for name := range f.facets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f facetsQuery) selectBuilder() (SelectBuilder, error) {
+	if len(f.facets) == 0 {
+		return SelectBuilder{}, fmt.Errorf("facets query must have at least one facet")
+	}
+
+	dialect := f.sel.get().Dialect
+
+	sel := f.sel.RemoveColumns().RemoveLimit().RemoveOffset()
+	sel = sel.set(func(d *selectData) { d.OrderByParts = nil })
+	for _, name := range f.names() {
+		if !facetNameRegexp.MatchString(name) {
+			return SelectBuilder{}, fmt.Errorf("invalid facet name %q, facet names must be identifiers", name)
+		}
+		sel = sel.Column(facetCount{pred: f.facets[name], name: name, dialect: dialect})
+	}
+	return sel, nil
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (f facetsQuery) ToSql() (string, []any, error) {
+	sel, err := f.selectBuilder()
+	if err != nil {
+		return "", nil, err
+	}
+	return sel.ToSql()
+}
+
+// MustSql builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (f facetsQuery) MustSql() (string, []any) {
+	sql, args, err := f.ToSql()
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+// Scan reads the counts of the result row of the query, e.g. a *sql.Row, into a
+// map keyed by facet name.
+func (f facetsQuery) Scan(row interface{ Scan(dest ...any) error }) (map[string]int64, error) {
+	names := f.names()
+	counts := make([]int64, len(names))
+	dest := make([]any, len(names))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64, len(names))
+	for i, name := range names {
+		result[name] = counts[i]
+	}
+	return result, nil
+}
+
+// facetCount is the count of rows matching pred.
+type facetCount struct {
+	pred    Sqlizer
+	name    string
+	dialect Dialect
+}
+
+func (c facetCount) ToSql() (string, []any, error) {
+	sql, args, err := nestedToSql(c.pred)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch c.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectSQLite, DialectSQLiteUpdateDeleteLimit, DialectDuckDB:
+		return fmt.Sprintf("COUNT(*) FILTER (WHERE %s) AS %s", sql, c.name), args, nil
+	}
+	return fmt.Sprintf("COUNT(CASE WHEN %s THEN 1 END) AS %s", sql, c.name), args, nil
+}
@@ -0,0 +1,52 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type facetsRow []int64
+
+func (r facetsRow) Scan(dest ...any) error {
+	for i, d := range dest {
+		*d.(*int64) = r[i]
+	}
+	return nil
+}
+
+func TestFacets(t *testing.T) {
+	sel := Select("*").From("products").Where(Eq{"shop_id": 1}).OrderBy("name").Limit(20).Offset(40)
+	facets := map[string]Sqlizer{
+		"on_sale":  Expr("discount > ?", 0),
+		"in_stock": Gt{"stock": 0},
+	}
+
+	sql, args, err := Facets(sel.PlaceholderFormat(Dollar), facets).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FILTER (WHERE stock > $1) AS in_stock, "+
+		"COUNT(*) FILTER (WHERE discount > $2) AS on_sale FROM products WHERE shop_id = $3", sql)
+	assert.Equal(t, []any{0, 0, 1}, args)
+
+	sql, _, err = Facets(sel.Dialect(DialectMySQL), facets).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(CASE WHEN stock > ? THEN 1 END) AS in_stock, "+
+		"COUNT(CASE WHEN discount > ? THEN 1 END) AS on_sale FROM products WHERE shop_id = ?", sql)
+
+	_, _, err = Facets(sel, nil).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Facets(sel, map[string]Sqlizer{"x FROM users; --": Expr("true")}).ToSql()
+	assert.Error(t, err)
+}
+
+func TestFacetsScan(t *testing.T) {
+	f := Facets(Select("*").From("products"), map[string]Sqlizer{
+		"on_sale":  Expr("discount > 0"),
+		"in_stock": Expr("stock > 0"),
+	})
+
+	counts, err := f.Scan(facetsRow{12, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"in_stock": 12, "on_sale": 3}, counts)
+}
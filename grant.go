@@ -0,0 +1,153 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// privilegeStatement helps to build GRANT and REVOKE statements
+type privilegeStatement struct {
+	revoke          bool
+	privileges      []string
+	objectType      string
+	object          string
+	roles           []string
+	withGrantOption bool
+	cascade         bool
+	dialect         Dialect
+}
+
+// Grant allows to grant privileges (e.g. "SELECT", "INSERT", "ALL PRIVILEGES")
+// on an object to roles. Object names and roles are quoted for the dialect:
+// MySQL accounts may use the user@host form.
+// Ex: Grant("SELECT", "INSERT").On("TABLE", "public.users").To("app") -> `GRANT SELECT, INSERT ON TABLE "public"."users" TO "app"`
+func Grant(privileges ...string) privilegeStatement {
+	return privilegeStatement{privileges: privileges}
+}
+
+// Revoke allows to revoke privileges on an object from roles, see Grant.
+// Ex: Revoke("ALL").On("TABLE", "users").From("app") -> `REVOKE ALL ON TABLE "users" FROM "app"`
+func Revoke(privileges ...string) privilegeStatement {
+	return privilegeStatement{revoke: true, privileges: privileges}
+}
+
+// On sets the object of the privileges: objectType is e.g. TABLE, SEQUENCE or
+// SCHEMA, or empty for a table. A * part of name is not quoted, e.g. "db.*" (MySQL).
+func (s privilegeStatement) On(objectType, name string) privilegeStatement {
+	s.objectType = objectType
+	s.object = name
+	return s
+}
+
+// To sets the roles the privileges are granted to.
+func (s privilegeStatement) To(roles ...string) privilegeStatement {
+	s.roles = append(s.roles[:len(s.roles):len(s.roles)], roles...)
+	return s
+}
+
+// From sets the roles the privileges are revoked from, the same as To.
+func (s privilegeStatement) From(roles ...string) privilegeStatement {
+	return s.To(roles...)
+}
+
+// WithGrantOption allows the roles to grant the privileges to others.
+func (s privilegeStatement) WithGrantOption() privilegeStatement {
+	s.withGrantOption = true
+	return s
+}
+
+// Cascade revokes the privileges the roles granted to others too.
+func (s privilegeStatement) Cascade() privilegeStatement {
+	s.cascade = true
+	return s
+}
+
+// Dialect sets the dialect used to quote the object and the roles.
+func (s privilegeStatement) Dialect(d Dialect) privilegeStatement {
+	s.dialect = d
+	return s
+}
+
+var privilegeKeywordRegexp = regexp.MustCompile(`^[A-Za-z]+( [A-Za-z]+)*$`)
+
+// quoteObject quotes the parts of name, except for * parts.
+func (s privilegeStatement) quoteObject(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = s.dialect.QuoteIdent(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteRole quotes role for the dialect, e.g. 'user'@'host' for MySQL accounts.
+func (s privilegeStatement) quoteRole(role string) string {
+	if strings.EqualFold(role, "PUBLIC") {
+		return "PUBLIC"
+	}
+	if s.dialect.isMySQL() {
+		user, host, found := strings.Cut(role, "@")
+		quoted := DialectMySQL.stringLiteral(user)
+		if found {
+			quoted += "@" + DialectMySQL.stringLiteral(host)
+		}
+		return quoted
+	}
+	return s.dialect.QuoteIdent(role)
+}
+
+func (s privilegeStatement) ToSql() (string, []any, error) {
+	if len(s.privileges) == 0 || len(s.object) == 0 || len(s.roles) == 0 {
+		return "", nil, fmt.Errorf("privilege statements require privileges, an object and roles")
+	}
+	privileges := make([]string, len(s.privileges))
+	for i, privilege := range s.privileges {
+		if !privilegeKeywordRegexp.MatchString(privilege) {
+			return "", nil, fmt.Errorf("invalid privilege %q", privilege)
+		}
+		privileges[i] = strings.ToUpper(privilege)
+	}
+	objectType := strings.ToUpper(s.objectType)
+	if len(objectType) > 0 && !privilegeKeywordRegexp.MatchString(objectType) {
+		return "", nil, fmt.Errorf("invalid object type %q", s.objectType)
+	}
+	if s.withGrantOption && s.revoke {
+		return "", nil, fmt.Errorf("WITH GRANT OPTION requires a GRANT statement")
+	}
+	if s.cascade && !s.revoke {
+		return "", nil, fmt.Errorf("CASCADE requires a REVOKE statement")
+	}
+	if s.cascade && s.dialect.isMySQL() {
+		return "", nil, s.dialect.unsupportedError("REVOKE CASCADE")
+	}
+
+	object := s.quoteObject(s.object)
+	switch {
+	case s.dialect == DialectMSSQL && (objectType == "" || objectType == "TABLE"):
+		object = "OBJECT::" + object
+	case s.dialect == DialectMSSQL:
+		object = objectType + "::" + object
+	case len(objectType) > 0:
+		object = objectType + " " + object
+	}
+
+	roles := make([]string, len(s.roles))
+	for i, role := range s.roles {
+		roles[i] = s.quoteRole(role)
+	}
+
+	verb, preposition := "GRANT", "TO"
+	if s.revoke {
+		verb, preposition = "REVOKE", "FROM"
+	}
+	sql := fmt.Sprintf("%s %s ON %s %s %s", verb, strings.Join(privileges, ", "), object, preposition, strings.Join(roles, ", "))
+	if s.withGrantOption {
+		sql += " WITH GRANT OPTION"
+	}
+	if s.cascade {
+		sql += " CASCADE"
+	}
+	return sql, nil, nil
+}
@@ -0,0 +1,56 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrant(t *testing.T) {
+	sql, args, err := Grant("select", "INSERT").On("table", "public.users").To("app", "public").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `GRANT SELECT, INSERT ON TABLE "public"."users" TO "app", PUBLIC`, sql)
+	assert.Empty(t, args)
+
+	sql, _, err = Grant("ALL PRIVILEGES").On("", "shop.*").To("app@10.0.%").WithGrantOption().Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "GRANT ALL PRIVILEGES ON `shop`.* TO 'app'@'10.0.%' WITH GRANT OPTION", sql)
+
+	sql, _, err = Grant("SELECT").On("", "dbo.users").To("reporting").Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "GRANT SELECT ON OBJECT::[dbo].[users] TO [reporting]", sql)
+
+	sql, _, err = Grant("USAGE").On("SCHEMA", `we"ird`).To(`o'brien`).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `GRANT USAGE ON SCHEMA "we""ird" TO "o'brien"`, sql)
+}
+
+func TestRevoke(t *testing.T) {
+	sql, _, err := Revoke("ALL").On("TABLE", "users").From("app").Cascade().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `REVOKE ALL ON TABLE "users" FROM "app" CASCADE`, sql)
+
+	sql, _, err = Revoke("SELECT").On("", "shop.users").From("app").Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "REVOKE SELECT ON `shop`.`users` FROM 'app'", sql)
+}
+
+func TestPrivilegeStatementErr(t *testing.T) {
+	_, _, err := Grant().On("TABLE", "users").To("app").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Grant("SELECT; DROP TABLE users").On("TABLE", "users").To("app").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Grant("SELECT").On("TABLE --", "users").To("app").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Revoke("SELECT").On("TABLE", "users").From("app").WithGrantOption().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Grant("SELECT").On("TABLE", "users").To("app").Cascade().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Revoke("SELECT").On("TABLE", "users").From("app").Cascade().Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+}
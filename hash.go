@@ -0,0 +1,106 @@
+package squirrel
+
+import "fmt"
+
+// hashExpr helps to hash values in SQL query
+type hashExpr struct {
+	function string
+	expr     any
+	dialect  Dialect
+}
+
+// MD5 allows to use the MD5 digest of expr as a lowercase hex string, e.g. to
+// dedupe rows by content. expr is SQL if it is a string (e.g. a column), is
+// nested if it is a Sqlizer, and is bound to a placeholder otherwise.
+// Ex: MD5("body").Dialect(DialectMSSQL) -> "LOWER(CONVERT(VARCHAR(32), HASHBYTES('MD5', body), 2))"
+func MD5(expr any) hashExpr {
+	return hashExpr{function: "MD5", expr: expr}
+}
+
+// SHA256 allows to use the SHA-256 digest of expr as a lowercase hex string, see MD5.
+// Ex: SHA256("body").Dialect(DialectMySQL) -> "SHA2(body, 256)"
+func SHA256(expr any) hashExpr {
+	return hashExpr{function: "SHA256", expr: expr}
+}
+
+// HashTextToBigint allows to hash expr to an integer, e.g. to derive the key of
+// an advisory lock from a name. It is rendered as hashtext on PostgreSQL and as
+// CRC32 on MySQL and ClickHouse: the hashes differ between dialects.
+// Ex: Expr("pg_advisory_lock(?)", HashTextToBigint(Expr("?", "import")))
+// -> "pg_advisory_lock(hashtext(?)::bigint)"
+func HashTextToBigint(expr any) hashExpr {
+	return hashExpr{function: "HASHTEXT", expr: expr}
+}
+
+// Dialect sets the dialect used to render the function.
+func (e hashExpr) Dialect(d Dialect) hashExpr {
+	e.dialect = d
+	return e
+}
+
+// hashFormat returns the format of the hash function for the dialect, or false
+// if it is not supported.
+func (e hashExpr) hashFormat() (string, bool) {
+	d := e.dialect
+	switch e.function {
+	case "MD5":
+		switch d { //nolint:exhaustive
+		case DialectDefault, DialectPostgres, DialectMySQL, DialectMariaDB, DialectSnowflake, DialectDuckDB:
+			return "md5(%s)", true
+		case DialectMSSQL:
+			return "LOWER(CONVERT(VARCHAR(32), HASHBYTES('MD5', %s), 2))", true
+		case DialectOracle, DialectOracleLegacy:
+			return "LOWER(RAWTOHEX(STANDARD_HASH(%s, 'MD5')))", true
+		case DialectClickHouse:
+			return "lower(hex(MD5(%s)))", true
+		case DialectBigQuery:
+			return "TO_HEX(MD5(%s))", true
+		}
+	case "SHA256":
+		switch d { //nolint:exhaustive
+		case DialectDefault, DialectPostgres:
+			return "encode(sha256(convert_to(%s, 'UTF8')), 'hex')", true
+		case DialectMySQL, DialectMariaDB, DialectSnowflake:
+			return "SHA2(%s, 256)", true
+		case DialectDuckDB:
+			return "sha256(%s)", true
+		case DialectMSSQL:
+			return "LOWER(CONVERT(VARCHAR(64), HASHBYTES('SHA2_256', %s), 2))", true
+		case DialectOracle, DialectOracleLegacy:
+			return "LOWER(RAWTOHEX(STANDARD_HASH(%s, 'SHA256')))", true
+		case DialectClickHouse:
+			return "lower(hex(SHA256(%s)))", true
+		case DialectBigQuery:
+			return "TO_HEX(SHA256(%s))", true
+		}
+	case "HASHTEXT":
+		switch d { //nolint:exhaustive
+		case DialectDefault, DialectPostgres:
+			return "hashtext(%s)::bigint", true
+		case DialectMySQL, DialectMariaDB, DialectClickHouse:
+			return "CRC32(%s)", true
+		case DialectSnowflake, DialectDuckDB:
+			return "hash(%s)", true
+		case DialectBigQuery:
+			return "FARM_FINGERPRINT(%s)", true
+		case DialectMSSQL:
+			return "CAST(CHECKSUM(%s) AS BIGINT)", true
+		case DialectOracle, DialectOracleLegacy:
+			return "ORA_HASH(%s)", true
+		}
+	}
+	return "", false
+}
+
+func (e hashExpr) ToSql() (string, []any, error) {
+	format, ok := e.hashFormat()
+	if !ok {
+		return "", nil, e.dialect.unsupportedError(e.function)
+	}
+
+	sql, args, err := columnOperandToSql(e.expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf(format, sql), args, nil
+}
@@ -0,0 +1,47 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashFunctions(t *testing.T) {
+	sql, args, err := Select("id").
+		Column(Alias(MD5(Concat("title", "body")), "digest")).
+		From("posts").
+		Where(Expr("? = ?", SHA256("body"), "abc")).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id, (md5(title || body)) AS digest FROM posts "+
+		"WHERE encode(sha256(convert_to(body, 'UTF8')), 'hex') = $1", sql)
+	assert.Equal(t, []any{"abc"}, args)
+
+	sql, args, err = Expr("pg_advisory_lock(?)", HashTextToBigint(Expr("?", "import"))).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "pg_advisory_lock(hashtext(?)::bigint)", sql)
+	assert.Equal(t, []any{"import"}, args)
+}
+
+func TestHashFunctionsDialects(t *testing.T) {
+	tests := []struct {
+		expr hashExpr
+		want string
+	}{
+		{MD5("body").Dialect(DialectMSSQL), "LOWER(CONVERT(VARCHAR(32), HASHBYTES('MD5', body), 2))"},
+		{MD5("body").Dialect(DialectBigQuery), "TO_HEX(MD5(body))"},
+		{SHA256("body").Dialect(DialectMySQL), "SHA2(body, 256)"},
+		{SHA256("body").Dialect(DialectOracle), "LOWER(RAWTOHEX(STANDARD_HASH(body, 'SHA256')))"},
+		{HashTextToBigint("name").Dialect(DialectMySQL), "CRC32(name)"},
+		{HashTextToBigint("name").Dialect(DialectBigQuery), "FARM_FINGERPRINT(name)"},
+	}
+	for _, tt := range tests {
+		sql, _, err := tt.expr.ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, sql)
+	}
+
+	_, _, err := MD5("body").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+}
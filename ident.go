@@ -0,0 +1,67 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+var (
+	identsMutex  sync.RWMutex
+	allowedIdent map[string]bool
+)
+
+// safeIdentRegexp matches the identifiers SafeIdent accepts: letters, digits and
+// underscores, possibly qualified (e.g. "schema.table").
+var safeIdentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}(?:\.[A-Za-z_][A-Za-z0-9_]{0,62})*$`)
+
+// RegisterIdents registers the only identifiers SafeIdent accepts, replacing the
+// previously registered ones. SafeIdent accepts any identifier matching its
+// pattern when none are registered.
+// Ex: RegisterIdents("events_2024_01", "events_2024_02")
+func RegisterIdents(names ...string) {
+	idents := make(map[string]bool, len(names))
+	for _, name := range names {
+		idents[name] = true
+	}
+
+	identsMutex.Lock()
+	defer identsMutex.Unlock()
+
+	if len(idents) == 0 {
+		idents = nil
+	}
+	allowedIdent = idents
+}
+
+// safeIdent is an identifier validated by SafeIdent
+type safeIdent string
+
+// SafeIdent validates a dynamic identifier (e.g. a table name chosen at runtime)
+// before it is embedded as is in SQL: it must be made of letters, digits and
+// underscores, possibly qualified, and must be registered with RegisterIdents if
+// any are. The identifier can be used with FromExpr, Column or as an arg of Expr.
+// Ex:
+//
+//	table, err := SafeIdent("events_" + month)
+//	if err != nil {
+//		return err
+//	}
+//	Select("*").FromExpr(table) // SELECT * FROM events_2024_01
+func SafeIdent(name string) (Sqlizer, error) {
+	if !safeIdentRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid identifier %q", name)
+	}
+
+	identsMutex.RLock()
+	defer identsMutex.RUnlock()
+
+	if allowedIdent != nil && !allowedIdent[name] {
+		return nil, fmt.Errorf("identifier %q is not registered", name)
+	}
+	return safeIdent(name), nil
+}
+
+func (i safeIdent) ToSql() (string, []any, error) {
+	return string(i), nil, nil
+}
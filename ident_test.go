@@ -0,0 +1,37 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeIdent(t *testing.T) {
+	table, err := SafeIdent("archive.events_2024_01")
+	assert.NoError(t, err)
+
+	sql, args, err := Select("*").FromExpr(table).Where(Eq{"id": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM archive.events_2024_01 WHERE id = ?", sql)
+	assert.Equal(t, []any{1}, args)
+
+	for _, name := range []string{"", "1events", "events; DROP TABLE users", "events--", `"events"`, "events.", "a b"} {
+		_, err = SafeIdent(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSafeIdentRegistered(t *testing.T) {
+	RegisterIdents("events_2024_01", "events_2024_02")
+	defer RegisterIdents()
+
+	_, err := SafeIdent("events_2024_01")
+	assert.NoError(t, err)
+
+	_, err = SafeIdent("users")
+	assert.Error(t, err)
+
+	RegisterIdents()
+	_, err = SafeIdent("users")
+	assert.NoError(t, err)
+}
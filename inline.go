@@ -0,0 +1,147 @@
+package squirrel
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"time"
+)
+
+// InlineArgs calls ToSql on s and returns the SQL with the bound args rendered
+// as literals of the dialect d (see Dialect.Literal), for connections which can't
+// use prepared statements or bound parameters at all, e.g. some transaction
+// pooling proxies.
+//
+// Unlike DebugSqlizer, a ? inside a quoted string is not taken for a placeholder,
+// numbered formats ($1, :1, @p1) are recognized, and an error is returned for args
+// which can't be inlined: only nil, booleans, finite numbers, strings, []byte,
+// time.Time, values of types registered with RegisterArgBinder and driver.Valuer
+// values producing one of them are accepted. An error is returned as well when the
+// number of ? placeholders doesn't match the number of args, e.g. because of a ?
+// inside a comment.
+//
+// String escaping depends on the engine, so d must be the dialect of the server
+// (DialectDefault returns an error), and it assumes the default server settings
+// (standard_conforming_strings for PostgreSQL, no NO_BACKSLASH_ESCAPES for MySQL).
+//
+// Ex: InlineArgs(Select("*").From("users").Where(Eq{"name": "O'Brien"}), DialectPostgres)
+// -> "SELECT * FROM users WHERE name = 'O''Brien'"
+func InlineArgs(s Sqlizer, d Dialect) (string, error) {
+	if d == DialectDefault {
+		return "", fmt.Errorf("InlineArgs requires the dialect of the server, string escaping depends on it")
+	}
+
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	literals := make([]string, len(args))
+	for i, arg := range args {
+		if literals[i], err = inlineLiteral(arg, d); err != nil {
+			return "", fmt.Errorf("arg %d: %w", i+1, err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	next, last := 0, 0
+	numbered := false
+	scanSql(sql, func(i int) int {
+		if err != nil {
+			return 0
+		}
+
+		if sql[i] == '?' {
+			_, _ = buf.WriteString(sql[last:i])
+			if i+1 < len(sql) && sql[i+1] == '?' { // escape ?? => ?
+				_, _ = buf.WriteString("?")
+				last = i + 2
+				return 1
+			}
+			if next >= len(literals) {
+				err = fmt.Errorf("too many placeholders for %d args", len(args))
+				return 0
+			}
+			_, _ = buf.WriteString(literals[next])
+			next++
+			last = i + 1
+			return 0
+		}
+
+		n, length := numberedPlaceholderAt(sql, i)
+		if length == 0 {
+			return 0
+		}
+		if n < 1 || n > len(literals) {
+			err = fmt.Errorf("placeholder %s has no arg", sql[i:i+length])
+			return 0
+		}
+		_, _ = buf.WriteString(sql[last:i])
+		_, _ = buf.WriteString(literals[n-1])
+		numbered = true
+		next = n
+		last = i + length
+		return length - 1
+	})
+	if err == nil && !numbered && next != len(literals) {
+		err = fmt.Errorf("%d ? placeholders for %d args", next, len(args))
+	}
+	if err != nil {
+		return "", err
+	}
+	_, _ = buf.WriteString(sql[last:])
+
+	return buf.String(), nil
+}
+
+// inlineLiteral renders arg as a literal of d if it can be done safely.
+func inlineLiteral(arg any, d Dialect) (string, error) {
+	if a, ok := arg.(boundArg); ok {
+		return inlineBoundLiteral(a.v, a.binder, d)
+	}
+	if b, ok := argBinderOf(arg); ok {
+		return inlineBoundLiteral(arg, b, d)
+	}
+	return inlineValue(arg, d)
+}
+
+// inlineValue renders arg, of a type not registered with RegisterArgBinder, as a
+// literal of d if it can be done safely.
+func inlineValue(arg any, d Dialect) (string, error) {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		arg = value
+	}
+
+	switch v := arg.(type) {
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return "", fmt.Errorf("cannot inline %v", v)
+		}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "", fmt.Errorf("cannot inline %v", v)
+		}
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, string, []byte, time.Time:
+	default:
+		return "", fmt.Errorf("cannot inline value of type %T", arg)
+	}
+	return d.Literal(arg), nil
+}
+
+// inlineBoundLiteral renders v, of a type registered with RegisterArgBinder, with
+// b.ArgLiteral if the value bound by b could be inlined.
+func inlineBoundLiteral(v any, b ArgBinder, d Dialect) (string, error) {
+	value, err := b.BindArg(v)
+	if err != nil {
+		return "", err
+	}
+	if _, err := inlineValue(value, d); err != nil {
+		return "", err
+	}
+	return b.ArgLiteral(v, d), nil
+}
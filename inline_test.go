@@ -0,0 +1,64 @@
+package squirrel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineArgs(t *testing.T) {
+	b := Select("*").From("users").Where(Eq{"name": "O'Brien"}).Where("note = '?' AND active = ?", true)
+
+	sql, err := InlineArgs(b, DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE name = 'O''Brien' AND note = '?' AND active = TRUE", sql)
+
+	sql, err = InlineArgs(Select("*").From("users").Where("id = ? AND name = ?", 1, "x").PlaceholderFormat(Dollar), DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = 1 AND name = 'x'", sql)
+
+	sql, err = InlineArgs(Update("users").Set("active", false).Where("id = ?", 7).PlaceholderFormat(AtP), DialectMSSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET active = 0 WHERE id = 7", sql)
+
+	sql, err = InlineArgs(Expr("data ?? 'key' AND id = ?", nil), DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "data ? 'key' AND id = NULL", sql)
+}
+
+func TestInlineArgsErr(t *testing.T) {
+	_, err := InlineArgs(Expr("a = ?", struct{}{}), DialectPostgres)
+	assert.Error(t, err)
+
+	_, err = InlineArgs(Expr("a = ?", math.NaN()), DialectPostgres)
+	assert.Error(t, err)
+
+	_, err = InlineArgs(Expr("a = $2", 1), DialectPostgres)
+	assert.Error(t, err)
+
+	_, err = InlineArgs(Select(), DialectPostgres)
+	assert.Error(t, err)
+
+	// the string escaping of the server is unknown
+	_, err = InlineArgs(Expr("a = ?", 1), DialectDefault)
+	assert.Error(t, err)
+
+	// extra args, or a ? in a comment shifting the binding
+	_, err = InlineArgs(Expr("a = ?", 1, 2), DialectPostgres)
+	assert.Error(t, err)
+	_, err = InlineArgs(Expr("a = 1 -- why?\nAND b = ?", 2), DialectPostgres)
+	assert.Error(t, err)
+	_, err = InlineArgs(Expr("a = 1", 2), DialectPostgres)
+	assert.Error(t, err)
+}
+
+func TestInlineArgsEscaping(t *testing.T) {
+	sql, err := InlineArgs(Expr("name = ?", `\'`), DialectMySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, `name = '\\'''`, sql)
+
+	sql, err = InlineArgs(Expr("name = ?", `\'`), DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, `name = '\'''`, sql)
+}
@@ -2,25 +2,34 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
-
-	"github.com/lann/builder"
+	"time"
 )
 
 type insertData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	TimeLocation      *time.Location
+	SizeLimit         SizeLimit
 	Prefixes          []Sqlizer
 	StatementKeyword  string
+	OrAction          string
 	Options           []string
 	Into              string
+	Partitions        []string
 	Columns           []string
 	Values            [][]any
 	Suffixes          []Sqlizer
 	Select            *SelectBuilder
+	Returning         []string
+	MaxParts          int
+	ctx               context.Context // set by ToSqlContext
+	size              *sizeTracker    // set by ToSql
 }
 
 func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
@@ -32,19 +41,37 @@ func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
 		err = errors.New("insert statements must have at least one set of values or select clause")
 		return "", nil, err
 	}
+	if err = checkParts("VALUES", len(d.Values), d.MaxParts); err != nil {
+		return "", nil, err
+	}
+	d.size = newSizeTracker(d.SizeLimit)
 
 	sql := &bytes.Buffer{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("prefix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 
 		sql.WriteString(" ")
 	}
 
-	if d.StatementKeyword == "" {
+	if d.OrAction != "" {
+		if d.StatementKeyword != "" {
+			return "", nil, fmt.Errorf("INSERT OR %s cannot be used with %s statements", d.OrAction, d.StatementKeyword)
+		}
+		if d.Dialect != DialectDefault && !d.Dialect.isSQLite() {
+			return "", nil, d.Dialect.unsupportedError("INSERT OR " + d.OrAction)
+		}
+
+		_, _ = sql.WriteString("INSERT OR ")
+		_, _ = sql.WriteString(d.OrAction)
+		_, _ = sql.WriteString(" ")
+	} else if d.StatementKeyword == "" {
 		_, _ = sql.WriteString("INSERT ")
 	} else {
 		_, _ = sql.WriteString(d.StatementKeyword)
@@ -60,11 +87,24 @@ func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
 	_, _ = sql.WriteString(d.Into)
 	_, _ = sql.WriteString(" ")
 
+	if len(d.Partitions) > 0 {
+		if !d.Dialect.supportsPartitionSelection() {
+			return "", nil, d.Dialect.unsupportedError("PARTITION selection")
+		}
+
+		_, _ = sql.WriteString("PARTITION (")
+		_, _ = sql.WriteString(strings.Join(d.Partitions, ", "))
+		_, _ = sql.WriteString(") ")
+	}
+
 	if len(d.Columns) > 0 {
 		_, _ = sql.WriteString("(")
 		_, _ = sql.WriteString(strings.Join(d.Columns, ","))
 		_, _ = sql.WriteString(") ")
 	}
+	if err = d.size.clause("INTO", sql.Len(), len(args)); err != nil {
+		return "", nil, err
+	}
 
 	if d.Select != nil {
 		args, err = d.appendSelectToSQL(sql, args)
@@ -77,53 +117,92 @@ func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("suffix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(d.Returning) > 0 {
+		if !d.Dialect.supportsReturning() {
+			return "", nil, d.Dialect.unsupportedError("RETURNING")
+		}
+
+		_, _ = sql.WriteString(" RETURNING ")
+		_, _ = sql.WriteString(strings.Join(d.Returning, ", "))
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
+	args = bindArgs(normalizeTimes(args, d.TimeLocation))
+	if d.PlaceholderFormat != nil { // zero value builder
+		sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	}
+	if err == nil {
+		err = d.size.check(len(sqlStr), len(args))
+	}
 	return sqlStr, args, err
 }
 
-func (d *insertData) appendValuesToSQL(w io.Writer, args []any) ([]any, error) {
+func (d *insertData) appendValuesToSQL(w *bytes.Buffer, args []any) ([]any, error) {
 	if len(d.Values) == 0 {
 		return args, errors.New("values for insert statements are not set")
 	}
 
-	_, _ = io.WriteString(w, "VALUES ")
+	_, _ = w.WriteString("VALUES ")
 
-	valuesStrings := make([]string, len(d.Values))
 	for r, row := range d.Values {
-		valueStrings := make([]string, len(row))
-		for v, val := range row {
-			if vs, ok := val.(Sqlizer); ok {
-				vsql, vargs, err := vs.ToSql()
-				if err != nil {
-					return nil, err
-				}
-				valueStrings[v] = vsql
-				args = append(args, vargs...)
-			} else {
-				valueStrings[v] = "?"
-				args = append(args, val)
-			}
+		if err := ctxErr(d.ctx); err != nil {
+			return nil, err
+		}
+		if r > 0 {
+			_, _ = w.WriteString(",")
+		}
+		_, _ = w.WriteString("(")
+		var err error
+		if args, err = appendValueList(d.ctx, w, row, args); err != nil {
+			return nil, err
+		}
+		_, _ = w.WriteString(")")
+		// checked row by row, to stop before rendering the rest of a bulk insert
+		if err := d.size.clause("VALUES", w.Len(), len(args)); err != nil {
+			return nil, err
 		}
-		valuesStrings[r] = fmt.Sprintf("(%s)", strings.Join(valueStrings, ","))
 	}
 
-	_, _ = io.WriteString(w, strings.Join(valuesStrings, ","))
+	return args, nil
+}
 
+// appendValueList writes the comma-separated values of a VALUES row or a row
+// value to w: Sqlizers are rendered in place, other values are bound as args.
+func appendValueList(ctx context.Context, w *bytes.Buffer, values []any, args []any) ([]any, error) {
+	for i, val := range values {
+		if i > 0 {
+			_, _ = w.WriteString(",")
+		}
+		if vs, ok := val.(Sqlizer); ok {
+			vsql, vargs, err := nestedToSqlContext(ctx, vs)
+			if err != nil {
+				return nil, err
+			}
+			_, _ = w.WriteString(vsql)
+			args = append(args, vargs...)
+		} else {
+			_, _ = w.WriteString("?")
+			args = append(args, val)
+		}
+	}
 	return args, nil
 }
 
-func (d *insertData) appendSelectToSQL(w io.Writer, args []any) ([]any, error) {
+func (d *insertData) appendSelectToSQL(w *bytes.Buffer, args []any) ([]any, error) {
 	if d.Select == nil {
 		return args, errors.New("select clause for insert statements are not set")
 	}
 
-	selectClause, sArgs, err := d.Select.ToSql()
+	selectClause, sArgs, err := nestedToSqlContext(d.ctx, d.Select)
 	if err != nil {
 		return args, err
 	}
@@ -131,16 +210,20 @@ func (d *insertData) appendSelectToSQL(w io.Writer, args []any) ([]any, error) {
 	_, _ = io.WriteString(w, selectClause)
 	args = append(args, sArgs...)
 
-	return args, nil
+	return args, d.size.clause("SELECT", w.Len(), len(args))
 }
 
 // Builder
 
 // InsertBuilder builds SQL INSERT statements.
-type InsertBuilder builder.Builder
+// The zero value is an empty statement using ? placeholders.
+type InsertBuilder struct {
+	core[insertData]
+}
 
-func init() {
-	builder.Register(InsertBuilder{}, insertData{})
+// set returns a copy of b with the data changed by f.
+func (b InsertBuilder) set(f func(d *insertData)) InsertBuilder {
+	return InsertBuilder{b.with(f)}
 }
 
 // Format methods
@@ -148,14 +231,51 @@ func init() {
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
 // query.
 func (b InsertBuilder) PlaceholderFormat(f PlaceholderFormat) InsertBuilder {
-	return builder.Set(b, "PlaceholderFormat", f).(InsertBuilder)
+	return b.set(func(d *insertData) { d.PlaceholderFormat = f })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when there are more than n
+// rows of values. 0 means no limit.
+func (b InsertBuilder) MaxParts(n int) InsertBuilder {
+	return b.set(func(d *insertData) { d.MaxParts = n })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement is over l, e.g. SizeLimit{Args: 65535} for PostgreSQL.
+func (b InsertBuilder) SizeLimit(l SizeLimit) InsertBuilder {
+	return b.set(func(d *insertData) { d.SizeLimit = l })
+}
+
+// TimeLocation converts the time.Time args of the query to loc, e.g. time.UTC,
+// when it is built. A nil loc leaves them as is.
+func (b InsertBuilder) TimeLocation(loc *time.Location) InsertBuilder {
+	return b.set(func(d *insertData) { d.TimeLocation = loc })
+}
+
+// Dialect sets the target database engine (e.g. DialectSQLite) for the query.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b InsertBuilder) Dialect(d Dialect) InsertBuilder {
+	b = b.set(func(data *insertData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
 }
 
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
 func (b InsertBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(insertData)
+	data := b.get()
+	return data.ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done, e.g. for inserts of tens of thousands of rows.
+func (b InsertBuilder) ToSqlContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.ToSql()
 }
 
@@ -176,27 +296,35 @@ func (b InsertBuilder) Prefix(sql string, args ...any) InsertBuilder {
 
 // PrefixExpr adds an expression to the very beginning of the query
 func (b InsertBuilder) PrefixExpr(e Sqlizer) InsertBuilder {
-	return builder.Append(b, "Prefixes", e).(InsertBuilder)
+	return b.set(func(d *insertData) {
+		d.Prefixes = appendTo(d.Prefixes, orNilSqlizer(e, "prefix"))
+	})
 }
 
 // Options adds keyword options before the INTO clause of the query.
 func (b InsertBuilder) Options(options ...string) InsertBuilder {
-	return builder.Extend(b, "Options", options).(InsertBuilder)
+	return b.set(func(d *insertData) { d.Options = appendTo(d.Options, options...) })
 }
 
 // Into sets the INTO clause of the query.
 func (b InsertBuilder) Into(from string) InsertBuilder {
-	return builder.Set(b, "Into", from).(InsertBuilder)
+	return b.set(func(d *insertData) { d.Into = from })
+}
+
+// Partition restricts the insert to the given partitions of the table (MySQL).
+// Ex: Insert("t").Partition("p0").Values(1) -> "INSERT INTO t PARTITION (p0) VALUES (?)"
+func (b InsertBuilder) Partition(partitions ...string) InsertBuilder {
+	return b.set(func(d *insertData) { d.Partitions = appendTo(d.Partitions, partitions...) })
 }
 
 // Columns adds insert columns to the query.
 func (b InsertBuilder) Columns(columns ...string) InsertBuilder {
-	return builder.Extend(b, "Columns", columns).(InsertBuilder)
+	return b.set(func(d *insertData) { d.Columns = appendTo(d.Columns, columns...) })
 }
 
 // Values adds a single row's values to the query.
 func (b InsertBuilder) Values(values ...any) InsertBuilder {
-	return builder.Append(b, "Values", values).(InsertBuilder)
+	return b.set(func(d *insertData) { d.Values = appendTo(d.Values, values) })
 }
 
 // Suffix adds an expression to the end of the query
@@ -206,7 +334,9 @@ func (b InsertBuilder) Suffix(sql string, args ...any) InsertBuilder {
 
 // SuffixExpr adds an expression to the end of the query
 func (b InsertBuilder) SuffixExpr(e Sqlizer) InsertBuilder {
-	return builder.Append(b, "Suffixes", e).(InsertBuilder)
+	return b.set(func(d *insertData) {
+		d.Suffixes = appendTo(d.Suffixes, orNilSqlizer(e, "suffix"))
+	})
 }
 
 // SetMap set columns and values for insert builder from a map of column name and value
@@ -224,8 +354,10 @@ func (b InsertBuilder) SetMap(clauses map[string]any) InsertBuilder {
 		vals = append(vals, clauses[col])
 	}
 
-	b = builder.Set(b, "Columns", cols).(InsertBuilder)
-	b = builder.Set(b, "Values", [][]any{vals}).(InsertBuilder)
+	b = b.set(func(d *insertData) {
+		d.Columns = cols
+		d.Values = [][]any{vals}
+	})
 
 	return b
 }
@@ -233,9 +365,45 @@ func (b InsertBuilder) SetMap(clauses map[string]any) InsertBuilder {
 // Select set Select clause for insert query
 // If Values and Select are used, then Select has higher priority
 func (b InsertBuilder) Select(sb SelectBuilder) InsertBuilder {
-	return builder.Set(b, "Select", &sb).(InsertBuilder)
+	return b.set(func(d *insertData) { d.Select = &sb })
+}
+
+// Returning adds a RETURNING clause to the query.
+// It is rendered after all suffixes, so it can follow e.g. an ON CONFLICT clause
+// added with Suffix.
+func (b InsertBuilder) Returning(columns ...string) InsertBuilder {
+	return b.set(func(d *insertData) { d.Returning = appendTo(d.Returning, columns...) })
+}
+
+// OrReplace turns the statement into INSERT OR REPLACE (SQLite).
+func (b InsertBuilder) OrReplace() InsertBuilder {
+	return b.orAction("REPLACE")
+}
+
+// OrIgnore turns the statement into INSERT OR IGNORE (SQLite).
+func (b InsertBuilder) OrIgnore() InsertBuilder {
+	return b.orAction("IGNORE")
+}
+
+// OrAbort turns the statement into INSERT OR ABORT (SQLite).
+func (b InsertBuilder) OrAbort() InsertBuilder {
+	return b.orAction("ABORT")
+}
+
+// OrFail turns the statement into INSERT OR FAIL (SQLite).
+func (b InsertBuilder) OrFail() InsertBuilder {
+	return b.orAction("FAIL")
+}
+
+// OrRollback turns the statement into INSERT OR ROLLBACK (SQLite).
+func (b InsertBuilder) OrRollback() InsertBuilder {
+	return b.orAction("ROLLBACK")
+}
+
+func (b InsertBuilder) orAction(action string) InsertBuilder {
+	return b.set(func(d *insertData) { d.OrAction = action })
 }
 
 func (b InsertBuilder) statementKeyword(keyword string) InsertBuilder {
-	return builder.Set(b, "StatementKeyword", keyword).(InsertBuilder)
+	return b.set(func(d *insertData) { d.StatementKeyword = keyword })
 }
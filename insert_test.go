@@ -92,3 +92,60 @@ func TestInsertBuilderReplace(t *testing.T) {
 
 	assert.Equal(t, expectedSQL, sql)
 }
+
+func TestInsertBuilderReturning(t *testing.T) {
+	b := Insert("users").
+		Columns("name").
+		Values("John").
+		Suffix("ON CONFLICT DO NOTHING").
+		Returning("id", "created_at")
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSQL := "INSERT INTO users (name) VALUES (?) ON CONFLICT DO NOTHING RETURNING id, created_at"
+	assert.Equal(t, expectedSQL, sql)
+	assert.Equal(t, []any{"John"}, args)
+
+	_, _, err = b.Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderOrAction(t *testing.T) {
+	b := Insert("users").Columns("id", "name").Values(1, "John")
+
+	sql, _, err := b.OrReplace().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT OR REPLACE INTO users (id,name) VALUES (?,?)", sql)
+
+	sql, _, err = b.OrIgnore().Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT OR IGNORE INTO users (id,name) VALUES (?,?)", sql)
+
+	sql, _, err = b.OrAbort().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT OR ABORT INTO users (id,name) VALUES (?,?)", sql)
+
+	_, _, err = b.OrFail().Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Replace("users").Values(1).OrRollback().ToSql()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderPartition(t *testing.T) {
+	b := Insert("events").Partition("p2024_01").Columns("id", "kind").Values(1, "click")
+
+	sql, args, err := b.Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO events PARTITION (p2024_01) (id,kind) VALUES (?,?)", sql)
+	assert.Equal(t, []any{1, "click"}, args)
+
+	_, _, err = b.Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+}
+
+func TestInsertBuilderNilSqlizer(t *testing.T) {
+	_, _, err := StatementBuilder.RejectNilPredicates().Insert("t").Values(1).SuffixExpr(nil).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in suffix")
+}
@@ -2,9 +2,9 @@ package squirrel
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
-
-	"github.com/lann/builder"
 )
 
 type fromSelectLateralPart struct {
@@ -13,7 +13,7 @@ type fromSelectLateralPart struct {
 }
 
 func (p fromSelectLateralPart) ToSql() (string, []any, error) {
-	subSql, subArgs, err := p.sel.ToSql()
+	subSql, subArgs, err := nestedToSql(p.sel)
 	if err != nil {
 		return "", nil, err
 	}
@@ -23,8 +23,23 @@ func (p fromSelectLateralPart) ToSql() (string, []any, error) {
 }
 
 func (b SelectBuilder) FromSelectLateral(sel Sqlizer, alias string) SelectBuilder {
+	if err := checkLateral("FromSelectLateral", sel, alias); err != nil {
+		return b.withErr(err)
+	}
 	sel = forceQuestionPlaceholders(sel)
-	return builder.Set(b, "From", fromSelectLateralPart{sel: sel, alias: alias}).(SelectBuilder)
+	return b.set(func(d *selectData) { d.From = fromSelectLateralPart{sel: sel, alias: alias} })
+}
+
+// checkLateral returns an error if the subquery or the alias of a lateral
+// subquery is missing.
+func checkLateral(method string, sel Sqlizer, alias string) error {
+	if sel == nil {
+		return fmt.Errorf("%w in %s subquery", ErrNilSqlizer, method)
+	}
+	if strings.TrimSpace(alias) == "" {
+		return fmt.Errorf("%s: alias is required", method)
+	}
+	return nil
 }
 
 type joinLateralSelectPart struct {
@@ -35,7 +50,7 @@ type joinLateralSelectPart struct {
 }
 
 func (p joinLateralSelectPart) ToSql() (string, []any, error) {
-	subSql, subArgs, err := p.sel.ToSql()
+	subSql, subArgs, err := nestedToSql(p.sel)
 	if err != nil {
 		return "", nil, err
 	}
@@ -57,21 +72,30 @@ func (p joinLateralSelectPart) ToSql() (string, []any, error) {
 }
 
 func (b SelectBuilder) JoinLateralSelect(sel Sqlizer, alias string, on Sqlizer) SelectBuilder {
+	if err := checkLateral("JoinLateralSelect", sel, alias); err != nil {
+		return b.withErr(err)
+	}
 	sel = forceQuestionPlaceholders(sel)
 	part := joinLateralSelectPart{joinType: "JOIN", sel: sel, alias: alias, on: on}
-	return builder.Append(b, "Joins", part).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
 }
 
 func (b SelectBuilder) LeftJoinLateralSelect(sel Sqlizer, alias string, on Sqlizer) SelectBuilder {
+	if err := checkLateral("LeftJoinLateralSelect", sel, alias); err != nil {
+		return b.withErr(err)
+	}
 	sel = forceQuestionPlaceholders(sel)
 	part := joinLateralSelectPart{joinType: "LEFT JOIN", sel: sel, alias: alias, on: on}
-	return builder.Append(b, "Joins", part).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
 }
 
 func (b SelectBuilder) CrossJoinLateralSelect(sel Sqlizer, alias string) SelectBuilder {
+	if err := checkLateral("CrossJoinLateralSelect", sel, alias); err != nil {
+		return b.withErr(err)
+	}
 	sel = forceQuestionPlaceholders(sel)
 	part := joinLateralSelectPart{joinType: "CROSS JOIN", sel: sel, alias: alias, on: nil}
-	return builder.Append(b, "Joins", part).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
 }
 func forceQuestionPlaceholders(s Sqlizer) Sqlizer {
 	switch v := any(s).(type) {
@@ -83,3 +107,222 @@ func forceQuestionPlaceholders(s Sqlizer) Sqlizer {
 		return s
 	}
 }
+
+// tableFunction is a set-returning function used as a FROM or JOIN source
+type tableFunction struct {
+	fn         string
+	args       []any
+	ordinality bool
+	alias      string
+}
+
+// FromFunction allows to use a set-returning function, like unnest or
+// generate_series, as the source of the FROM clause (see SelectBuilder.FromTableFunction)
+// or of a join (see SelectBuilder.JoinTableFunction). Args are bound like in Expr;
+// slices are kept as a single array arg for unnest(?).
+// Ex:
+//
+//	Select("val", "ord").FromTableFunction(FromFunction("unnest(?)", ids).WithOrdinality().As("u(val, ord)"))
+//	// SELECT val, ord FROM unnest(?) WITH ORDINALITY AS u(val, ord)
+func FromFunction(fn string, args ...any) tableFunction {
+	return tableFunction{fn: fn, args: args}
+}
+
+// WithOrdinality adds a column numbering the rows returned by the function,
+// from 1 (PostgreSQL).
+func (f tableFunction) WithOrdinality() tableFunction {
+	f.ordinality = true
+	return f
+}
+
+// As sets the alias of the function, optionally followed by the column aliases:
+// "u" or "u(val, ord)".
+func (f tableFunction) As(alias string) tableFunction {
+	f.alias = alias
+	return f
+}
+
+func (f tableFunction) ToSql() (string, []any, error) {
+	if len(f.fn) == 0 {
+		return "", nil, fmt.Errorf("table function requires a function call")
+	}
+
+	sql, args, err := Expr(f.fn, f.args...).ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	if f.ordinality {
+		sql += " WITH ORDINALITY"
+	}
+	if len(f.alias) > 0 {
+		sql += " AS " + f.alias
+	}
+	return sql, args, nil
+}
+
+// name returns the table alias of the function, without the column aliases.
+func (f tableFunction) name() string {
+	if i := strings.Index(f.alias, "("); i >= 0 {
+		return strings.TrimSpace(f.alias[:i])
+	}
+	return f.alias
+}
+
+// FromTableFunction sets a set-returning function into the FROM clause of the query,
+// see FromFunction.
+func (b SelectBuilder) FromTableFunction(f tableFunction) SelectBuilder {
+	return b.set(func(d *selectData) { d.From = f })
+}
+
+type joinTableFunctionPart struct {
+	joinType string // "JOIN", "LEFT JOIN", "CROSS JOIN"
+	f        tableFunction
+	on       Sqlizer // nil for CROSS JOIN
+}
+
+func (p joinTableFunctionPart) ToSql() (string, []any, error) {
+	fSql, args, err := p.f.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql := p.joinType + " " + fSql
+	if p.on != nil {
+		onSql, onArgs, err := nestedToSql(p.on)
+		if err != nil {
+			return "", nil, err
+		}
+		sql += " ON " + onSql
+		args = append(args, onArgs...)
+	}
+	return sql, args, nil
+}
+
+// JoinTableFunction adds a JOIN clause with a set-returning function to the query,
+// see FromFunction.
+// Ex: Select("*").From("orders o").JoinTableFunction(FromFunction("unnest(?)", ids).As("t(id)"), Expr("t.id = o.id"))
+// -> "SELECT * FROM orders o JOIN unnest(?) AS t(id) ON t.id = o.id"
+func (b SelectBuilder) JoinTableFunction(f tableFunction, on Sqlizer) SelectBuilder {
+	part := joinTableFunctionPart{joinType: "JOIN", f: f, on: on}
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
+}
+
+// LeftJoinTableFunction adds a LEFT JOIN clause with a set-returning function to
+// the query, see FromFunction.
+func (b SelectBuilder) LeftJoinTableFunction(f tableFunction, on Sqlizer) SelectBuilder {
+	part := joinTableFunctionPart{joinType: "LEFT JOIN", f: f, on: on}
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
+}
+
+// CrossJoinTableFunction adds a CROSS JOIN clause with a set-returning function to
+// the query, see FromFunction.
+func (b SelectBuilder) CrossJoinTableFunction(f tableFunction) SelectBuilder {
+	part := joinTableFunctionPart{joinType: "CROSS JOIN", f: f}
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
+}
+
+// joinUnnestPart joins the values of an array arg to a column, see SelectBuilder.JoinUnnest
+type joinUnnestPart struct {
+	column string
+	values []any
+	alias  string
+}
+
+// unnestName returns the name of the column of the unnested values: the column
+// name without its table.
+func (p joinUnnestPart) unnestName() string {
+	if i := strings.LastIndex(p.column, "."); i >= 0 {
+		return p.column[i+1:]
+	}
+	return p.column
+}
+
+// arrayElementReplacer escapes the quoted elements of PostgreSQL array literals.
+var arrayElementReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// unnestArray returns the distinct values as a PostgreSQL array literal, e.g.
+// {1,2}, with the array type to cast it to. The literal is a string, so it can
+// be bound by any driver. The values must all be integers or all be strings.
+func unnestArray(values []any) (string, string, error) {
+	ints := make([]string, 0, len(values))
+	strs := make([]string, 0, len(values))
+	seen := make(map[any]bool, len(values))
+	for _, v := range values {
+		var key any
+		switch v := v.(type) {
+		case int, int8, int16, int32, int64, uint8, uint16, uint32:
+			n := reflect.ValueOf(v).Convert(reflect.TypeOf(int64(0))).Int()
+			if key = n; !seen[key] {
+				ints = append(ints, strconv.FormatInt(n, 10))
+			}
+		case string:
+			if key = v; !seen[key] {
+				strs = append(strs, `"`+arrayElementReplacer.Replace(v)+`"`)
+			}
+		default:
+			return "", "", fmt.Errorf("join unnest values must be integers or strings, not %T", v)
+		}
+		seen[key] = true
+	}
+	switch {
+	case len(strs) == 0:
+		return "{" + strings.Join(ints, ",") + "}", "bigint[]", nil
+	case len(ints) == 0:
+		return "{" + strings.Join(strs, ",") + "}", "text[]", nil
+	}
+	return "", "", fmt.Errorf("join unnest values must be all integers or all strings")
+}
+
+func (p joinUnnestPart) ToSql() (string, []any, error) {
+	if len(p.column) == 0 || len(p.alias) == 0 {
+		return "", nil, fmt.Errorf("join unnest requires a column and an alias")
+	}
+
+	array, arrayType, err := unnestArray(p.values)
+	if err != nil {
+		return "", nil, err
+	}
+	name := p.unnestName()
+	sql := fmt.Sprintf("JOIN unnest(?::%s) AS %s(%s) ON %s.%s = %s", arrayType, p.alias, name, p.alias, name, p.column)
+	return sql, []any{array}, nil
+}
+
+// JoinUnnest restricts the query to the rows where column equals one of values,
+// joining the values bound as a single array for PostgreSQL, which is faster than
+// long IN lists and keeps the statement the same whatever the number of values.
+// Other dialects use column IN (...) in the WHERE clause instead. The values
+// must all be integers or all be strings: they are deduplicated and bound as an
+// array literal cast to bigint[] or text[], so no driver specific array type is
+// needed. The values are available as alias.<column name>.
+// Ex: Select("*").From("users").JoinUnnest("users.id", ids, "t")
+// -> "SELECT * FROM users JOIN unnest(?::bigint[]) AS t(id) ON t.id = users.id"
+func (b SelectBuilder) JoinUnnest(column string, values []any, alias string) SelectBuilder {
+	part := joinUnnestPart{column: column, values: values, alias: alias}
+	return b.set(func(d *selectData) { d.Joins = appendTo[Sqlizer](d.Joins, part) })
+}
+
+// joinUnnestFallbackToSqlRaw replaces the JoinUnnest joins of the query with IN
+// conditions for dialects without arrays.
+func (d *selectData) joinUnnestFallbackToSqlRaw() (string, []any, error) {
+	fallback := *d
+	fallback.Joins = make([]Sqlizer, 0, len(d.Joins))
+	fallback.WhereParts = append([]Sqlizer{}, d.WhereParts...)
+	for _, join := range d.Joins {
+		if p, ok := join.(joinUnnestPart); ok {
+			fallback.WhereParts = append(fallback.WhereParts, Eq{p.column: p.values})
+			continue
+		}
+		fallback.Joins = append(fallback.Joins, join)
+	}
+	return fallback.toSqlRaw()
+}
+
+// hasJoinUnnest reports whether the query has JoinUnnest joins.
+func (d *selectData) hasJoinUnnest() bool {
+	for _, join := range d.Joins {
+		if _, ok := join.(joinUnnestPart); ok {
+			return true
+		}
+	}
+	return false
+}
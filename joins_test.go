@@ -143,3 +143,102 @@ func TestSelectBuilderRawLeftAndCrossJoinLateral(t *testing.T) {
 	assert.Equal(t, expectedSql, sql)
 	assert.Len(t, args, 0)
 }
+
+func TestSelectBuilderFromTableFunction(t *testing.T) {
+	ids := []int64{3, 1, 2}
+	sql, args, err := Select("val", "ord").
+		FromTableFunction(FromFunction("unnest(?)", ids).WithOrdinality().As("u(val, ord)")).
+		OrderBy("ord").PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT val, ord FROM unnest($1) WITH ORDINALITY AS u(val, ord) ORDER BY ord", sql)
+	assert.Equal(t, []any{ids}, args)
+
+	sql, args, err = Select("d").FromTableFunction(FromFunction("generate_series(?, ?, interval '1 day')", "2024-01-01", "2024-01-31").As("d")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT d FROM generate_series(?, ?, interval '1 day') AS d", sql)
+	assert.Equal(t, []any{"2024-01-01", "2024-01-31"}, args)
+}
+
+func TestSelectBuilderJoinTableFunction(t *testing.T) {
+	sql, args, err := Select("o.*").From("orders o").
+		JoinTableFunction(FromFunction("unnest(?)", []int{1, 2}).As("t(id)"), Expr("t.id = o.id")).
+		LeftJoinTableFunction(FromFunction("jsonb_array_elements(o.items)").As("item"), Expr("true")).
+		CrossJoinTableFunction(FromFunction("generate_series(1, ?)", 3).As("n")).
+		Where(Eq{"o.status": "open"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT o.* FROM orders o JOIN unnest(?) AS t(id) ON t.id = o.id "+
+		"LEFT JOIN jsonb_array_elements(o.items) AS item ON true "+
+		"CROSS JOIN generate_series(1, ?) AS n WHERE o.status = ?", sql)
+	assert.Equal(t, []any{[]int{1, 2}, 3, "open"}, args)
+}
+
+func TestSelectBuilderTableFunctionErr(t *testing.T) {
+	_, _, err := Select("*").FromTableFunction(FromFunction("unnest(?)", []int{1}).WithOrdinality().As("u")).
+		Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("t").JoinTableFunction(FromFunction("unnest(?)", []int{1}).As("t(id)"), Expr("t.id = 1")).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").FromTableFunction(FromFunction("")).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderJoinUnnest(t *testing.T) {
+	b := Select("*").From("users").JoinUnnest("users.id", []any{1, int64(2)}, "t").Where(Eq{"active": true})
+
+	sql, args, err := b.Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users JOIN unnest($1::bigint[]) AS t(id) ON t.id = users.id WHERE active = $2", sql)
+	assert.Equal(t, []any{"{1,2}", true}, args)
+
+	sql, args, err = b.Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE active = ? AND users.id IN (?,?)", sql)
+	assert.Equal(t, []any{true, 1, int64(2)}, args)
+
+	sql, args, err = Select("*").From("users").JoinUnnest("users.email", []any{"a@x.io", `b"\@x.io`}, "e").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users JOIN unnest(?::text[]) AS e(email) ON e.email = users.email", sql)
+	assert.Equal(t, []any{`{"a@x.io","b\"\\@x.io"}`}, args)
+
+	_, _, err = Select("*").From("users").JoinUnnest("id", []any{1}, "").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("users").JoinUnnest("users.id", []any{1, "2"}, "t").ToSql()
+	assert.Error(t, err)
+	_, _, err = Select("*").From("users").JoinUnnest("users.id", []any{1.5}, "t").ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderJoinUnnestDuplicates(t *testing.T) {
+	sql, args, err := Select("*").From("users").
+		JoinUnnest("users.id", []any{3, int32(1), 3, int64(1), uint8(2)}, "t").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users JOIN unnest(?::bigint[]) AS t(id) ON t.id = users.id", sql)
+	assert.Equal(t, []any{"{3,1,2}"}, args)
+
+	_, args, err = Select("*").From("users").JoinUnnest("users.email", []any{"a", "a", "b"}, "e").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{`{"a","b"}`}, args)
+
+	_, args, err = Select("*").From("users").JoinUnnest("users.id", nil, "t").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"{}"}, args)
+}
+
+func TestSelectBuilderLateralErr(t *testing.T) {
+	sub := Select("x").From("t")
+
+	_, _, err := Select("*").FromSelectLateral(sub, "").ToSql()
+	assert.EqualError(t, err, "FromSelectLateral: alias is required")
+
+	_, _, err = Select("*").From("a").JoinLateralSelect(nil, "s", Expr("true")).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in JoinLateralSelect subquery")
+
+	// the first error is kept
+	_, _, err = Select("*").From("a").
+		LeftJoinLateralSelect(sub, " ", Expr("true")).
+		CrossJoinLateralSelect(nil, "s").ToSql()
+	assert.EqualError(t, err, "LeftJoinLateralSelect: alias is required")
+}
@@ -0,0 +1,144 @@
+package squirrel
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keysetPage holds the cursor of PaginateByKeyset: the ORDER BY values of the
+// last row of the previous page, none for the first page.
+type keysetPage struct {
+	cursor []any
+	err    error // error decoding the cursor of PaginateByCursor
+}
+
+// keysetKey is an ORDER BY column of a query paginated by keyset.
+type keysetKey struct {
+	column string
+	desc   bool
+}
+
+// keysetOrderByRegexp matches the ORDER BY terms keyset pagination supports: a
+// column with an optional direction.
+var keysetOrderByRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)(?:\s+(?i:(ASC|DESC)))?$`)
+
+// PaginateByKeyset adds a LIMIT and a condition selecting the rows after cursor,
+// the ORDER BY values of the last row of the previous page (none for the first
+// page). Unlike PaginateByID, any number of ORDER BY columns is supported, in
+// any mix of directions. The ORDER BY terms must be plain columns, to be unique
+// together, and to match the cursor values one for one: ToSql returns an error
+// otherwise.
+// Ex:
+//
+//	Select("*").From("posts").OrderBy("created_at DESC", "id").PaginateByKeyset(20, lastCreatedAt, lastID)
+//	// SELECT * FROM posts WHERE (created_at < ? OR (created_at = ? AND id > ?))
+//	// ORDER BY created_at DESC, id LIMIT 20
+func (b SelectBuilder) PaginateByKeyset(limit uint64, cursor ...any) SelectBuilder {
+	b = b.Limit(limit)
+	// copied so that the caller can reuse the slice
+	cursor = append([]any(nil), cursor...)
+	return b.set(func(d *selectData) { d.Keyset = &keysetPage{cursor: cursor} })
+}
+
+// keysetKeys returns the ORDER BY columns of the query.
+func (d *selectData) keysetKeys() ([]keysetKey, error) {
+	if len(d.OrderByParts) == 0 {
+		return nil, fmt.Errorf("keyset pagination requires an ORDER BY clause")
+	}
+
+	keys := make([]keysetKey, 0, len(d.OrderByParts))
+	for _, p := range orderByWithDialect(d.OrderByParts, d.Dialect) {
+		sql, args, err := nestedToSql(p)
+		if err != nil {
+			return nil, err
+		}
+		match := keysetOrderByRegexp.FindStringSubmatch(strings.TrimSpace(sql))
+		if match == nil || len(args) > 0 {
+			return nil, fmt.Errorf("keyset pagination requires ORDER BY columns with an optional ASC or DESC, not %q", sql)
+		}
+
+		for _, key := range keys {
+			if key.column == match[1] {
+				return nil, fmt.Errorf("keyset pagination ORDER BY column %s is repeated", key.column)
+			}
+		}
+		keys = append(keys, keysetKey{column: match[1], desc: strings.EqualFold(match[2], "DESC")})
+	}
+	return keys, nil
+}
+
+// keysetPredicate returns the condition selecting the rows after the keyset
+// cursor, nil for the first page.
+func (d *selectData) keysetPredicate() (Sqlizer, error) {
+	if d.Keyset.err != nil {
+		return nil, d.Keyset.err
+	}
+	if d.Paginator.pType != PaginatorTypeUndefined {
+		return nil, fmt.Errorf("keyset pagination can't be combined with a Paginator")
+	}
+
+	keys, err := d.keysetKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := d.Keyset.cursor
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+	if len(cursor) != len(keys) {
+		return nil, fmt.Errorf("keyset cursor has %d values, but ORDER BY has %d columns", len(cursor), len(keys))
+	}
+	for i, value := range cursor {
+		if value == nil {
+			return nil, fmt.Errorf("keyset cursor value %d (%s) is nil", i+1, keys[i].column)
+		}
+	}
+
+	after := func(key keysetKey) string {
+		if key.desc {
+			return key.column + " < ?"
+		}
+		return key.column + " > ?"
+	}
+
+	if len(keys) == 1 {
+		return Expr(after(keys[0]), cursor[0]), nil
+	}
+
+	sameDirection := true
+	for _, key := range keys[1:] {
+		sameDirection = sameDirection && key.desc == keys[0].desc
+	}
+	if sameDirection && d.Dialect.supportsRowValueComparison() {
+		columns := make([]string, len(keys))
+		for i, key := range keys {
+			columns[i] = key.column
+		}
+		operator := ">"
+		if keys[0].desc {
+			operator = "<"
+		}
+		return Expr(fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), operator, Placeholders(len(keys))), cursor...), nil
+	}
+
+	// (k1 > ? OR (k1 = ? AND k2 > ?) OR (k1 = ? AND k2 = ? AND k3 > ?) ...)
+	terms := make([]string, len(keys))
+	var args []any
+	for i, key := range keys {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, keys[j].column+" = ?")
+			args = append(args, cursor[j])
+		}
+		conds = append(conds, after(key))
+		args = append(args, cursor[i])
+
+		terms[i] = strings.Join(conds, " AND ")
+		if i > 0 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+	return Expr("("+strings.Join(terms, " OR ")+")", args...), nil
+}
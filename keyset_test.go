@@ -0,0 +1,74 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginateByKeyset(t *testing.T) {
+	sql, args, err := Select("*").From("posts").
+		Where(Eq{"author_id": 7}).
+		OrderBy("created_at DESC", "id").
+		PaginateByKeyset(20, "2024-01-01", 42).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts WHERE author_id = $1 AND (created_at < $2 OR (created_at = $3 AND id > $4)) "+
+		"ORDER BY created_at DESC, id LIMIT 20", sql)
+	assert.Equal(t, []any{7, "2024-01-01", "2024-01-01", 42}, args)
+
+	sql, args, err = Select("*").From("posts").
+		OrderByClause(Order("created_at").Desc()).OrderBy("id DESC").
+		PaginateByKeyset(20, "2024-01-01", 42).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts WHERE (created_at, id) < (?,?) ORDER BY created_at DESC, id DESC LIMIT 20", sql)
+	assert.Equal(t, []any{"2024-01-01", 42}, args)
+
+	sql, _, err = Select("*").From("posts").OrderBy("id").PaginateByKeyset(20).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts ORDER BY id LIMIT 20", sql)
+
+	sql, args, err = Select("*").From("posts").OrderBy("id").PaginateByKeyset(20, 42).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts WHERE id > ? ORDER BY id LIMIT 20", sql)
+	assert.Equal(t, []any{42}, args)
+}
+
+func TestPaginateByKeysetThreeKeys(t *testing.T) {
+	sql, args, err := Select("*").From("scores").
+		OrderBy("score DESC", "name", "id DESC").
+		PaginateByKeyset(10, 90, "bob", 3).
+		Dialect(DialectMSSQL).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM scores WHERE (score < @p1 OR (score = @p2 AND name > @p3) OR (score = @p4 AND name = @p5 AND id < @p6)) "+
+		"ORDER BY score DESC, name, id DESC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+	assert.Equal(t, []any{90, 90, "bob", 90, "bob", 3}, args)
+
+	sql, _, err = Select("*").From("scores").
+		OrderBy("score", "id").
+		PaginateByKeyset(10, 90, 3).
+		Dialect(DialectOracle).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "WHERE (score > :1 OR (score = :2 AND id > :3))")
+}
+
+func TestPaginateByKeysetErr(t *testing.T) {
+	tests := []SelectBuilder{
+		Select("*").From("posts").PaginateByKeyset(20, 42),
+		Select("*").From("posts").OrderBy("id").PaginateByKeyset(20, "2024-01-01", 42),
+		Select("*").From("posts").OrderBy("created_at DESC", "id").PaginateByKeyset(20, 42),
+		Select("*").From("posts").OrderBy("lower(name)").PaginateByKeyset(20, "bob"),
+		Select("*").From("posts").OrderByClause(Order("name").NullsLast()).PaginateByKeyset(20, "bob"),
+		Select("*").From("posts").OrderBy("id", "id DESC").PaginateByKeyset(20, 1, 2),
+		Select("*").From("posts").OrderBy("id").PaginateByKeyset(20, nil),
+		Select("*").From("posts").OrderBy("id").PaginateByKeyset(20, 42).Paginate(PaginatorByPage(20, 2)),
+	}
+	for _, b := range tests {
+		_, _, err := b.ToSql()
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,75 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the % and _ wildcards and the \ escape character of s, so
+// that s matches itself in a LIKE pattern using ESCAPE '\'.
+// Ex: EscapeLike("50%_off") -> `50\%\_off`
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// likePatternExpr helps to match user input in a LIKE pattern
+type likePatternExpr struct {
+	column  string
+	prefix  string
+	value   string
+	suffix  string
+	dialect Dialect
+}
+
+// Contains allows to match the rows where column contains value, with the
+// wildcards of value escaped, so they match themselves. The ESCAPE clause is
+// rendered per dialect: MySQL and Snowflake need the backslash to be escaped in
+// string literals, BigQuery and ClickHouse don't support ESCAPE.
+// Ex: Contains("name", "50%") -> "name LIKE ? ESCAPE '\'" with arg `%50\%%`
+func Contains(column, value string) likePatternExpr {
+	return likePatternExpr{column: column, prefix: "%", value: value, suffix: "%"}
+}
+
+// StartsWith allows to match the rows where column starts with value, see Contains.
+// Ex: StartsWith("name", "a_b") -> "name LIKE ? ESCAPE '\'" with arg `a\_b%`
+func StartsWith(column, value string) likePatternExpr {
+	return likePatternExpr{column: column, value: value, suffix: "%"}
+}
+
+// EndsWith allows to match the rows where column ends with value, see Contains.
+// Ex: EndsWith("email", "@example.com") -> "email LIKE ? ESCAPE '\'" with arg `%@example.com`
+func EndsWith(column, value string) likePatternExpr {
+	return likePatternExpr{column: column, prefix: "%", value: value}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e likePatternExpr) Dialect(d Dialect) likePatternExpr {
+	e.dialect = d
+	return e
+}
+
+func (e likePatternExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 {
+		return "", nil, fmt.Errorf("like pattern requires a column")
+	}
+
+	value := EscapeLike(e.value)
+	if e.dialect == DialectMSSQL {
+		// [ starts a character class
+		value = strings.ReplaceAll(value, "[", `\[`)
+	}
+	pattern := e.prefix + value + e.suffix
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectBigQuery, DialectClickHouse:
+		// backslash is the escape character, ESCAPE is not supported
+		return fmt.Sprintf("%s LIKE ?", e.column), []any{pattern}, nil
+	case DialectMySQL, DialectMariaDB, DialectSnowflake:
+		// backslashes are escape characters in string literals
+		return fmt.Sprintf(`%s LIKE ? ESCAPE '\\'`, e.column), []any{pattern}, nil
+	}
+	return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, e.column), []any{pattern}, nil
+}
@@ -0,0 +1,40 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `50\%\_off \\ more`, EscapeLike(`50%_off \ more`))
+}
+
+func TestLikePatterns(t *testing.T) {
+	sql, args, err := Contains("name", "50%").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `name LIKE ? ESCAPE '\'`, sql)
+	assert.Equal(t, []any{`%50\%%`}, args)
+
+	sql, args, err = StartsWith("name", "a_b").Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `name LIKE ? ESCAPE '\\'`, sql)
+	assert.Equal(t, []any{`a\_b%`}, args)
+
+	sql, args, err = EndsWith("email", "@example.com").Dialect(DialectBigQuery).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `email LIKE ?`, sql)
+	assert.Equal(t, []any{`%@example.com`}, args)
+
+	_, args, err = Contains("name", "[a]").Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{`%\[a]%`}, args)
+
+	sql, args, err = Select("*").From("users").Where(Contains("name", "o'b")).PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM users WHERE name LIKE $1 ESCAPE '\'`, sql)
+	assert.Equal(t, []any{`%o'b%`}, args)
+
+	_, _, err = Contains("", "x").ToSql()
+	assert.Error(t, err)
+}
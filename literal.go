@@ -0,0 +1,242 @@
+package squirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// valueToSql renders v as a nested Sqlizer, or as a placeholder bound to v.
+func valueToSql(v any) (string, []any, error) {
+	if s, ok := v.(Sqlizer); ok {
+		return nestedToSql(s)
+	}
+	return "?", []any{v}, nil
+}
+
+// valuesToSql renders every value with valueToSql and returns the SQL parts.
+func valuesToSql(values []any) (parts []string, args []any, err error) {
+	parts = make([]string, 0, len(values))
+	for _, v := range values {
+		vSql, vArgs, err := valueToSql(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, vSql)
+		args = append(args, vArgs...)
+	}
+	return parts, args, nil
+}
+
+// arrayExpr helps to use array literals in SQL query
+type arrayExpr struct {
+	values  []any
+	dialect Dialect
+}
+
+// Array allows to use array literals in SQL query. Values can be Sqlizers or bound values.
+// Ex: Array(1, 2) -> "ARRAY[?,?]", Array(1, 2).Dialect(DialectBigQuery) -> "[?,?]"
+func Array(values ...any) arrayExpr {
+	return arrayExpr{values: values}
+}
+
+// Dialect sets the dialect used to render the array literal.
+func (e arrayExpr) Dialect(d Dialect) arrayExpr {
+	e.dialect = d
+	return e
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (e arrayExpr) ToSql() (sql string, args []any, err error) {
+	parts, args, err := valuesToSql(e.values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectBigQuery, DialectClickHouse:
+		sql = fmt.Sprintf("[%s]", strings.Join(parts, ","))
+	case DialectMySQL, DialectMariaDB, DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		return "", nil, e.dialect.unsupportedError("array literal")
+	default:
+		sql = fmt.Sprintf("ARRAY[%s]", strings.Join(parts, ","))
+	}
+	return sql, args, nil
+}
+
+// StructField is a named field of a struct literal. See Struct.
+type StructField struct {
+	Name  string
+	Value any
+}
+
+// structExpr helps to use struct literals in SQL query
+type structExpr struct {
+	fields  []StructField
+	dialect Dialect
+}
+
+// Struct allows to use struct literals in SQL query. Values can be Sqlizers or bound values.
+// Field names can be empty.
+// Ex: Struct(StructField{"id", 1}, StructField{"name", "John"}) -> "STRUCT(? AS id, ? AS name)"
+//
+// PostgreSQL renders a ROW constructor, field names are ignored there.
+func Struct(fields ...StructField) structExpr {
+	return structExpr{fields: fields}
+}
+
+// Dialect sets the dialect used to render the struct literal.
+func (e structExpr) Dialect(d Dialect) structExpr {
+	e.dialect = d
+	return e
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (e structExpr) ToSql() (sql string, args []any, err error) {
+	if len(e.fields) == 0 {
+		return "", nil, fmt.Errorf("struct literal must have at least one field")
+	}
+
+	values := make([]any, len(e.fields))
+	for i, f := range e.fields {
+		values[i] = f.Value
+	}
+
+	parts, args, err := valuesToSql(values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectPostgres:
+		return fmt.Sprintf("ROW(%s)", strings.Join(parts, ", ")), args, nil
+	case DialectMySQL, DialectMariaDB, DialectSQLite, DialectSQLiteUpdateDeleteLimit, DialectClickHouse:
+		return "", nil, e.dialect.unsupportedError("struct literal")
+	}
+
+	for i, f := range e.fields {
+		if f.Name != "" {
+			parts[i] = fmt.Sprintf("%s AS %s", parts[i], f.Name)
+		}
+	}
+	return fmt.Sprintf("STRUCT(%s)", strings.Join(parts, ", ")), args, nil
+}
+
+// literalExpr helps to inline a value as a SQL literal
+type literalExpr struct {
+	value   any
+	dialect Dialect
+}
+
+// Literal inlines v in the query as a literal of the dialect instead of binding it.
+// Strings are escaped, but prefer bound args for user input.
+// Ex: Literal(true) -> "TRUE", Literal(true).Dialect(DialectMSSQL) -> "1"
+func Literal(v any) literalExpr {
+	return literalExpr{value: v}
+}
+
+// Dialect sets the dialect used to render the literal.
+func (e literalExpr) Dialect(d Dialect) literalExpr {
+	e.dialect = d
+	return e
+}
+
+func (e literalExpr) ToSql() (string, []any, error) {
+	return e.dialect.Literal(e.value), nil, nil
+}
+
+var (
+	// True is the TRUE literal ("1" for dialects without boolean literals).
+	// Ex: Expr("active = ?", True) -> "active = TRUE", Expr("active = ?", True.Dialect(DialectOracle)) -> "active = 1"
+	True = Literal(true)
+	// False is the FALSE literal ("0" for dialects without boolean literals).
+	False = Literal(false)
+	// Null is the NULL literal.
+	Null = Literal(nil)
+)
+
+// Literal renders v as a SQL literal of the dialect.
+//
+// nil is rendered as NULL, booleans as TRUE / FALSE (1 / 0 for Oracle and MSSQL),
+// numbers as is, strings and other values as escaped string literals, []byte
+// as binary literals and time.Time as timestamp literals. Values of types
+// registered with RegisterArgBinder are rendered by their ArgLiteral, and
+// driver.Valuer values by their Value.
+func (d Dialect) Literal(v any) string {
+	if a, ok := v.(boundArg); ok {
+		return a.binder.ArgLiteral(a.v, d)
+	}
+	if b, ok := argBinderOf(v); ok {
+		return b.ArgLiteral(v, d)
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("[Value error: %s]", err)
+		}
+		v = value
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if d.hasBooleanLiterals() {
+			if v {
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+		if v {
+			return "1"
+		}
+		return "0"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case string:
+		return d.stringLiteral(v)
+	case []byte:
+		switch d { //nolint:exhaustive
+		case DialectPostgres:
+			return fmt.Sprintf("'\\x%x'::bytea", v)
+		case DialectMSSQL:
+			return fmt.Sprintf("0x%x", v)
+		case DialectBigQuery:
+			return fmt.Sprintf("FROM_HEX('%x')", v)
+		case DialectOracle, DialectOracleLegacy:
+			return fmt.Sprintf("HEXTORAW('%x')", v)
+		}
+		return fmt.Sprintf("X'%x'", v)
+	case time.Time:
+		return d.timeLiteral(v)
+	}
+	return d.stringLiteral(fmt.Sprint(v))
+}
+
+func (d Dialect) stringLiteral(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if d.isMySQL() || d == DialectClickHouse || d == DialectBigQuery {
+		// backslash is an escape character in string literals of these engines
+		s = strings.ReplaceAll(s, "\\", "\\\\")
+	}
+	if d == DialectMSSQL {
+		return "N'" + s + "'"
+	}
+	return "'" + s + "'"
+}
+
+func (d Dialect) timeLiteral(t time.Time) string {
+	switch d { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB, DialectClickHouse:
+		// no time zone offset in DATETIME literals
+		return "'" + t.Format("2006-01-02 15:04:05.999999") + "'"
+	case DialectMSSQL:
+		return "'" + t.Format("2006-01-02T15:04:05.9999999-07:00") + "'"
+	case DialectBigQuery:
+		return "TIMESTAMP '" + t.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+	case DialectOracle, DialectOracleLegacy:
+		return "TIMESTAMP '" + t.Format("2006-01-02 15:04:05.999999999 -07:00") + "'"
+	}
+	return "'" + t.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+}
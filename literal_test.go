@@ -0,0 +1,95 @@
+package squirrel
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArray(t *testing.T) {
+	sql, args, err := Array(1, Expr("? + 1", 2)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "ARRAY[?,? + 1]", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	sql, _, err = Array(1, 2).Dialect(DialectBigQuery).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "[?,?]", sql)
+
+	_, _, err = Array(1).Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+}
+
+func TestStruct(t *testing.T) {
+	s := Struct(StructField{"id", 1}, StructField{"name", "John"}, StructField{Value: Expr("CURRENT_DATE()")})
+
+	sql, args, err := s.Dialect(DialectBigQuery).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "STRUCT(? AS id, ? AS name, CURRENT_DATE())", sql)
+	assert.Equal(t, []any{1, "John"}, args)
+
+	sql, _, err = s.Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "ROW(?, ?, CURRENT_DATE())", sql)
+
+	_, _, err = Struct().ToSql()
+	assert.Error(t, err)
+}
+
+func TestBigQuerySelect(t *testing.T) {
+	sql, args, err := StatementBuilder.Dialect(DialectBigQuery).
+		Select("id").
+		From(DialectBigQuery.QuoteIdent("my-project.dataset.users")).
+		Where(Eq{"id": 1}).
+		Where(Expr("tag IN UNNEST(?)", Array("a", "b").Dialect(DialectBigQuery))).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM `my-project.dataset.users` WHERE id = @p1 AND tag IN UNNEST([@p2,@p3])", sql)
+	assert.Equal(t, []any{1, "a", "b"}, args)
+}
+
+func TestLiteral(t *testing.T) {
+	sql, args, err := Select("*").From("users").Where(Expr("active = ?", True)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE active = TRUE", sql)
+	assert.Empty(t, args)
+
+	sql, _, err = Expr("active = ?", True.Dialect(DialectMSSQL)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "active = 1", sql)
+
+	sql, _, err = Expr("deleted = ?, note = ?", False.Dialect(DialectOracle), Null).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted = 0, note = NULL", sql)
+
+	sql, _, _ = Literal("O'Reilly").ToSql()
+	assert.Equal(t, "'O''Reilly'", sql)
+}
+
+func TestDialectLiteral(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 500000000, time.UTC)
+
+	tests := []struct {
+		dialect  Dialect
+		value    any
+		expected string
+	}{
+		{DialectDefault, 12, "12"},
+		{DialectPostgres, true, "TRUE"},
+		{DialectOracle, false, "0"},
+		{DialectMySQL, `a\'b`, `'a\\''b'`},
+		{DialectMSSQL, "x", "N'x'"},
+		{DialectPostgres, []byte{1, 171}, `'\x01ab'::bytea`},
+		{DialectSQLite, []byte{1, 171}, "X'01ab'"},
+		{DialectPostgres, ts, "'2024-05-06 07:08:09.5+00:00'"},
+		{DialectMySQL, ts, "'2024-05-06 07:08:09.5'"},
+		{DialectOracle, ts, "TIMESTAMP '2024-05-06 07:08:09.5 +00:00'"},
+		{DialectBigQuery, ts, "TIMESTAMP '2024-05-06 07:08:09.5+00:00'"},
+		{DialectDefault, sql.NullString{}, "NULL"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.dialect.Literal(test.value), "%s %v", test.dialect, test.value)
+	}
+}
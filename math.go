@@ -0,0 +1,70 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mathExpr helps to use math functions in SQL query
+type mathExpr struct {
+	function string
+	args     []any
+	dialect  Dialect
+}
+
+// Round allows to round expr to n decimal digits. expr, like the args of the
+// other math functions, is SQL if it is a string (e.g. a column), is nested if
+// it is a Sqlizer, and is bound to a placeholder otherwise.
+// Ex: Round(Expr("price * ?", rate), 2) -> "ROUND(price * ?, 2)"
+func Round(expr any, n int) mathExpr {
+	return mathExpr{function: "ROUND", args: []any{expr, Expr(fmt.Sprintf("%d", n))}}
+}
+
+// Ceil allows to round expr up to an integer, with CEILING on SQL Server.
+// Ex: Ceil(Expr("amount / ?", 10)) -> "CEIL(amount / ?)"
+func Ceil(expr any) mathExpr {
+	return mathExpr{function: "CEIL", args: []any{expr}}
+}
+
+// Floor allows to round expr down to an integer.
+// Ex: Floor("rating") -> "FLOOR(rating)"
+func Floor(expr any) mathExpr {
+	return mathExpr{function: "FLOOR", args: []any{expr}}
+}
+
+// Abs allows to use the absolute value of expr.
+// Ex: Abs(Expr("balance - ?", 100)) -> "ABS(balance - ?)"
+func Abs(expr any) mathExpr {
+	return mathExpr{function: "ABS", args: []any{expr}}
+}
+
+// Power allows to raise base to the power of exponent.
+// Ex: Power("growth", 2) -> "POWER(growth, ?)"
+func Power(base, exponent any) mathExpr {
+	return mathExpr{function: "POWER", args: []any{base, exponent}}
+}
+
+// Dialect sets the dialect used to render the function.
+func (e mathExpr) Dialect(d Dialect) mathExpr {
+	e.dialect = d
+	return e
+}
+
+func (e mathExpr) ToSql() (sql string, args []any, err error) {
+	function := e.function
+	if function == "CEIL" && e.dialect == DialectMSSQL {
+		function = "CEILING"
+	}
+
+	operands := make([]string, 0, len(e.args))
+	for _, arg := range e.args {
+		argSql, argArgs, err := columnOperandToSql(arg)
+		if err != nil {
+			return "", nil, err
+		}
+		operands = append(operands, argSql)
+		args = append(args, argArgs...)
+	}
+
+	return fmt.Sprintf("%s(%s)", function, strings.Join(operands, ", ")), args, nil
+}
@@ -0,0 +1,37 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMathFunctions(t *testing.T) {
+	sql, args, err := Select("id").
+		Column(Alias(Round(Expr("price * ?", 1.2), 2), "gross")).
+		From("products").
+		Where(Expr("rating > ?", Floor(Expr("?", 3.7)))).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id, (ROUND(price * $1, 2)) AS gross FROM products WHERE rating > FLOOR($2)", sql)
+	assert.Equal(t, []any{1.2, 3.7}, args)
+
+	sql, args, err = Abs(Expr("balance - ?", 100)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "ABS(balance - ?)", sql)
+	assert.Equal(t, []any{100}, args)
+
+	sql, args, err = Power("growth", 2).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "POWER(growth, ?)", sql)
+	assert.Equal(t, []any{2}, args)
+
+	sql, _, err = Ceil("amount").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CEIL(amount)", sql)
+
+	sql, _, err = Ceil("amount").Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CEILING(amount)", sql)
+}
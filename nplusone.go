@@ -0,0 +1,85 @@
+package squirrel
+
+import (
+	"sync"
+)
+
+// TestReporter is the part of testing.TB used by NPlusOneDetector.Check.
+type TestReporter interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// NPlusOneDetector flags the queries executed more than a given number of times
+// in a scope, e.g. a test of an endpoint, which usually means a query is run per
+// row instead of once for all the rows. Queries are grouped by Fingerprint.
+// Ex:
+//
+//	d := NewNPlusOneDetector(3)
+//	// record the queries of the tested code with d.Record(sql, args)
+//	d.Check(t) // fails the test for the queries recorded more than 3 times
+type NPlusOneDetector struct {
+	max       int
+	collector *QueryStatsCollector
+	mu        sync.Mutex
+	examples  map[string]string
+}
+
+// NewNPlusOneDetector returns a detector flagging the queries recorded more than max times.
+func NewNPlusOneDetector(max int) *NPlusOneDetector {
+	return &NPlusOneDetector{max: max, collector: NewQueryStatsCollector(), examples: map[string]string{}}
+}
+
+// Record records an executed query.
+func (d *NPlusOneDetector) Record(sql string, args []any) {
+	d.collector.Record(sql, args)
+
+	fingerprint := Fingerprint(sql)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.examples[fingerprint]; !ok {
+		d.examples[fingerprint] = sql
+	}
+}
+
+// Observe builds s and records the query, returning the ToSql error if any.
+func (d *NPlusOneDetector) Observe(s Sqlizer) error {
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return err
+	}
+	d.Record(sql, args)
+	return nil
+}
+
+// Violations returns the statistics of the queries recorded more than max times.
+func (d *NPlusOneDetector) Violations() []QueryStats {
+	var violations []QueryStats
+	for _, s := range d.collector.Stats() {
+		if s.Count > int64(d.max) {
+			violations = append(violations, s)
+		}
+	}
+	return violations
+}
+
+// Check reports an error to t for every query recorded more than max times,
+// with the SQL of its first execution.
+func (d *NPlusOneDetector) Check(t TestReporter) {
+	t.Helper()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range d.Violations() {
+		t.Errorf("query executed %d times, more than %d: %s", s.Count, d.max, d.examples[s.Fingerprint])
+	}
+}
+
+// Reset forgets the recorded queries, e.g. to start a new scope.
+func (d *NPlusOneDetector) Reset() {
+	d.collector.Flush()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.examples = map[string]string{}
+}
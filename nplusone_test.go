@@ -0,0 +1,36 @@
+package squirrel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReporter struct {
+	errors []string
+}
+
+func (r *fakeReporter) Helper() {}
+
+func (r *fakeReporter) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNPlusOneDetector(t *testing.T) {
+	d := NewNPlusOneDetector(2)
+	assert.NoError(t, d.Observe(Select("*").From("orders").Where(Eq{"user_id": []int{1, 2, 3}})))
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, d.Observe(Select("*").From("items").Where(Eq{"order_id": i}).PlaceholderFormat(Dollar)))
+	}
+
+	r := &fakeReporter{}
+	d.Check(r)
+	assert.Equal(t, []string{"query executed 3 times, more than 2: SELECT * FROM items WHERE order_id = $1"}, r.errors)
+	assert.Len(t, d.Violations(), 1)
+
+	d.Reset()
+	r = &fakeReporter{}
+	d.Check(r)
+	assert.Empty(t, r.errors)
+}
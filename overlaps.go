@@ -0,0 +1,93 @@
+package squirrel
+
+import (
+	"fmt"
+)
+
+// overlapsExpr helps to test whether two time periods overlap
+type overlapsExpr struct {
+	startColumn string
+	endColumn   string
+	start       any
+	end         any
+	dialect     Dialect
+}
+
+// Overlaps allows to use the OVERLAPS operator: whether the period from startA to
+// endA (columns) and the period from startB to endB (values, or Sqlizers) overlap,
+// e.g. to find conflicting bookings. Periods are half-open: a period ending when
+// the other starts doesn't overlap it. Dialects without OVERLAPS use the equivalent
+// startA < endB AND endA > startB comparison.
+// Ex: Overlaps("starts_at", "ends_at", from, to) -> "(starts_at, ends_at) OVERLAPS (?, ?)"
+func Overlaps(startA, endA string, startB, endB any) overlapsExpr {
+	return overlapsExpr{startColumn: startA, endColumn: endA, start: startB, end: endB}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e overlapsExpr) Dialect(d Dialect) overlapsExpr {
+	e.dialect = d
+	return e
+}
+
+func (e overlapsExpr) ToSql() (string, []any, error) {
+	if len(e.startColumn) == 0 || len(e.endColumn) == 0 {
+		return "", nil, fmt.Errorf("overlaps requires a start and an end column")
+	}
+
+	startSql, startArgs, err := operandToSql(e.start)
+	if err != nil {
+		return "", nil, err
+	}
+	endSql, endArgs, err := operandToSql(e.end)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectOracle, DialectOracleLegacy, DialectDuckDB:
+		sql := fmt.Sprintf("(%s, %s) OVERLAPS (%s, %s)", e.startColumn, e.endColumn, startSql, endSql)
+		return sql, append(append([]any{}, startArgs...), endArgs...), nil
+	}
+	sql := fmt.Sprintf("%s < %s AND %s > %s", e.startColumn, endSql, e.endColumn, startSql)
+	return sql, append(append([]any{}, endArgs...), startArgs...), nil
+}
+
+// rangeOpExpr helps to use the operators of PostgreSQL range types
+type rangeOpExpr struct {
+	column   string
+	operator string
+	value    any
+}
+
+// RangeContains allows to test whether the range (or multirange) column contains
+// value, an element or a range (PostgreSQL @> operator).
+// Ex: RangeContains("during", time.Now()) -> "during @> ?"
+func RangeContains(column string, value any) rangeOpExpr {
+	return rangeOpExpr{column: column, operator: "@>", value: value}
+}
+
+// RangeContainedBy allows to test whether the range column is contained by the
+// range value (PostgreSQL <@ operator).
+// Ex: RangeContainedBy("during", Expr("tstzrange(?, ?)", from, to)) -> "during <@ tstzrange(?, ?)"
+func RangeContainedBy(column string, value any) rangeOpExpr {
+	return rangeOpExpr{column: column, operator: "<@", value: value}
+}
+
+// RangeOverlaps allows to test whether the range column and the range value have
+// points in common (PostgreSQL && operator).
+// Ex: RangeOverlaps("during", Expr("tstzrange(?, ?)", from, to)) -> "during && tstzrange(?, ?)"
+func RangeOverlaps(column string, value any) rangeOpExpr {
+	return rangeOpExpr{column: column, operator: "&&", value: value}
+}
+
+func (e rangeOpExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 {
+		return "", nil, fmt.Errorf("range operator %s requires a column", e.operator)
+	}
+
+	sql, args, err := operandToSql(e.value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s %s %s", e.column, e.operator, sql), args, nil
+}
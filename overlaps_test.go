@@ -0,0 +1,50 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlaps(t *testing.T) {
+	sql, args, err := Overlaps("starts_at", "ends_at", "2024-01-01", "2024-01-07").Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(starts_at, ends_at) OVERLAPS (?, ?)", sql)
+	assert.Equal(t, []any{"2024-01-01", "2024-01-07"}, args)
+
+	sql, args, err = Overlaps("starts_at", "ends_at", "2024-01-01", Expr("? + INTERVAL 1 DAY", "2024-01-06")).
+		Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "starts_at < ? + INTERVAL 1 DAY AND ends_at > ?", sql)
+	assert.Equal(t, []any{"2024-01-06", "2024-01-01"}, args)
+
+	sql, args, err = Select("id").From("bookings").
+		Where(Overlaps("starts_at", "ends_at", 1, 2)).Where(Eq{"room_id": 3}).
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM bookings WHERE (starts_at, ends_at) OVERLAPS ($1, $2) AND room_id = $3", sql)
+	assert.Equal(t, []any{1, 2, 3}, args)
+
+	_, _, err = Overlaps("", "ends_at", 1, 2).ToSql()
+	assert.Error(t, err)
+}
+
+func TestRangeOperators(t *testing.T) {
+	sql, args, err := RangeContains("during", "2024-01-01").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "during @> ?", sql)
+	assert.Equal(t, []any{"2024-01-01"}, args)
+
+	sql, args, err = RangeContainedBy("during", Expr("tstzrange(?, ?)", 1, 2)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "during <@ tstzrange(?, ?)", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	sql, args, err = RangeOverlaps("during", "[2024-01-01,2024-01-07)").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "during && ?", sql)
+	assert.Equal(t, []any{"[2024-01-01,2024-01-07)"}, args)
+
+	_, _, err = RangeOverlaps("", 1).ToSql()
+	assert.Error(t, err)
+}
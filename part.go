@@ -1,10 +1,42 @@
 package squirrel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 )
 
+// ErrNilSqlizer is returned (wrapped with the name of the clause) by ToSql when a
+// nil Sqlizer was given to a builder method which can't skip it.
+var ErrNilSqlizer = errors.New("nil Sqlizer")
+
+// nilSqlizer takes the place of a nil Sqlizer given for clause.
+type nilSqlizer struct {
+	clause string
+}
+
+func (n nilSqlizer) ToSql() (string, []any, error) {
+	return "", nil, fmt.Errorf("%w in %s", ErrNilSqlizer, n.clause)
+}
+
+// orNilSqlizer returns e, or a nilSqlizer for clause if e is nil.
+func orNilSqlizer(e Sqlizer, clause string) Sqlizer {
+	if e == nil {
+		return nilSqlizer{clause: clause}
+	}
+	return e
+}
+
+// orNilSqlizers is orNilSqlizer for every item of exprs.
+func orNilSqlizers(exprs []Sqlizer, clause string) []Sqlizer {
+	result := make([]Sqlizer, len(exprs))
+	for i, e := range exprs {
+		result[i] = orNilSqlizer(e, clause)
+	}
+	return result
+}
+
 type part struct {
 	pred any
 	args []any
@@ -15,12 +47,19 @@ func newPart(pred any, args ...any) Sqlizer {
 }
 
 func (p part) ToSql() (sql string, args []any, err error) {
+	return p.toSqlRawContext(context.Background())
+}
+
+func (p part) toSqlRawContext(ctx context.Context) (sql string, args []any, err error) {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
 	case Sqlizer:
-		sql, args, err = nestedToSql(pred)
+		sql, args, err = nestedToSqlContext(ctx, pred)
 	case string:
+		if hasListArg(p.args) {
+			return interpolateArgs(pred, p.args, false)
+		}
 		sql = pred
 		args = p.args
 	default:
@@ -29,6 +68,22 @@ func (p part) ToSql() (sql string, args []any, err error) {
 	return
 }
 
+// operandToSql returns the SQL and args of a value bound to a placeholder or of a Sqlizer.
+func operandToSql(v any) (string, []any, error) {
+	if s, ok := v.(Sqlizer); ok {
+		return nestedToSql(s)
+	}
+	return "?", []any{v}, nil
+}
+
+// columnOperandToSql is operandToSql with strings taken as SQL, e.g. column names.
+func columnOperandToSql(v any) (string, []any, error) {
+	if s, ok := v.(string); ok {
+		return s, nil, nil
+	}
+	return operandToSql(v)
+}
+
 func nestedToSql(s Sqlizer) (string, []any, error) {
 	if raw, ok := s.(rawSqlizer); ok {
 		return raw.toSqlRaw()
@@ -37,9 +92,34 @@ func nestedToSql(s Sqlizer) (string, []any, error) {
 	}
 }
 
+// nestedToSqlContext is nestedToSql passing ctx, if not nil, to the nested
+// builders, so that they return its error as soon as it is done as well.
+func nestedToSqlContext(ctx context.Context, s Sqlizer) (string, []any, error) {
+	if ctx != nil {
+		switch s := s.(type) {
+		case contextSqlizer:
+			return s.toSqlRawContext(ctx)
+		case rawSqlizer:
+			// rendered raw, without ctx
+		case sqlizerContext:
+			return s.ToSqlContext(ctx)
+		}
+	}
+	return nestedToSql(s)
+}
+
 func appendToSql(parts []Sqlizer, w io.Writer, sep string, args []any) ([]any, error) {
+	return appendToSqlContext(context.Background(), parts, w, sep, args)
+}
+
+// appendToSqlContext is appendToSql returning the error of ctx, if not nil, as
+// soon as it is done.
+func appendToSqlContext(ctx context.Context, parts []Sqlizer, w io.Writer, sep string, args []any) ([]any, error) {
 	for i, p := range parts {
-		partSql, partArgs, err := nestedToSql(p)
+		if err := ctxErr(ctx); err != nil {
+			return nil, err
+		}
+		partSql, partArgs, err := nestedToSqlContext(ctx, p)
 		if err != nil {
 			return nil, err
 		} else if len(partSql) == 0 {
@@ -0,0 +1,96 @@
+package squirrel
+
+import (
+	"fmt"
+	"time"
+)
+
+// PartitionPeriod is the time span of the tables of a TimePartitions scheme.
+type PartitionPeriod int
+
+const (
+	PartitionDaily   PartitionPeriod = iota + 1 // one table per day
+	PartitionMonthly                            // one table per month
+	PartitionYearly                             // one table per year
+)
+
+// timePartitions helps to query tables partitioned by time in the application
+type timePartitions struct {
+	layout string
+	period PartitionPeriod
+}
+
+// TimePartitions describes tables partitioned by time at the application level:
+// there is one table per period, named by formatting its start with layout, a
+// time.Format layout.
+// Ex:
+//
+//	events := TimePartitions("events_2006_01", PartitionMonthly)
+//	events.UnionAll(from, to, func(table string) Sqlizer {
+//		return Select("*").From(table).Where(Expr("ts >= ? AND ts < ?", from, to))
+//	})
+//	// (SELECT * FROM events_2024_01 WHERE ...) UNION ALL (SELECT * FROM events_2024_02 WHERE ...)
+func TimePartitions(layout string, period PartitionPeriod) timePartitions {
+	return timePartitions{layout: layout, period: period}
+}
+
+// start returns the start of the period t is in.
+func (p timePartitions) start(t time.Time) time.Time {
+	switch p.period {
+	case PartitionMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case PartitionYearly:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// next returns the start of the period after the one starting at t.
+func (p timePartitions) next(t time.Time) time.Time {
+	switch p.period {
+	case PartitionMonthly:
+		return t.AddDate(0, 1, 0)
+	case PartitionYearly:
+		return t.AddDate(1, 0, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// Tables returns the names of the tables holding the rows of the [from, to)
+// time range, in order. Every name is validated with SafeIdent.
+func (p timePartitions) Tables(from, to time.Time) ([]string, error) {
+	if p.period < PartitionDaily || p.period > PartitionYearly {
+		return nil, fmt.Errorf("unknown partition period %d", p.period)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("partition time range is empty: %s is not before %s", from, to)
+	}
+
+	var tables []string
+	for t := p.start(from); t.Before(to); t = p.next(t) {
+		name := t.Format(p.layout)
+		if _, err := SafeIdent(name); err != nil {
+			return nil, err
+		}
+		if len(tables) > 0 && tables[len(tables)-1] == name {
+			return nil, fmt.Errorf("partition layout %q gives the same table %s for different periods", p.layout, name)
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
+}
+
+// UnionAll returns the UNION ALL of the queries returned by query for every
+// table of the [from, to) time range.
+func (p timePartitions) UnionAll(from, to time.Time, query func(table string) Sqlizer) (UnionBuilder, error) {
+	tables, err := p.Tables(from, to)
+	if err != nil {
+		return UnionBuilder{}, err
+	}
+
+	parts := make([]Sqlizer, 0, len(tables))
+	for _, table := range tables {
+		parts = append(parts, query(table))
+	}
+	return UnionAll(parts...), nil
+}
@@ -0,0 +1,57 @@
+package squirrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimePartitionsTables(t *testing.T) {
+	from := time.Date(2023, time.November, 15, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	tables, err := TimePartitions("events_2006_01", PartitionMonthly).Tables(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"events_2023_11", "events_2023_12", "events_2024_01"}, tables)
+
+	tables, err = TimePartitions("logs_20060102", PartitionDaily).Tables(from, from.Add(36*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logs_20231115", "logs_20231116"}, tables)
+
+	tables, err = TimePartitions("archive.orders_2006", PartitionYearly).Tables(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"archive.orders_2023", "archive.orders_2024"}, tables)
+}
+
+func TestTimePartitionsUnionAll(t *testing.T) {
+	from := time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.February, 10, 0, 0, 0, 0, time.UTC)
+
+	union, err := TimePartitions("events_2006_01", PartitionMonthly).UnionAll(from, to, func(table string) Sqlizer {
+		return Select("id").From(table).Where(Expr("ts >= ? AND ts < ?", from, to))
+	})
+	assert.NoError(t, err)
+
+	sql, args, err := union.PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM events_2024_01 WHERE ts >= $1 AND ts < $2) "+
+		"UNION ALL (SELECT id FROM events_2024_02 WHERE ts >= $3 AND ts < $4)", sql)
+	assert.Equal(t, []any{from, to, from, to}, args)
+}
+
+func TestTimePartitionsErr(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := TimePartitions("events_2006_01", PartitionMonthly).Tables(from, from)
+	assert.Error(t, err)
+
+	_, err = TimePartitions("events-2006-01", PartitionMonthly).Tables(from, from.AddDate(0, 1, 0))
+	assert.Error(t, err)
+
+	_, err = TimePartitions("events_2006", PartitionMonthly).Tables(from, from.AddDate(0, 2, 0))
+	assert.Error(t, err)
+
+	_, err = TimePartitions("events_2006_01", PartitionPeriod(0)).Tables(from, from.AddDate(0, 1, 0))
+	assert.Error(t, err)
+}
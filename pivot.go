@@ -0,0 +1,88 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pivotColumn helps to aggregate the rows matching one value of a pivot column
+type pivotColumn struct {
+	column    string
+	aggregate string
+	value     string
+	pivot     any
+	alias     string
+}
+
+func (c pivotColumn) ToSql() (string, []any, error) {
+	if len(c.column) == 0 || len(c.aggregate) == 0 {
+		return "", nil, fmt.Errorf("pivot requires a column and an aggregate function")
+	}
+
+	value := c.value
+	if len(value) == 0 || value == "*" {
+		value = "1"
+	}
+
+	if s, ok := c.pivot.(Sqlizer); ok {
+		pivotSql, pivotArgs, err := nestedToSql(s)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s(CASE WHEN %s = %s THEN %s END) AS %s",
+			c.aggregate, c.column, pivotSql, value, c.alias), pivotArgs, nil
+	}
+
+	if c.pivot == nil {
+		return fmt.Sprintf("%s(CASE WHEN %s IS NULL THEN %s END) AS %s", c.aggregate, c.column, value, c.alias), nil, nil
+	}
+	return fmt.Sprintf("%s(CASE WHEN %s = ? THEN %s END) AS %s",
+		c.aggregate, c.column, value, c.alias), []any{c.pivot}, nil
+}
+
+// pivotAlias returns the name of the result column of the pivot value: the pivot
+// column name and the value joined by an underscore, with every character which
+// isn't a letter or a digit replaced with an underscore (e.g. status_on_hold).
+func pivotAlias(column string, pivot any) string {
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		column = column[i+1:]
+	}
+
+	value := "null"
+	if s, ok := pivot.(Sqlizer); ok {
+		value, _, _ = s.ToSql()
+	} else if pivot != nil {
+		value = fmt.Sprint(pivot)
+	}
+
+	alias := []byte(strings.ToLower(column + "_" + value))
+	for i, c := range alias {
+		if !('a' <= c && c <= 'z') && !('0' <= c && c <= '9') {
+			alias[i] = '_'
+		}
+	}
+	return string(alias)
+}
+
+// Pivot adds a column per pivot value to the query, aggregating value with the
+// aggregate function over the rows where column equals the pivot value. The
+// columns use CASE expressions, so they work with every dialect; use "*" as value
+// with COUNT to count the rows. The result columns are named after the column
+// and the pivot value, see the example.
+// Ex:
+//
+//	Select("region").From("sales").GroupBy("region").Pivot("quarter", "SUM", "amount", "Q1", "Q2")
+//	// SELECT region, SUM(CASE WHEN quarter = ? THEN amount END) AS quarter_q1,
+//	// SUM(CASE WHEN quarter = ? THEN amount END) AS quarter_q2 FROM sales GROUP BY region
+func (b SelectBuilder) Pivot(column, aggregate, value string, pivots ...any) SelectBuilder {
+	for _, pivot := range pivots {
+		b = b.Column(pivotColumn{
+			column:    column,
+			aggregate: aggregate,
+			value:     value,
+			pivot:     pivot,
+			alias:     pivotAlias(column, pivot),
+		})
+	}
+	return b
+}
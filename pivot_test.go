@@ -0,0 +1,33 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuilderPivot(t *testing.T) {
+	sql, args, err := Select("region").From("sales").GroupBy("region").
+		Pivot("s.quarter", "SUM", "amount", "Q1", "Q2").
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT region, "+
+		"SUM(CASE WHEN s.quarter = $1 THEN amount END) AS quarter_q1, "+
+		"SUM(CASE WHEN s.quarter = $2 THEN amount END) AS quarter_q2 "+
+		"FROM sales GROUP BY region", sql)
+	assert.Equal(t, []any{"Q1", "Q2"}, args)
+
+	sql, args, err = Select("team").From("tickets").GroupBy("team").
+		Pivot("status", "COUNT", "*", "on hold", 2024, nil, Expr("'x'")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT team, "+
+		"COUNT(CASE WHEN status = ? THEN 1 END) AS status_on_hold, "+
+		"COUNT(CASE WHEN status = ? THEN 1 END) AS status_2024, "+
+		"COUNT(CASE WHEN status IS NULL THEN 1 END) AS status_null, "+
+		"COUNT(CASE WHEN status = 'x' THEN 1 END) AS status__x_ "+
+		"FROM tickets GROUP BY team", sql)
+	assert.Equal(t, []any{"on hold", 2024}, args)
+
+	_, _, err = Select("a").From("t").Pivot("", "SUM", "x", 1).ToSql()
+	assert.Error(t, err)
+}
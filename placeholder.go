@@ -2,6 +2,7 @@ package squirrel
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"strings"
 )
@@ -85,6 +86,40 @@ func Placeholders(count int) string {
 	return strings.Repeat(",?", count)[1:]
 }
 
+// PlaceholderList returns count ? placeholders joined with commas, enclosed in parentheses.
+// Ex: PlaceholderList(3) -> "(?,?,?)"
+func PlaceholderList(count int) string {
+	if count < 1 {
+		return ""
+	}
+
+	return "(" + Placeholders(count) + ")"
+}
+
+// PlaceholderRows returns rows tuples of cols ? placeholders joined with commas,
+// e.g. for the VALUES list of a bulk insert.
+// Ex: PlaceholderRows(2, 3) -> "(?,?,?),(?,?,?)"
+func PlaceholderRows(rows, cols int) string {
+	if rows < 1 || cols < 1 {
+		return ""
+	}
+
+	return strings.Repeat(","+PlaceholderList(cols), rows)[1:]
+}
+
+// NamedArgs wraps args into sql.NamedArg values named after the AtP placeholders
+// (p1, p2, p3, ...). Useful for clients taking named parameters only, e.g. BigQuery:
+//
+//	sql, args, _ := query.PlaceholderFormat(AtP).ToSql()
+//	rows, err := db.Query(sql, NamedArgs(args)...)
+func NamedArgs(args []any) []any {
+	named := make([]any, len(args))
+	for i, arg := range args {
+		named[i] = sql.Named(fmt.Sprintf("p%d", i+1), arg)
+	}
+	return named
+}
+
 func replacePositionalPlaceholders(sql, prefix string) (string, error) {
 	buf := &bytes.Buffer{}
 	i := 0
@@ -1,6 +1,7 @@
 package squirrel
 
 import (
+	"database/sql"
 	"strings"
 	"testing"
 
@@ -53,6 +54,18 @@ func TestEscapeAtp(t *testing.T) {
 	assert.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['@p1'] AND enabled = @p2", s)
 }
 
+func TestPlaceholderList(t *testing.T) {
+	assert.Equal(t, "(?,?,?)", PlaceholderList(3))
+	assert.Equal(t, "", PlaceholderList(0))
+}
+
+func TestPlaceholderRows(t *testing.T) {
+	assert.Equal(t, "(?,?,?),(?,?,?)", PlaceholderRows(2, 3))
+	assert.Equal(t, "(?)", PlaceholderRows(1, 1))
+	assert.Equal(t, "", PlaceholderRows(2, 0))
+	assert.Equal(t, "", PlaceholderRows(0, 2))
+}
+
 func BenchmarkPlaceholdersArray(b *testing.B) {
 	var count = b.N
 	placeholders := make([]string, count)
@@ -65,3 +78,8 @@ func BenchmarkPlaceholdersArray(b *testing.B) {
 func BenchmarkPlaceholdersStrings(b *testing.B) {
 	Placeholders(b.N)
 }
+
+func TestNamedArgs(t *testing.T) {
+	args := NamedArgs([]any{1, "a"})
+	assert.Equal(t, []any{sql.Named("p1", 1), sql.Named("p2", "a")}, args)
+}
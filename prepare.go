@@ -0,0 +1,126 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// preparedStmt helps to manage server-side prepared statements explicitly
+type preparedStmt struct {
+	name    string
+	query   Sqlizer
+	dialect Dialect
+}
+
+// Prepare allows to create a named server-side prepared statement from q, e.g.
+// when driver-level prepared statements can't be used behind a transaction
+// pooler. The args of q are not bound: the placeholders of q are the parameters
+// of the statement, pass their values to Execute.
+// Ex:
+//
+//	p := Prepare("user_by_id", Select("*").From("users").Where("id = ?")).Dialect(DialectPostgres)
+//	p.ToSql()            // PREPARE user_by_id AS SELECT * FROM users WHERE id = $1
+//	p.Execute(1).ToSql() // EXECUTE user_by_id(1)
+func Prepare(name string, q Sqlizer) preparedStmt {
+	return preparedStmt{name: name, query: q}
+}
+
+// Dialect sets the dialect used to render the statements.
+func (p preparedStmt) Dialect(d Dialect) preparedStmt {
+	p.dialect = d
+	return p
+}
+
+func (p preparedStmt) validate() error {
+	if len(p.name) == 0 {
+		return fmt.Errorf("prepared statement name must not be empty")
+	}
+	switch p.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectDuckDB, DialectMySQL, DialectMariaDB:
+		return nil
+	}
+	return p.dialect.unsupportedError("PREPARE")
+}
+
+// ToSql builds the PREPARE statement.
+func (p preparedStmt) ToSql() (string, []any, error) {
+	if err := p.validate(); err != nil {
+		return "", nil, err
+	}
+
+	sql, _, err := nestedToSql(p.query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if p.dialect.isMySQL() {
+		return fmt.Sprintf("PREPARE %s FROM %s", p.name, p.dialect.stringLiteral(sql)), nil, nil
+	}
+
+	sql, err = Dollar.ReplacePlaceholders(sql)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("PREPARE %s AS %s", p.name, sql), nil, nil
+}
+
+// Execute returns the EXECUTE statement of the prepared statement with the given
+// parameter values. As utility statements can't have bound args, the values are
+// rendered as literals of the dialect (see Dialect.Literal). For MySQL they are
+// assigned to user variables first, so the statement needs multi-statement support.
+func (p preparedStmt) Execute(args ...any) Sqlizer {
+	return executeStmt{stmt: p, args: args}
+}
+
+// Deallocate returns the DEALLOCATE statement of the prepared statement.
+func (p preparedStmt) Deallocate() Sqlizer {
+	return deallocateStmt{stmt: p}
+}
+
+type executeStmt struct {
+	stmt preparedStmt
+	args []any
+}
+
+func (e executeStmt) ToSql() (string, []any, error) {
+	p := e.stmt
+	if err := p.validate(); err != nil {
+		return "", nil, err
+	}
+
+	if len(e.args) == 0 {
+		return "EXECUTE " + p.name, nil, nil
+	}
+
+	literals := make([]string, len(e.args))
+	for i, arg := range e.args {
+		literals[i] = p.dialect.Literal(arg)
+	}
+
+	if p.dialect.isMySQL() {
+		assignments := make([]string, len(e.args))
+		variables := make([]string, len(e.args))
+		for i, literal := range literals {
+			variables[i] = fmt.Sprintf("@%s_%d", p.name, i+1)
+			assignments[i] = variables[i] + " = " + literal
+		}
+		return fmt.Sprintf("SET %s; EXECUTE %s USING %s",
+			strings.Join(assignments, ", "), p.name, strings.Join(variables, ", ")), nil, nil
+	}
+
+	return fmt.Sprintf("EXECUTE %s(%s)", p.name, strings.Join(literals, ", ")), nil, nil
+}
+
+type deallocateStmt struct {
+	stmt preparedStmt
+}
+
+func (e deallocateStmt) ToSql() (string, []any, error) {
+	if err := e.stmt.validate(); err != nil {
+		return "", nil, err
+	}
+	if e.stmt.dialect.isMySQL() {
+		return "DEALLOCATE PREPARE " + e.stmt.name, nil, nil
+	}
+	return "DEALLOCATE " + e.stmt.name, nil, nil
+}
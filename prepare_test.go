@@ -0,0 +1,52 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreparePostgres(t *testing.T) {
+	p := Prepare("user_by_id", Select("*").From("users").Where("id = ? AND name = ?")).Dialect(DialectPostgres)
+
+	sql, args, err := p.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "PREPARE user_by_id AS SELECT * FROM users WHERE id = $1 AND name = $2", sql)
+	assert.Empty(t, args)
+
+	sql, args, err = p.Execute(1, "O'Brien").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "EXECUTE user_by_id(1, 'O''Brien')", sql)
+	assert.Empty(t, args)
+
+	sql, _, err = p.Deallocate().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DEALLOCATE user_by_id", sql)
+}
+
+func TestPrepareMySQL(t *testing.T) {
+	p := Prepare("user_by_id", Select("*").From("users").Where("name = 'x' AND id = ?")).Dialect(DialectMySQL)
+
+	sql, _, err := p.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "PREPARE user_by_id FROM 'SELECT * FROM users WHERE name = ''x'' AND id = ?'", sql)
+
+	sql, _, err = p.Execute(1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SET @user_by_id_1 = 1; EXECUTE user_by_id USING @user_by_id_1", sql)
+
+	sql, _, err = p.Deallocate().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DEALLOCATE PREPARE user_by_id", sql)
+}
+
+func TestPrepareErr(t *testing.T) {
+	_, _, err := Prepare("", Select("1")).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Prepare("q", Select("1")).Dialect(DialectMSSQL).Execute().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Prepare("q", Select()).ToSql()
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package squirrel
+
+import (
+	"fmt"
+)
+
+// rawQuery helps to use handwritten statements like the other builders
+type rawQuery struct {
+	sql               string
+	args              []any
+	placeholderFormat PlaceholderFormat
+}
+
+// RawQuery allows to use a handwritten statement as a Sqlizer, e.g. for legacy
+// queries. The statement must use ? placeholders, which are replaced with the
+// format set by PlaceholderFormat. Slice and Sqlizer args are expanded like in Expr.
+//
+// ToSql returns an error if the statement contains numbered placeholders
+// ($1, :1, @p1) or if the number of placeholders doesn't match the args.
+//
+// Ex: RawQuery("SELECT * FROM users WHERE id IN (?)", ids).PlaceholderFormat(Dollar)
+func RawQuery(sql string, args ...any) rawQuery {
+	return rawQuery{sql: sql, args: args}
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the query.
+func (q rawQuery) PlaceholderFormat(f PlaceholderFormat) rawQuery {
+	q.placeholderFormat = f
+	return q
+}
+
+func (q rawQuery) toSqlRaw() (string, []any, error) {
+	if p := numberedPlaceholder(q.sql); p != "" {
+		return "", nil, fmt.Errorf("raw query must use ? placeholders, found %s", p)
+	}
+
+	sql, args, err := interpolateArgs(q.sql, q.args, true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if count := countPlaceholders(sql); count != len(args) {
+		return "", nil, fmt.Errorf("raw query has %d placeholders for %d args", count, len(args))
+	}
+	return sql, args, nil
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (q rawQuery) ToSql() (string, []any, error) {
+	sql, args, err := q.toSqlRaw()
+	if err != nil || q.placeholderFormat == nil {
+		return sql, args, err
+	}
+
+	sql, err = q.placeholderFormat.ReplacePlaceholders(sql)
+	return sql, args, err
+}
+
+// scanSql calls f with the index of every byte of sql outside of quoted
+// strings and identifiers. f returns the number of bytes to skip after i.
+func scanSql(sql string, f func(i int) int) {
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' || c == '`' {
+			quote = c
+			continue
+		}
+		i += f(i)
+	}
+}
+
+// countPlaceholders returns the number of ? placeholders of sql, "??" excluded.
+func countPlaceholders(sql string) int {
+	return len(placeholderIndexes(sql))
+}
+
+// placeholderIndexes returns the indexes of the ? placeholders of sql, "??" and
+// quoted ones excluded.
+func placeholderIndexes(sql string) []int {
+	var indexes []int
+	scanSql(sql, func(i int) int {
+		if sql[i] != '?' {
+			return 0
+		}
+		if i+1 < len(sql) && sql[i+1] == '?' {
+			return 1
+		}
+		indexes = append(indexes, i)
+		return 0
+	})
+	return indexes
+}
+
+// numberedPlaceholder returns the first $N, :N or @pN placeholder of sql, or "".
+func numberedPlaceholder(sql string) (placeholder string) {
+	scanSql(sql, func(i int) int {
+		if placeholder != "" {
+			return 0
+		}
+		if _, length := numberedPlaceholderAt(sql, i); length > 0 {
+			placeholder = sql[i : i+length]
+			return length - 1
+		}
+		return 0
+	})
+	return placeholder
+}
+
+// numberedPlaceholderAt returns the number and the length of the $N, :N or @pN
+// placeholder starting at sql[i], or a zero length if there is none.
+func numberedPlaceholderAt(sql string, i int) (n int, length int) {
+	isDigit := func(i int) bool { return i < len(sql) && '0' <= sql[i] && sql[i] <= '9' }
+
+	start := -1
+	switch {
+	case sql[i] == '$' && isDigit(i+1):
+		start = i + 1
+	case sql[i] == ':' && isDigit(i+1) && (i == 0 || sql[i-1] != ':'):
+		start = i + 1
+	case sql[i] == '@' && i+2 < len(sql) && sql[i+1] == 'p' && isDigit(i+2):
+		start = i + 2
+	}
+	if start < 0 || (i > 0 && isIdentifierChar(sql[i-1])) {
+		return 0, 0
+	}
+
+	end := start
+	for isDigit(end) {
+		n = n*10 + int(sql[end]-'0')
+		end++
+	}
+	return n, end - i
+}
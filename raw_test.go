@@ -0,0 +1,51 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawQuery(t *testing.T) {
+	q := RawQuery("SELECT * FROM users WHERE id IN (?) AND name <> '?' AND data ?? 'k' AND age > ?", []int{1, 2}, 18)
+
+	sql, args, err := q.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id IN (?,?) AND name <> '?' AND data ?? 'k' AND age > ?", sql)
+	assert.Equal(t, []any{1, 2, 18}, args)
+
+	sql, _, err = RawQuery("SELECT * FROM users WHERE created_at::date = ?", "2024-01-01").PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE created_at::date = $1", sql)
+
+	// array slices are not :N placeholders
+	sql, args, err = RawQuery("SELECT tags[1:2] FROM users WHERE id = ?", 1).PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT tags[1:2] FROM users WHERE id = $1", sql)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestRawQueryErrors(t *testing.T) {
+	_, _, err := RawQuery("SELECT * FROM users WHERE id = $1", 1).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = RawQuery("SELECT * FROM users WHERE id = ? AND name = @p2", 1, "x").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = RawQuery("SELECT * FROM users WHERE id = ?", 1, 2).ToSql()
+	assert.Error(t, err)
+
+	// numbered placeholders inside strings are ignored
+	_, _, err = RawQuery("SELECT '$1' FROM users").ToSql()
+	assert.NoError(t, err)
+}
+
+func TestRawQueryNested(t *testing.T) {
+	sql, args, err := With("active", RawQuery("SELECT id FROM users WHERE active = ?", true)).
+		Select(Select("id").From("active").Where("id > ?", 10)).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH active AS (SELECT id FROM users WHERE active = $1) SELECT id FROM active WHERE id > $2", sql)
+	assert.Equal(t, []any{true, 10}, args)
+}
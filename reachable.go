@@ -0,0 +1,122 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// defaultReachableDepth is the depth cap of Reachable for dialects without arrays
+// to record the visited path.
+const defaultReachableDepth = 100
+
+// reachableQuery helps to walk graphs stored as edge tables
+type reachableQuery struct {
+	edges             string
+	fromColumn        string
+	toColumn          string
+	start             []any
+	maxDepth          int
+	dialect           Dialect
+	placeholderFormat PlaceholderFormat
+}
+
+// Reachable allows to select the nodes reachable from the From nodes following
+// the edges of the edges table, from fromColumn to toColumn. A recursive CTE named
+// reachable is generated, with the node, its depth (1 for the direct neighbours)
+// and, for PostgreSQL, the visited path, which stops the walk on cycles. Other
+// dialects don't have arrays to record the path, so the walk is stopped at Depth
+// (defaultReachableDepth when not set) instead.
+// Ex:
+//
+//	Reachable("follows", "follower_id", "followee_id").From(1)
+//	// WITH RECURSIVE reachable (node, depth, path) AS (
+//	// SELECT e.followee_id, 1, ARRAY[e.follower_id, e.followee_id] FROM follows e WHERE e.follower_id IN (?)
+//	// UNION ALL SELECT e.followee_id, r.depth + 1, r.path || e.followee_id FROM follows e
+//	// JOIN reachable r ON e.follower_id = r.node WHERE e.followee_id <> ALL(r.path))
+//	// SELECT DISTINCT node FROM reachable
+func Reachable(edges, fromColumn, toColumn string) reachableQuery {
+	return reachableQuery{edges: edges, fromColumn: fromColumn, toColumn: toColumn}
+}
+
+// From sets the ids of the nodes to start from.
+func (q reachableQuery) From(ids ...any) reachableQuery {
+	q.start = append(q.start[:len(q.start):len(q.start)], ids...)
+	return q
+}
+
+// Depth limits the walk to maxDepth edges from the start nodes.
+func (q reachableQuery) Depth(maxDepth int) reachableQuery {
+	q.maxDepth = maxDepth
+	return q
+}
+
+// Dialect sets the dialect used to render the query.
+// The placeholder format preferred by the dialect is set as well.
+func (q reachableQuery) Dialect(d Dialect) reachableQuery {
+	q.dialect = d
+	if f := d.PlaceholderFormat(); f != nil {
+		q.placeholderFormat = f
+	}
+	return q
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the query.
+func (q reachableQuery) PlaceholderFormat(f PlaceholderFormat) reachableQuery {
+	q.placeholderFormat = f
+	return q
+}
+
+func (q reachableQuery) toSqlRaw() (string, []any, error) {
+	if len(q.edges) == 0 || len(q.fromColumn) == 0 || len(q.toColumn) == 0 {
+		return "", nil, fmt.Errorf("reachable query requires an edges table, a from column and a to column")
+	}
+	if len(q.start) == 0 {
+		return "", nil, fmt.Errorf("reachable query requires at least one From node")
+	}
+	if q.dialect == DialectClickHouse {
+		return "", nil, q.dialect.unsupportedError("recursive CTE")
+	}
+
+	startSql, args, err := Eq{"e." + q.fromColumn: q.start}.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	from, to := "e."+q.fromColumn, "e."+q.toColumn
+	withPath := q.dialect == DialectDefault || q.dialect == DialectPostgres
+
+	sql := &bytes.Buffer{}
+	_, _ = sql.WriteString(recursiveWith(q.dialect))
+	if withPath {
+		_, _ = sql.WriteString("reachable (node, depth, path) AS (")
+		_, _ = fmt.Fprintf(sql, "SELECT %s, 1, ARRAY[%s, %s] FROM %s e WHERE %s", to, from, to, q.edges, startSql)
+		_, _ = fmt.Fprintf(sql, " UNION ALL SELECT %s, r.depth + 1, r.path || %s FROM %s e", to, to, q.edges)
+		_, _ = fmt.Fprintf(sql, " JOIN reachable r ON %s = r.node WHERE %s <> ALL(r.path)", from, to)
+		if q.maxDepth > 0 {
+			_, _ = fmt.Fprintf(sql, " AND r.depth < %d", q.maxDepth)
+		}
+	} else {
+		maxDepth := q.maxDepth
+		if maxDepth <= 0 {
+			maxDepth = defaultReachableDepth
+		}
+		_, _ = sql.WriteString("reachable (node, depth) AS (")
+		_, _ = fmt.Fprintf(sql, "SELECT %s, 1 FROM %s e WHERE %s", to, q.edges, startSql)
+		_, _ = fmt.Fprintf(sql, " UNION ALL SELECT %s, r.depth + 1 FROM %s e", to, q.edges)
+		_, _ = fmt.Fprintf(sql, " JOIN reachable r ON %s = r.node WHERE r.depth < %d", from, maxDepth)
+	}
+	_, _ = sql.WriteString(") SELECT DISTINCT node FROM reachable")
+
+	return sql.String(), args, nil
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (q reachableQuery) ToSql() (string, []any, error) {
+	sql, args, err := q.toSqlRaw()
+	if err != nil || q.placeholderFormat == nil {
+		return sql, args, err
+	}
+
+	sql, err = q.placeholderFormat.ReplacePlaceholders(sql)
+	return sql, args, err
+}
@@ -0,0 +1,55 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReachablePostgres(t *testing.T) {
+	sql, args, err := Reachable("follows", "follower_id", "followee_id").
+		From(1, 2).Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH RECURSIVE reachable (node, depth, path) AS ("+
+		"SELECT e.followee_id, 1, ARRAY[e.follower_id, e.followee_id] FROM follows e WHERE e.follower_id IN ($1,$2) "+
+		"UNION ALL SELECT e.followee_id, r.depth + 1, r.path || e.followee_id FROM follows e "+
+		"JOIN reachable r ON e.follower_id = r.node WHERE e.followee_id <> ALL(r.path)"+
+		") SELECT DISTINCT node FROM reachable", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	sql, _, err = Reachable("follows", "follower_id", "followee_id").From(1).Depth(3).ToSql()
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "WHERE e.followee_id <> ALL(r.path) AND r.depth < 3)")
+}
+
+func TestReachableDepthCap(t *testing.T) {
+	sql, args, err := Reachable("follows", "follower_id", "followee_id").
+		From(1).Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH RECURSIVE reachable (node, depth) AS ("+
+		"SELECT e.followee_id, 1 FROM follows e WHERE e.follower_id IN (?) "+
+		"UNION ALL SELECT e.followee_id, r.depth + 1 FROM follows e "+
+		"JOIN reachable r ON e.follower_id = r.node WHERE r.depth < 100"+
+		") SELECT DISTINCT node FROM reachable", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, _, err = Reachable("follows", "follower_id", "followee_id").
+		From(1).Depth(5).Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH reachable (node, depth) AS ("+
+		"SELECT e.followee_id, 1 FROM follows e WHERE e.follower_id IN (@p1) "+
+		"UNION ALL SELECT e.followee_id, r.depth + 1 FROM follows e "+
+		"JOIN reachable r ON e.follower_id = r.node WHERE r.depth < 5"+
+		") SELECT DISTINCT node FROM reachable", sql)
+}
+
+func TestReachableErr(t *testing.T) {
+	_, _, err := Reachable("follows", "follower_id", "followee_id").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Reachable("follows", "", "followee_id").From(1).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Reachable("follows", "follower_id", "followee_id").From(1).Dialect(DialectClickHouse).ToSql()
+	assert.Error(t, err)
+}
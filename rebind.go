@@ -0,0 +1,161 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// placeholderPrefix returns the prefix of the placeholders of f ("?" for Question).
+func placeholderPrefix(f PlaceholderFormat) (string, bool) {
+	d, ok := f.(placeholderDebugger)
+	if !ok {
+		return "", false
+	}
+	return d.debugPlaceholder(), true
+}
+
+// rebind replaces the placeholders of sql in the from format with placeholders
+// in the to format. Numbered placeholders replaced with ? placeholders may be out
+// of order or repeated, so order holds the index of the arg of every ? placeholder
+// in that case, and is nil otherwise.
+func rebind(sql string, from, to PlaceholderFormat) (_ string, order []int, err error) {
+	fromPrefix, ok := placeholderPrefix(from)
+	if !ok {
+		return "", nil, fmt.Errorf("unsupported placeholder format %T", from)
+	}
+	toPrefix, ok := placeholderPrefix(to)
+	if !ok {
+		sql, order, err = rebind(sql, from, Question)
+		if err != nil {
+			return "", nil, err
+		}
+		sql, err = to.ReplacePlaceholders(sql)
+		return sql, order, err
+	}
+
+	buf := &bytes.Buffer{}
+	next, last := 0, 0
+	scanSql(sql, func(i int) int {
+		if sql[i] == '?' {
+			if fromPrefix != "?" {
+				if toPrefix == "?" { // escape ? => ??
+					_, _ = buf.WriteString(sql[last : i+1])
+					_, _ = buf.WriteString("?")
+					last = i + 1
+				}
+				return 0
+			}
+
+			if i+1 < len(sql) && sql[i+1] == '?' { // escape ?? => ?
+				if toPrefix != "?" {
+					_, _ = buf.WriteString(sql[last:i])
+					_, _ = buf.WriteString("?")
+					last = i + 2
+				}
+				return 1
+			}
+			next++
+			_, _ = buf.WriteString(sql[last:i])
+			if toPrefix == "?" {
+				_, _ = buf.WriteString("?")
+			} else {
+				_, _ = fmt.Fprintf(buf, "%s%d", toPrefix, next)
+			}
+			last = i + 1
+			return 0
+		}
+
+		if fromPrefix == "?" || !strings.HasPrefix(sql[i:], fromPrefix) {
+			return 0
+		}
+		n, length := numberedPlaceholderAt(sql, i)
+		if length == 0 {
+			return 0
+		}
+		_, _ = buf.WriteString(sql[last:i])
+		if toPrefix == "?" {
+			_, _ = buf.WriteString("?")
+			order = append(order, n-1)
+		} else {
+			_, _ = fmt.Fprintf(buf, "%s%d", toPrefix, n)
+		}
+		last = i + length
+		return length - 1
+	})
+	_, _ = buf.WriteString(sql[last:])
+
+	return buf.String(), order, nil
+}
+
+// Rebind replaces the placeholders of sql in the from format (e.g. Dollar) with
+// placeholders in the to format (e.g. Question), e.g. to use SQL produced by
+// another library. Placeholders inside quoted strings and identifiers are left
+// as is, and literal question marks are escaped (??) or unescaped as needed.
+//
+// Rebind returns an error if numbered placeholders replaced with ? placeholders
+// are out of order or repeated, since the args would have to be reordered: use
+// Rebound in that case.
+//
+// Ex: Rebind("SELECT * FROM users WHERE id = $1 AND org_id = $2", Dollar, Question)
+// -> "SELECT * FROM users WHERE id = ? AND org_id = ?"
+func Rebind(sql string, from, to PlaceholderFormat) (string, error) {
+	sql, order, err := rebind(sql, from, to)
+	if err != nil {
+		return "", err
+	}
+	for i, n := range order {
+		if n != i {
+			return "", fmt.Errorf("placeholder %d is out of order, use Rebound to reorder the args", n+1)
+		}
+	}
+	return sql, nil
+}
+
+// reboundSqlizer helps to change the placeholder format of any Sqlizer
+type reboundSqlizer struct {
+	s      Sqlizer
+	format PlaceholderFormat
+}
+
+// Rebound wraps s so that ToSql returns SQL with placeholders in format, whatever
+// the format s uses (detected from the first numbered placeholder, ? otherwise).
+// The args are reordered or repeated when numbered placeholders are replaced with
+// ? placeholders. Use Question as format to nest a Sqlizer producing numbered
+// placeholders in a builder.
+// Ex: Select("*").From("users").Where(Rebound(Expr("org_id = $2 AND id = $1", 1, 2), Question))
+// -> "SELECT * FROM users WHERE org_id = ? AND id = ?" with args [2 1]
+func Rebound(s Sqlizer, format PlaceholderFormat) Sqlizer {
+	return reboundSqlizer{s: s, format: format}
+}
+
+func (r reboundSqlizer) ToSql() (string, []any, error) {
+	sql, args, err := r.s.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var from PlaceholderFormat = Question
+	switch p := numberedPlaceholder(sql); {
+	case strings.HasPrefix(p, "$"):
+		from = Dollar
+	case strings.HasPrefix(p, ":"):
+		from = Colon
+	case strings.HasPrefix(p, "@p"):
+		from = AtP
+	}
+
+	sql, order, err := rebind(sql, from, r.format)
+	if err != nil || order == nil {
+		return sql, args, err
+	}
+
+	reordered := make([]any, len(order))
+	for i, n := range order {
+		if n < 0 || n >= len(args) {
+			return "", nil, fmt.Errorf("placeholder %d has no arg", n+1)
+		}
+		reordered[i] = args[n]
+	}
+	return sql, reordered, nil
+}
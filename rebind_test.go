@@ -0,0 +1,50 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		sql      string
+		from, to PlaceholderFormat
+		want     string
+	}{
+		{"a = ? AND b = ?", Question, Dollar, "a = $1 AND b = $2"},
+		{"a = $1 AND b = $2", Dollar, Question, "a = ? AND b = ?"},
+		{"a = $1 AND b = $2", Dollar, AtP, "a = @p1 AND b = @p2"},
+		{"a = :2 AND b = :1", Colon, Dollar, "a = $2 AND b = $1"},
+		{"a = $1 AND b = '$2'", Dollar, Question, "a = ? AND b = '$2'"},
+		{"a = ? AND b = '?'", Question, Colon, "a = :1 AND b = '?'"},
+		{"data ?? 'key' AND id = ?", Question, Dollar, "data ? 'key' AND id = $1"},
+		{"data ? 'key' AND id = $1", Dollar, Question, "data ?? 'key' AND id = ?"},
+		{"a::int = $1", Dollar, Colon, "a::int = :1"},
+	}
+	for _, tt := range tests {
+		sql, err := Rebind(tt.sql, tt.from, tt.to)
+		assert.NoError(t, err, tt.sql)
+		assert.Equal(t, tt.want, sql, tt.sql)
+	}
+
+	_, err := Rebind("a = $2 AND b = $1", Dollar, Question)
+	assert.Error(t, err)
+}
+
+func TestRebound(t *testing.T) {
+	sql, args, err := Select("*").From("users").
+		Where(Rebound(Expr("org_id = $2 AND (id = $1 OR parent_id = $1)", 1, 2), Question)).
+		PlaceholderFormat(AtP).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE org_id = @p1 AND (id = @p2 OR parent_id = @p3)", sql)
+	assert.Equal(t, []any{2, 1, 1}, args)
+
+	sql, args, err = Rebound(Select("*").From("users").Where(Eq{"id": 1}), Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = $1", sql)
+	assert.Equal(t, []any{1}, args)
+
+	_, _, err = Rebound(Expr("id = $2", 1), Question).ToSql()
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package squirrel
+
+import (
+	"strings"
+)
+
+// DefaultRedactionMask is used by RedactionPolicy when Mask is empty.
+const DefaultRedactionMask = "[REDACTED]"
+
+// Redactor is the interface that wraps the Redact method.
+//
+// Redact is called for every bound arg rendered by DebugSqlizerRedacted.
+// column is the identifier the placeholder is compared with (e.g. "email" for
+// "email = ?"), or an empty string if it could not be detected. pos is the
+// zero-based position of the arg. If redacted is true, replacement is printed
+// instead of the arg value.
+type Redactor interface {
+	Redact(column string, pos int, arg any) (replacement string, redacted bool)
+}
+
+// RedactorFunc is an adapter to allow the use of ordinary functions as Redactor.
+type RedactorFunc func(column string, pos int, arg any) (string, bool)
+
+// Redact calls f(column, pos, arg).
+func (f RedactorFunc) Redact(column string, pos int, arg any) (string, bool) {
+	return f(column, pos, arg)
+}
+
+// RedactionPolicy is a Redactor that hides args by column name or by position.
+//
+// Column names are matched case-insensitively, either completely or by the part
+// after the last dot, so "email" matches both "email = ?" and "u.email = ?".
+// Column detection is a best effort: it works for comparisons like the ones
+// produced by Eq, Lt, Like or UpdateBuilder.Set, use Positions for the rest
+// (e.g. InsertBuilder values).
+//
+// Ex:
+//
+//	RedactionPolicy{Columns: []string{"email", "token"}}
+type RedactionPolicy struct {
+	Columns   []string
+	Positions []int
+	Mask      string
+}
+
+// Redact implements Redactor.
+func (p RedactionPolicy) Redact(column string, pos int, _ any) (string, bool) {
+	mask := p.Mask
+	if mask == "" {
+		mask = DefaultRedactionMask
+	}
+
+	for _, position := range p.Positions {
+		if position == pos {
+			return mask, true
+		}
+	}
+
+	if column == "" {
+		return "", false
+	}
+
+	short := column
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		short = column[i+1:]
+	}
+
+	for _, c := range p.Columns {
+		if strings.EqualFold(c, column) || strings.EqualFold(c, short) {
+			return mask, true
+		}
+	}
+
+	return "", false
+}
+
+func redactArg(r Redactor, column string, pos int, arg any) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	return r.Redact(column, pos, arg)
+}
+
+// placeholderColumn tries to detect the column compared with the placeholder
+// that follows sql. If sql only separates items of a list (e.g. "IN (?,?)"),
+// the previous column is kept.
+func placeholderColumn(sql, previous string) string {
+	s := strings.TrimRight(sql, " ")
+	if s == "," {
+		return previous
+	}
+
+	s = strings.TrimRight(s, " (")
+	trimmed := strings.TrimRight(s, "=<>!")
+	found := len(trimmed) != len(s)
+	s = strings.TrimRight(trimmed, " ")
+
+	if !found {
+		upper := strings.ToUpper(s)
+		for _, keyword := range []string{"NOT ILIKE", "NOT LIKE", "ILIKE", "LIKE", "NOT IN", "IN"} {
+			if strings.HasSuffix(upper, " "+keyword) {
+				s = strings.TrimRight(s[:len(s)-len(keyword)], " ")
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		return ""
+	}
+
+	start := len(s)
+	for start > 0 && isIdentifierChar(s[start-1]) {
+		start--
+	}
+
+	return strings.Trim(s[start:], "\"`")
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '.' || c == '"' || c == '`' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,54 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugSqlizerRedactedColumns(t *testing.T) {
+	b := Select("id").From("users").
+		Where(Eq{"u.email": "john@example.com", "name": "John"}).
+		Where(Eq{"token": []string{"a", "b"}})
+
+	policy := RedactionPolicy{Columns: []string{"EMAIL", "token"}}
+
+	expected := "SELECT id FROM users WHERE name = 'John' AND u.email = '[REDACTED]' AND token IN ('[REDACTED]','[REDACTED]')"
+	assert.Equal(t, expected, DebugSqlizerRedacted(b, policy))
+}
+
+func TestDebugSqlizerRedactedPositions(t *testing.T) {
+	b := Insert("users").Columns("name", "password").Values("John", "secret")
+
+	policy := RedactionPolicy{Positions: []int{1}, Mask: "***"}
+
+	expected := "INSERT INTO users (name,password) VALUES ('John','***')"
+	assert.Equal(t, expected, DebugSqlizerRedacted(b, policy))
+}
+
+func TestDebugSqlizerRedactedFunc(t *testing.T) {
+	b := Update("users").Set("token", "abc").Where(Like{"name": "J%"})
+
+	var columns []string
+	r := RedactorFunc(func(column string, pos int, arg any) (string, bool) {
+		columns = append(columns, column)
+		return "<string>", pos == 0
+	})
+
+	assert.Equal(t, "UPDATE users SET token = '<string>' WHERE name LIKE 'J%'", DebugSqlizerRedacted(b, r))
+	assert.Equal(t, []string{"token", "name"}, columns)
+}
+
+func TestDebugSqlizerRedactedNil(t *testing.T) {
+	b := Select("id").From("users").Where(Eq{"email": "john@example.com"})
+	assert.Equal(t, DebugSqlizer(b), DebugSqlizerRedacted(b, nil))
+}
+
+func TestPlaceholderColumn(t *testing.T) {
+	assert.Equal(t, "email", placeholderColumn("WHERE email = ", ""))
+	assert.Equal(t, "u.id", placeholderColumn("WHERE u.id >= ", ""))
+	assert.Equal(t, "id", placeholderColumn("WHERE id NOT IN (", ""))
+	assert.Equal(t, "id", placeholderColumn(",", "id"))
+	assert.Equal(t, "name", placeholderColumn(` AND "name" ILIKE `, ""))
+	assert.Equal(t, "", placeholderColumn("VALUES (", ""))
+}
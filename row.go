@@ -0,0 +1,97 @@
+package squirrel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	compositeMutex  sync.RWMutex
+	compositeFields = map[reflect.Type]func(v any) []any{}
+)
+
+// RegisterComposite registers how Row binds values of the type of sample: fields
+// returns the values of the composite type's attributes, in their declared order.
+// It is typically used for PostgreSQL composite types.
+// Ex:
+//
+//	RegisterComposite(Address{}, func(v any) []any {
+//		a := v.(Address)
+//		return []any{a.Street, a.City}
+//	})
+//	Row(Address{"Main St", "Paris"}) // ROW(?,?)
+func RegisterComposite(sample any, fields func(v any) []any) {
+	compositeMutex.Lock()
+	defer compositeMutex.Unlock()
+
+	compositeFields[reflect.TypeOf(sample)] = fields
+}
+
+func compositeValues(v any) ([]any, bool) {
+	compositeMutex.RLock()
+	defer compositeMutex.RUnlock()
+
+	fields, ok := compositeFields[reflect.TypeOf(v)]
+	if !ok {
+		return nil, false
+	}
+	return fields(v), true
+}
+
+// rowExpr helps to use row values in SQL query
+type rowExpr struct {
+	values  []any
+	dialect Dialect
+}
+
+// Row allows to use a row value (row constructor), e.g. to compare several columns
+// at once or to insert into a composite column. Values are bound as args, unless
+// they are Sqlizers. A single value whose type was registered with
+// RegisterComposite is expanded into its attributes.
+// It is rendered as ROW(...) for PostgreSQL, MySQL and DuckDB and as (...) for
+// the other dialects.
+// Ex: Select("*").From("t").Where(Expr("(a, b) = ?", Row(1, 2))) -> "SELECT * FROM t WHERE (a, b) = ROW(?,?)"
+func Row(values ...any) rowExpr {
+	return rowExpr{values: values}
+}
+
+// Dialect sets the dialect used to render the row value.
+func (e rowExpr) Dialect(d Dialect) rowExpr {
+	e.dialect = d
+	return e
+}
+
+func (e rowExpr) ToSql() (string, []any, error) {
+	if e.dialect == DialectMSSQL {
+		return "", nil, e.dialect.unsupportedError("row values")
+	}
+
+	values := e.values
+	if len(values) == 1 {
+		if fields, ok := compositeValues(values[0]); ok {
+			values = fields
+		}
+	}
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("row values must have at least one value")
+	}
+
+	sql := &bytes.Buffer{}
+	switch e.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectMySQL, DialectMariaDB, DialectDuckDB:
+		_, _ = sql.WriteString("ROW(")
+	default:
+		_, _ = sql.WriteString("(")
+	}
+
+	args, err := appendValueList(context.Background(), sql, values, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	_, _ = sql.WriteString(")")
+
+	return sql.String(), args, nil
+}
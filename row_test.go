@@ -0,0 +1,44 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	Street string
+	City   string
+}
+
+func TestRow(t *testing.T) {
+	sql, args, err := Select("*").From("t").Where(Expr("(a, b) = ?", Row(1, 2))).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE (a, b) = ROW(?,?)", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	sql, args, err = Row(1, Expr("now()"), Row("x").Dialect(DialectSQLite)).Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(?,now(),(?))", sql)
+	assert.Equal(t, []any{1, "x"}, args)
+
+	_, _, err = Row().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Row(1, 2).Dialect(DialectMSSQL).ToSql()
+	assert.Error(t, err)
+}
+
+func TestRowComposite(t *testing.T) {
+	RegisterComposite(testAddress{}, func(v any) []any {
+		a := v.(testAddress)
+		return []any{a.Street, a.City}
+	})
+
+	sql, args, err := Insert("users").Columns("name", "address").
+		Values("John", Row(testAddress{"Main St", "Paris"}).Dialect(DialectPostgres)).
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (name,address) VALUES ($1,ROW($2,$3))", sql)
+	assert.Equal(t, []any{"John", "Main St", "Paris"}, args)
+}
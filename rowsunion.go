@@ -0,0 +1,105 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rowsUnion helps to select literal rows with a SELECT repeated for every row
+type rowsUnion struct {
+	template          Sqlizer
+	rows              [][]any
+	placeholderFormat PlaceholderFormat
+}
+
+// UnionAllFromRows allows to select literal rows, e.g. on engines without VALUES
+// in FROM: selectTemplate is rendered once and repeated for every row, joined
+// with UNION ALL, its ? placeholders bound to the values of the row. No builder
+// is created per row, so thousands of rows are rendered in a single pass.
+// Values which are Sqlizers are inlined, the others, slices included, are bound
+// as is to the placeholders outside of quoted strings.
+// Ex: UnionAllFromRows(Select("? AS id", "? AS name"), [][]any{{1, "a"}, {2, "b"}})
+// -> "SELECT ? AS id, ? AS name UNION ALL SELECT ? AS id, ? AS name" with args [1 a 2 b]
+func UnionAllFromRows(selectTemplate Sqlizer, rows [][]any) rowsUnion {
+	return rowsUnion{template: selectTemplate, rows: rows}
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the query.
+func (u rowsUnion) PlaceholderFormat(f PlaceholderFormat) rowsUnion {
+	u.placeholderFormat = f
+	return u
+}
+
+func (u rowsUnion) toSqlRaw() (string, []any, error) {
+	if u.template == nil {
+		return "", nil, fmt.Errorf("%w in UnionAllFromRows template", ErrNilSqlizer)
+	}
+	if len(u.rows) == 0 {
+		return "", nil, fmt.Errorf("union from rows requires at least one row")
+	}
+
+	template, templateArgs, err := nestedToSql(u.template)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(templateArgs) > 0 {
+		return "", nil, fmt.Errorf("union from rows template must not bind args, use ? placeholders for the row values")
+	}
+
+	placeholders := placeholderIndexes(template)
+	if len(placeholders) == 0 {
+		return "", nil, fmt.Errorf("union from rows template has no placeholders")
+	}
+
+	sql := &strings.Builder{}
+	sql.Grow(len(u.rows) * (len(template) + len(" UNION ALL ")))
+	args := make([]any, 0, len(u.rows)*len(placeholders))
+	for i, row := range u.rows {
+		if len(row) != len(placeholders) {
+			return "", nil, fmt.Errorf("union from rows: row %d has %d values, the template has %d placeholders", i, len(row), len(placeholders))
+		}
+		if i > 0 {
+			_, _ = sql.WriteString(" UNION ALL ")
+		}
+		if args, err = writeRow(sql, template, placeholders, row, args); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return sql.String(), args, nil
+}
+
+// writeRow writes template with the values of row bound to its placeholders, at
+// the given indexes: the Sqlizers are inlined, the other values are appended to
+// args.
+func writeRow(sql *strings.Builder, template string, placeholders []int, row []any, args []any) ([]any, error) {
+	last := 0
+	for j, v := range row {
+		s, ok := v.(Sqlizer)
+		if !ok {
+			args = append(args, v)
+			continue
+		}
+		vSql, vArgs, err := nestedToSql(s)
+		if err != nil {
+			return nil, err
+		}
+		_, _ = sql.WriteString(template[last:placeholders[j]])
+		_, _ = sql.WriteString(vSql)
+		last = placeholders[j] + 1
+		args = append(args, vArgs...)
+	}
+	_, _ = sql.WriteString(template[last:])
+	return args, nil
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (u rowsUnion) ToSql() (string, []any, error) {
+	sql, args, err := u.toSqlRaw()
+	if err != nil || u.placeholderFormat == nil {
+		return sql, args, err
+	}
+
+	sql, err = u.placeholderFormat.ReplacePlaceholders(sql)
+	return sql, args, err
+}
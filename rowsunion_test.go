@@ -0,0 +1,61 @@
+package squirrel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnionAllFromRows(t *testing.T) {
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, Expr("UPPER(?)", "c")}}
+	sql, args, err := UnionAllFromRows(Select("? AS id", "? AS name"), rows).PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT $1 AS id, $2 AS name UNION ALL SELECT $3 AS id, $4 AS name "+
+		"UNION ALL SELECT $5 AS id, UPPER($6) AS name", sql)
+	assert.Equal(t, []any{1, "a", 2, "b", 3, "c"}, args)
+
+	sql, _, err = UnionAllFromRows(Select("? AS id").Dialect(DialectOracle), [][]any{{1}, {2}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ? AS id FROM DUAL UNION ALL SELECT ? AS id FROM DUAL", sql)
+
+	sql, args, err = Select("v.id").
+		FromExpr(Alias(UnionAllFromRows(Select("? AS id", "'??' AS q"), [][]any{{1}, {2}}), "v")).
+		Join("users u ON u.id = v.id").PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT v.id FROM (SELECT $1 AS id, '?' AS q UNION ALL SELECT $2 AS id, '?' AS q) AS v "+
+		"JOIN users u ON u.id = v.id", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	// quoted ? and slices are bound the same way with or without Sqlizers in the row
+	sql, args, err = UnionAllFromRows(Select("'?' AS q", "? AS id", "? AS ids"),
+		[][]any{{1, []int{1, 2}}, {Expr("2"), []int{3}}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT '?' AS q, ? AS id, ? AS ids UNION ALL SELECT '?' AS q, 2 AS id, ? AS ids", sql)
+	assert.Equal(t, []any{1, []int{1, 2}, []int{3}}, args)
+}
+
+func TestUnionAllFromRowsErr(t *testing.T) {
+	_, _, err := UnionAllFromRows(Select("? AS id"), nil).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = UnionAllFromRows(Select("? AS id"), [][]any{{1}, {1, 2}}).ToSql()
+	assert.EqualError(t, err, "union from rows: row 1 has 2 values, the template has 1 placeholders")
+
+	_, _, err = UnionAllFromRows(Select("id").From("t").Where(Eq{"a": 1}), [][]any{{1}}).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = UnionAllFromRows(nil, [][]any{{1}}).ToSql()
+	assert.True(t, errors.Is(err, ErrNilSqlizer))
+}
+
+func BenchmarkUnionAllFromRows(b *testing.B) {
+	rows := make([][]any, 10000)
+	for i := range rows {
+		rows[i] = []any{i, "name"}
+	}
+	q := UnionAllFromRows(Select("? AS id", "? AS name"), rows)
+	for i := 0; i < b.N; i++ {
+		_, _, _ = q.ToSql()
+	}
+}
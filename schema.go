@@ -0,0 +1,706 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// registeredSchema holds the map[string]map[string]bool of the registered tables
+// and their columns.
+var registeredSchema atomic.Value
+
+// RegisterSchema registers the tables and their columns used by the Validate
+// methods of the builders, replacing the previously registered schema. Names are
+// matched case-insensitively.
+// Ex:
+//
+//	RegisterSchema(map[string][]string{
+//		"users": {"id", "name", "org_id"},
+//		"orgs":  {"id", "name"},
+//	})
+func RegisterSchema(schema map[string][]string) {
+	tables := make(map[string]map[string]bool, len(schema))
+	for table, columns := range schema {
+		set := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			set[strings.ToLower(column)] = true
+		}
+		tables[strings.ToLower(table)] = set
+	}
+
+	registeredSchema.Store(tables)
+}
+
+// sqlKeywords are the keywords which look like plain columns in select lists and predicates.
+var sqlKeywords = map[string]bool{
+	"NULL": true, "TRUE": true, "FALSE": true, "DEFAULT": true,
+	"CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true, "CURRENT_USER": true,
+}
+
+// SchemaRows is the interface of the rows ScanSchema reads, implemented by *sql.Rows.
+type SchemaRows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// SchemaQuery returns the query listing the tables and columns of the current
+// schema (or database) from the catalog of the dialect, in (table, column) pairs
+// to be read by ScanSchema.
+// Ex:
+//
+//	q, _ := SchemaQuery(DialectPostgres)
+//	rows, _ := db.Query(q.MustSql())
+//	schema, _ := ScanSchema(rows)
+//	RegisterSchema(schema)
+func SchemaQuery(d Dialect) (SelectBuilder, error) {
+	columns := Select("table_name", "column_name").
+		From("information_schema.columns").
+		OrderBy("table_name", "ordinal_position").
+		Dialect(d)
+
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectDuckDB:
+		return columns.Where("table_schema = current_schema()"), nil
+	case DialectMySQL, DialectMariaDB:
+		return columns.Where("table_schema = DATABASE()"), nil
+	case DialectMSSQL:
+		return columns.Where("table_schema = SCHEMA_NAME()"), nil
+	case DialectSnowflake:
+		return columns.Where("table_schema = CURRENT_SCHEMA()"), nil
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		return Select("m.name", "p.name").
+			From("sqlite_master m").
+			Join("pragma_table_info(m.name) p").
+			Where("m.type = 'table'").
+			OrderBy("m.name", "p.cid").
+			Dialect(d), nil
+	case DialectOracle, DialectOracleLegacy:
+		return Select("table_name", "column_name").
+			From("user_tab_columns").
+			OrderBy("table_name", "column_id").
+			Dialect(d), nil
+	}
+	return SelectBuilder{}, d.unsupportedError("SchemaQuery")
+}
+
+// ScanSchema reads the (table, column) rows of the SchemaQuery query into a schema
+// for RegisterSchema. The rows are not closed.
+func ScanSchema(rows SchemaRows) (map[string][]string, error) {
+	schema := map[string][]string{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		schema[table] = append(schema[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// SchemaColumn is a column of a table read by ScanSchemaColumns or
+// ParseSchemaDDLColumns, with its SQL type, empty if unknown.
+type SchemaColumn struct {
+	Name string
+	Type string
+}
+
+// SchemaColumnsQuery is SchemaQuery with the SQL type of the columns, in
+// (table, column, type) rows to be read by ScanSchemaColumns.
+func SchemaColumnsQuery(d Dialect) (SelectBuilder, error) {
+	q, err := SchemaQuery(d)
+	if err != nil {
+		return q, err
+	}
+	if d == DialectSQLite || d == DialectSQLiteUpdateDeleteLimit {
+		return q.Column("p.type"), nil
+	}
+	return q.Column("data_type"), nil
+}
+
+// ScanSchemaColumns reads the (table, column, type) rows of the SchemaColumnsQuery
+// query into a schema for WriteGoTables. The rows are not closed.
+func ScanSchemaColumns(rows SchemaRows) (map[string][]SchemaColumn, error) {
+	schema := map[string][]SchemaColumn{}
+	for rows.Next() {
+		var table string
+		var column SchemaColumn
+		if err := rows.Scan(&table, &column.Name, &column.Type); err != nil {
+			return nil, err
+		}
+		schema[table] = append(schema[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// ddlConstraintKeywords start the items of CREATE TABLE which aren't columns.
+var ddlConstraintKeywords = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "FOREIGN": true, "CHECK": true,
+	"KEY": true, "INDEX": true, "FULLTEXT": true, "SPATIAL": true, "EXCLUDE": true, "LIKE": true,
+}
+
+// ddlColumnKeywords end the type of a column definition of CREATE TABLE.
+var ddlColumnKeywords = map[string]bool{
+	"NOT": true, "NULL": true, "PRIMARY": true, "UNIQUE": true, "DEFAULT": true, "CHECK": true,
+	"REFERENCES": true, "CONSTRAINT": true, "GENERATED": true, "COLLATE": true, "IDENTITY": true,
+	"AUTO_INCREMENT": true, "AUTOINCREMENT": true, "COMMENT": true, "AS": true,
+}
+
+// ParseSchemaDDL reads the tables and columns of the CREATE TABLE statements of
+// ddl, e.g. a migration or schema dump file, into a schema for RegisterSchema.
+// Other statements are ignored, and so are the schema parts of table names.
+func ParseSchemaDDL(ddl string) (map[string][]string, error) {
+	tables, err := ParseSchemaDDLColumns(ddl)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string][]string, len(tables))
+	for table, columns := range tables {
+		names := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = column.Name
+		}
+		schema[table] = names
+	}
+	return schema, nil
+}
+
+// ParseSchemaDDLColumns is ParseSchemaDDL with the SQL type of the columns, e.g.
+// "varchar(255)", into a schema for WriteGoTables.
+func ParseSchemaDDLColumns(ddl string) (map[string][]SchemaColumn, error) {
+	schema := map[string][]SchemaColumn{}
+
+	ddl = ddlStripComments(ddl)
+	for pos := 0; ; {
+		i := ddlIndexKeyword(ddl, pos, "CREATE ")
+		if i < 0 {
+			break
+		}
+		pos = i + len("CREATE ")
+
+		open := strings.IndexAny(ddl[pos:], "(;")
+		if open < 0 || ddl[pos+open] != '(' {
+			continue
+		}
+		words := strings.Fields(ddl[pos : pos+open])
+		tableIdx := -1
+		for j, word := range words {
+			if strings.EqualFold(word, "TABLE") {
+				tableIdx = j + 1
+				break
+			}
+		}
+		if tableIdx < 0 {
+			continue
+		}
+		for tableIdx < len(words) && ddlModifier(words[tableIdx]) {
+			tableIdx++
+		}
+		if tableIdx >= len(words) {
+			return nil, fmt.Errorf("missing table name in CREATE TABLE statement")
+		}
+		if tableIdx != len(words)-1 {
+			// CREATE TABLE ... AS SELECT, PARTITION OF, ...
+			continue
+		}
+		table := ddlIdentifier(words[tableIdx])
+		if j := strings.LastIndex(table, "."); j >= 0 {
+			table = table[j+1:]
+		}
+
+		body, end, err := ddlParenthesized(ddl, pos+open)
+		if err != nil {
+			return nil, fmt.Errorf("CREATE TABLE %s: %w", table, err)
+		}
+		pos = end
+
+		columns := []SchemaColumn{}
+		for _, item := range ddlSplit(body) {
+			fields := strings.Fields(item)
+			if len(fields) == 0 || ddlConstraintKeywords[strings.ToUpper(fields[0])] {
+				continue
+			}
+			columns = append(columns, SchemaColumn{Name: ddlIdentifier(fields[0]), Type: ddlColumnType(item)})
+		}
+		schema[table] = columns
+	}
+
+	return schema, nil
+}
+
+// ddlStripComments replaces the -- and /* */ comments of ddl, outside of quotes,
+// with a space.
+func ddlStripComments(ddl string) string {
+	if !strings.Contains(ddl, "--") && !strings.Contains(ddl, "/*") {
+		return ddl
+	}
+
+	buf := &strings.Builder{}
+	var quote byte
+	for i := 0; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case strings.HasPrefix(ddl[i:], "--"):
+			end := strings.IndexByte(ddl[i:], '\n')
+			if end < 0 {
+				end = len(ddl) - i
+			}
+			i += end - 1
+			c = ' '
+		case strings.HasPrefix(ddl[i:], "/*"):
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				end = len(ddl) - i - 4
+			}
+			i += end + 3
+			c = ' '
+		}
+		_ = buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// ddlIndexKeyword returns the index of the first occurrence of keyword in
+// ddl[from:], matched case-insensitively as a whole word, or -1. The offsets
+// are those of ddl, unlike those of strings.ToUpper(ddl) when it has runes whose
+// upper case has another length.
+func ddlIndexKeyword(ddl string, from int, keyword string) int {
+	for i := from; i+len(keyword) <= len(ddl); i++ {
+		if strings.EqualFold(ddl[i:i+len(keyword)], keyword) && (i == 0 || !isIdentifierChar(ddl[i-1])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ddlColumnType returns the type of the column definition item, the words after
+// the column name up to the constraints.
+func ddlColumnType(item string) string {
+	words := ddlWords(item)
+	end := 1
+	for end < len(words) && !ddlColumnKeywords[strings.ToUpper(words[end])] {
+		end++
+	}
+	if end <= 1 {
+		return ""
+	}
+	return strings.Join(words[1:end], " ")
+}
+
+// ddlWords splits item on the spaces outside of parentheses, so that e.g.
+// numeric(10, 2) is one word.
+func ddlWords(item string) []string {
+	var words []string
+	depth, start := 0, -1
+	for i := 0; i < len(item); i++ {
+		switch c := item[i]; {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			if start >= 0 {
+				words = append(words, item[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, item[start:])
+	}
+	return words
+}
+
+// ddlModifier reports whether word is part of IF NOT EXISTS.
+func ddlModifier(word string) bool {
+	switch strings.ToUpper(word) {
+	case "IF", "NOT", "EXISTS":
+		return true
+	}
+	return false
+}
+
+// ddlIdentifier removes the quotes of an identifier.
+func ddlIdentifier(name string) string {
+	return strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(name)
+}
+
+// ddlParenthesized returns the text inside the parentheses opening at ddl[open]
+// and the position after the closing one.
+func ddlParenthesized(ddl string, open int) (string, int, error) {
+	depth := 0
+	var quote byte
+	for i := open; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return ddl[open+1 : i], i + 1, nil
+			}
+		}
+	}
+	return "", 0, fmt.Errorf("unbalanced parentheses")
+}
+
+// ddlSplit splits the items of a CREATE TABLE body on top-level commas.
+func ddlSplit(body string) []string {
+	var items []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, body[start:])
+}
+
+// schemaScope resolves the tables and columns of one statement against the
+// registered schema.
+type schemaScope struct {
+	schema  map[string]map[string]bool
+	refs    []tableRef
+	aliases map[string]bool // select list aliases, usable in GROUP BY and ORDER BY
+
+	// unresolved is set when a FROM or JOIN source couldn't be parsed, e.g. a
+	// raw JOIN on a subquery: the columns which may come from it aren't checked.
+	unresolved bool
+}
+
+func newSchemaScope() (*schemaScope, error) {
+	schema, _ := registeredSchema.Load().(map[string]map[string]bool)
+	if schema == nil {
+		return nil, fmt.Errorf("no schema registered, see RegisterSchema")
+	}
+	return &schemaScope{schema: schema, aliases: map[string]bool{}}, nil
+}
+
+// addSource adds the tables of a FROM or JOIN clause to the scope.
+func (s *schemaScope) addSource(source Sqlizer) error {
+	refs := tableRefs(source)
+	if len(refs) == 0 {
+		s.unresolved = true
+	}
+	return s.addTables(refs...)
+}
+
+// addTables adds tables to the scope. The schema of schema-qualified tables, e.g.
+// public.users, is dropped unless the qualified name is registered.
+func (s *schemaScope) addTables(refs ...tableRef) error {
+	for _, ref := range refs {
+		if len(ref.table) > 0 {
+			if _, ok := s.schema[strings.ToLower(ref.table)]; !ok {
+				table := ref.table
+				if i := strings.LastIndex(table, "."); i >= 0 {
+					table = table[i+1:]
+				}
+				if _, ok := s.schema[strings.ToLower(table)]; !ok {
+					return fmt.Errorf("unknown table %s", ref.table)
+				}
+				if ref.name == ref.table {
+					ref.name = table
+				}
+				ref.table = table
+			}
+		}
+		s.refs = append(s.refs, ref)
+	}
+	return nil
+}
+
+// checkColumn checks a plain, possibly qualified, column. Anything else (expressions,
+// placeholders, stars) is ignored.
+func (s *schemaScope) checkColumn(column string) error {
+	column = strings.TrimSpace(column)
+	m := simpleColumnRegexp.FindStringSubmatch(column)
+	if m == nil || len(m[2]) > 0 {
+		return nil
+	}
+	column = m[1]
+	if sqlKeywords[strings.ToUpper(column)] {
+		return nil
+	}
+
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		qualifier, name := column[:i], column[i+1:]
+		if j := strings.LastIndex(qualifier, "."); j >= 0 {
+			qualifier = qualifier[j+1:] // schema.table.column
+		}
+
+		for _, ref := range s.refs {
+			if !strings.EqualFold(ref.name, qualifier) {
+				continue
+			}
+			if len(ref.table) == 0 || s.schema[strings.ToLower(ref.table)][strings.ToLower(name)] {
+				return nil
+			}
+			return fmt.Errorf("unknown column %s in table %s", name, ref.table)
+		}
+		if s.unresolved {
+			return nil
+		}
+		return fmt.Errorf("unknown table or alias %s in column %s", qualifier, column)
+	}
+
+	if s.aliases[strings.ToLower(column)] {
+		return nil
+	}
+	if s.unresolved {
+		return nil
+	}
+	for _, ref := range s.refs {
+		if len(ref.table) == 0 || s.schema[strings.ToLower(ref.table)][strings.ToLower(column)] {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown column %s", column)
+}
+
+// checkPredicates checks the columns of the map-based predicates (Eq, Lt, Like, ...)
+// and of In / NotIn, also when nested into And / Or.
+func (s *schemaScope) checkPredicates(preds []Sqlizer) error {
+	for _, pred := range preds {
+		var columns []string
+		switch p := pred.(type) {
+		case *wherePart:
+			if m, ok := p.pred.(map[string]any); ok {
+				columns = getSortedKeys(m)
+			} else if sqlizer, ok := p.pred.(Sqlizer); ok {
+				if err := s.checkPredicates([]Sqlizer{sqlizer}); err != nil {
+					return err
+				}
+			}
+		case And:
+			if err := s.checkPredicates(p); err != nil {
+				return err
+			}
+		case Or:
+			if err := s.checkPredicates(p); err != nil {
+				return err
+			}
+		case Eq:
+			columns = getSortedKeys(p)
+		case NotEq:
+			columns = getSortedKeys(p)
+		case EqNotEmpty:
+			columns = getSortedKeys(p)
+		case Like:
+			columns = getSortedKeys(p)
+		case NotLike:
+			columns = getSortedKeys(p)
+		case ILike:
+			columns = getSortedKeys(p)
+		case NotILike:
+			columns = getSortedKeys(p)
+		case Lt:
+			columns = getSortedKeys(p)
+		case LtOrEq:
+			columns = getSortedKeys(p)
+		case Gt:
+			columns = getSortedKeys(p)
+		case GtOrEq:
+			columns = getSortedKeys(p)
+		case inExpr:
+			columns = []string{p.column}
+		case notInExpr:
+			columns = []string{p.column}
+		}
+
+		for _, column := range columns {
+			if err := s.checkColumn(column); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the tables and columns referenced by the query against the
+// schema registered with RegisterSchema. Only plain references are checked:
+// table names in From and Join, plain columns of the select list, GROUP BY and
+// ORDER BY, and the columns of map-based predicates (Eq, Lt, Like, ...) and In.
+// Subqueries and raw SQL fragments are not inspected, nor are the columns which
+// may come from a FROM or JOIN source that can't be parsed, e.g. a raw JOIN on
+// a subquery. The schema of schema-qualified names (public.users.id) is ignored,
+// and CTE names have to be registered to be used as tables.
+func (b SelectBuilder) Validate() error {
+	d := b.get()
+
+	s, err := newSchemaScope()
+	if err != nil {
+		return err
+	}
+
+	if d.From != nil {
+		if err := s.addSource(d.From); err != nil {
+			return err
+		}
+	}
+	for _, join := range d.Joins {
+		if err := s.addSource(join); err != nil {
+			return err
+		}
+	}
+
+	for _, column := range d.Columns {
+		p, ok := column.(*part)
+		if !ok {
+			continue
+		}
+		str, ok := p.pred.(string)
+		if !ok {
+			continue
+		}
+		if m := simpleColumnRegexp.FindStringSubmatch(strings.TrimSpace(str)); m != nil {
+			if len(m[2]) > 0 {
+				s.aliases[strings.ToLower(m[2])] = true
+			}
+			if err := s.checkColumn(m[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, groupBy := range d.GroupBys {
+		for _, column := range strings.Split(groupBy, ",") {
+			if err := s.checkColumn(column); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, orderBy := range d.OrderByParts {
+		p, ok := orderBy.(*part)
+		if !ok {
+			continue
+		}
+		str, ok := p.pred.(string)
+		if !ok {
+			continue
+		}
+		for _, column := range strings.Split(str, ",") {
+			fields := strings.Fields(column)
+			if len(fields) == 2 && (strings.EqualFold(fields[1], "ASC") || strings.EqualFold(fields[1], "DESC")) {
+				column = fields[0]
+			}
+			if err := s.checkColumn(column); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.checkPredicates(d.WhereParts); err != nil {
+		return err
+	}
+	return s.checkPredicates(d.HavingParts)
+}
+
+// Validate checks the table and columns of the query against the schema
+// registered with RegisterSchema.
+func (b InsertBuilder) Validate() error {
+	d := b.get()
+
+	s, err := newSchemaScope()
+	if err != nil {
+		return err
+	}
+
+	if err := s.addTables(tableNames(d.Into)...); err != nil {
+		return err
+	}
+	for _, column := range d.Columns {
+		if err := s.checkColumn(column); err != nil {
+			return err
+		}
+	}
+
+	if d.Select != nil {
+		return d.Select.Validate()
+	}
+	return nil
+}
+
+// Validate checks the tables and columns of the query against the schema
+// registered with RegisterSchema. See SelectBuilder.Validate for what is checked.
+func (b UpdateBuilder) Validate() error {
+	d := b.get()
+
+	s, err := newSchemaScope()
+	if err != nil {
+		return err
+	}
+
+	if err := s.addTables(tableNames(d.Table)...); err != nil {
+		return err
+	}
+	if d.From != nil {
+		if err := s.addSource(d.From); err != nil {
+			return err
+		}
+	}
+
+	for _, set := range d.SetClauses {
+		if err := s.checkColumn(set.column); err != nil {
+			return err
+		}
+	}
+
+	return s.checkPredicates(d.WhereParts)
+}
+
+// Validate checks the table and columns of the query against the schema
+// registered with RegisterSchema. See SelectBuilder.Validate for what is checked.
+func (b DeleteBuilder) Validate() error {
+	d := b.get()
+
+	s, err := newSchemaScope()
+	if err != nil {
+		return err
+	}
+
+	if err := s.addTables(tableNames(d.From)...); err != nil {
+		return err
+	}
+
+	return s.checkPredicates(d.WhereParts)
+}
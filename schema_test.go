@@ -0,0 +1,225 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func registerTestSchema(t *testing.T) {
+	RegisterSchema(map[string][]string{
+		"users": {"id", "name", "org_id", "created_at"},
+		"orgs":  {"id", "name"},
+	})
+	t.Cleanup(func() {
+		registeredSchema.Store(map[string]map[string]bool(nil))
+	})
+}
+
+func TestValidateWithoutSchema(t *testing.T) {
+	assert.Error(t, Select("id").From("users").Validate())
+}
+
+func TestSelectBuilderValidate(t *testing.T) {
+	registerTestSchema(t)
+
+	b := Select("u.id", "u.name AS user_name", "o.name", "COUNT(*)").
+		From("users u").
+		LeftJoin("orgs o ON o.id = u.org_id").
+		Where(Eq{"u.org_id": 1}).
+		Where(Or{Like{"o.name": "a%"}, Gt{"created_at": "2024-01-01"}}).
+		GroupBy("u.id", "u.name", "o.name").
+		OrderBy("user_name DESC")
+	assert.NoError(t, b.Validate())
+
+	assert.EqualError(t, b.Column("u.email").Validate(), "unknown column email in table users")
+	assert.EqualError(t, b.Where(Eq{"email": "x"}).Validate(), "unknown column email")
+	assert.EqualError(t, b.Where(In("x.id", []int{1})).Validate(), "unknown table or alias x in column x.id")
+	assert.EqualError(t, Select("id").From("accounts").Validate(), "unknown table accounts")
+
+	// subqueries and expressions are not inspected
+	assert.NoError(t, Select("s.anything", "LOWER(name)").
+		FromSelect(Select("1").From("whatever"), "s").
+		Where("whatever = ?", 1).Validate())
+}
+
+func TestSelectBuilderValidateUnresolvedSource(t *testing.T) {
+	registerTestSchema(t)
+
+	b := Select("u.id", "x.total", "total").From("users u").
+		Join("(SELECT user_id, SUM(amount) AS total FROM payments GROUP BY user_id) x ON x.user_id = u.id").
+		Where(Gt{"x.total": 10})
+	assert.NoError(t, b.Validate())
+
+	// the known tables are still checked
+	assert.EqualError(t, b.Column("u.email").Validate(), "unknown column email in table users")
+}
+
+func TestSelectBuilderValidateSchemaQualified(t *testing.T) {
+	registerTestSchema(t)
+
+	b := Select("public.users.id", "users.name", "o.name").From("public.users").
+		Join("public.orgs o ON o.id = users.org_id").
+		Where(Eq{"public.users.org_id": 1})
+	assert.NoError(t, b.Validate())
+
+	assert.EqualError(t, b.Column("public.users.email").Validate(), "unknown column email in table users")
+	assert.EqualError(t, Select("id").From("public.accounts").Validate(), "unknown table public.accounts")
+}
+
+func TestInsertBuilderValidate(t *testing.T) {
+	registerTestSchema(t)
+
+	assert.NoError(t, Insert("users").Columns("id", "name").Values(1, "John").Validate())
+	assert.EqualError(t, Insert("users").Columns("id", "nmae").Values(1, "John").Validate(), "unknown column nmae")
+	assert.EqualError(t, Insert("users").Columns("id").Select(Select("id").From("accounts")).Validate(), "unknown table accounts")
+}
+
+func TestUpdateBuilderValidate(t *testing.T) {
+	registerTestSchema(t)
+
+	b := Update("users").Set("name", "John").Where(Eq{"id": 1})
+	assert.NoError(t, b.Validate())
+	assert.EqualError(t, b.Set("email", "x").Validate(), "unknown column email")
+
+	assert.NoError(t, Update("users u").From("orgs o").Set("name", Expr("o.name")).Where(Eq{"o.id": 1}).Validate())
+}
+
+func TestDeleteBuilderValidate(t *testing.T) {
+	registerTestSchema(t)
+
+	assert.NoError(t, Delete("users").Where(Eq{"id": 1}).Validate())
+	assert.EqualError(t, Delete("users").Where(NotEq{"deleted": true}).Validate(), "unknown column deleted")
+	assert.EqualError(t, Delete("accounts").Validate(), "unknown table accounts")
+}
+
+type fakeSchemaRows struct {
+	rows [][]string
+	pos  int
+}
+
+func (r *fakeSchemaRows) Next() bool {
+	r.pos++
+	return r.pos <= len(r.rows)
+}
+
+func (r *fakeSchemaRows) Scan(dest ...any) error {
+	for i := range dest {
+		*dest[i].(*string) = r.rows[r.pos-1][i]
+	}
+	return nil
+}
+
+func (r *fakeSchemaRows) Err() error {
+	return nil
+}
+
+func TestSchemaQuery(t *testing.T) {
+	q, err := SchemaQuery(DialectPostgres)
+	assert.NoError(t, err)
+	sql, _, err := q.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT table_name, column_name FROM information_schema.columns "+
+		"WHERE table_schema = current_schema() ORDER BY table_name, ordinal_position", sql)
+
+	q, err = SchemaQuery(DialectSQLite)
+	assert.NoError(t, err)
+	sql, _, err = q.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT m.name, p.name FROM sqlite_master m JOIN pragma_table_info(m.name) p "+
+		"WHERE m.type = 'table' ORDER BY m.name, p.cid", sql)
+
+	_, err = SchemaQuery(DialectClickHouse)
+	assert.Error(t, err)
+}
+
+func TestScanSchema(t *testing.T) {
+	schema, err := ScanSchema(&fakeSchemaRows{rows: [][]string{
+		{"users", "id"}, {"users", "name"}, {"orgs", "id"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"users": {"id", "name"}, "orgs": {"id"}}, schema)
+}
+
+func TestParseSchemaDDL(t *testing.T) {
+	ddl := `
+CREATE TABLE IF NOT EXISTS public.users (
+	id bigint PRIMARY KEY,
+	"name" text NOT NULL DEFAULT 'a, b',
+	price numeric(10, 2),
+	org_id bigint REFERENCES orgs (id),
+	CONSTRAINT users_name_key UNIQUE (name)
+);
+CREATE INDEX users_org_idx ON users (org_id);
+CREATE TABLE ` + "`orgs`" + ` (id INT, name VARCHAR(64), PRIMARY KEY (id));
+CREATE TABLE copy AS SELECT count(*) FROM users;
+`
+	schema, err := ParseSchemaDDL(ddl)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"users": {"id", "name", "price", "org_id"},
+		"orgs":  {"id", "name"},
+	}, schema)
+
+	_, err = ParseSchemaDDL("CREATE TABLE t (id int")
+	assert.Error(t, err)
+}
+
+func TestSchemaColumnsQuery(t *testing.T) {
+	q, err := SchemaColumnsQuery(DialectMySQL)
+	assert.NoError(t, err)
+	sql, _, err := q.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT table_name, column_name, data_type FROM information_schema.columns "+
+		"WHERE table_schema = DATABASE() ORDER BY table_name, ordinal_position", sql)
+
+	q, err = SchemaColumnsQuery(DialectSQLite)
+	assert.NoError(t, err)
+	sql, _, err = q.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT m.name, p.name, p.type FROM sqlite_master m JOIN pragma_table_info(m.name) p "+
+		"WHERE m.type = 'table' ORDER BY m.name, p.cid", sql)
+}
+
+func TestScanSchemaColumns(t *testing.T) {
+	schema, err := ScanSchemaColumns(&fakeSchemaRows{rows: [][]string{
+		{"users", "id", "bigint"}, {"users", "name", "text"}, {"orgs", "id", "integer"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]SchemaColumn{
+		"users": {{"id", "bigint"}, {"name", "text"}},
+		"orgs":  {{"id", "integer"}},
+	}, schema)
+}
+
+func TestParseSchemaDDLColumns(t *testing.T) {
+	schema, err := ParseSchemaDDLColumns(`
+CREATE TABLE users (
+	id bigint PRIMARY KEY,
+	email character varying(255) NOT NULL UNIQUE,
+	price numeric(10, 2) DEFAULT 0,
+	tags text[],
+	created_at timestamp with time zone,
+	CONSTRAINT users_email_key UNIQUE (email)
+);`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]SchemaColumn{"users": {
+		{"id", "bigint"},
+		{"email", "character varying(255)"},
+		{"price", "numeric(10, 2)"},
+		{"tags", "text[]"},
+		{"created_at", "timestamp with time zone"},
+	}}, schema)
+}
+
+func TestParseSchemaDDLNonASCII(t *testing.T) {
+	schema, err := ParseSchemaDDL(`-- ıııı ɐɐɐɐ: the upper case of these runes is shorter or longer
+/* CREATE TABLE old (id int); */
+-- CREATE TABLE older (id int);
+create table "ɐccounts" (
+	id int, -- the ıd
+	name text /* the name, ok */
+);`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"ɐccounts": {"id", "name"}}, schema)
+}
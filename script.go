@@ -0,0 +1,130 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ScriptError is returned by Script.ToSql when one of the statements fails to build.
+type ScriptError struct {
+	Index int // zero-based position of the statement
+	Err   error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("script statement %d: %s", e.Index, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// scriptExpr helps to run several statements at once
+type scriptExpr struct {
+	stmts             []Sqlizer
+	transaction       bool
+	dialect           Dialect
+	placeholderFormat PlaceholderFormat
+}
+
+// Script allows to join several statements into one multi-statement query, e.g. for
+// fixture loading or small migrations. Placeholders are numbered across the whole
+// script, but few drivers bind args to a multi-statement query: PostgreSQL (the
+// extended protocol) and MySQL prepared statements reject it. Pass the script
+// through InlineArgs then, or use a driver mode sending it with the simple protocol.
+// Ex: Script(Insert("a").Values(1), Update("b").Set("c", 2)) -> "INSERT INTO a VALUES (?); UPDATE b SET c = ?"
+func Script(stmts ...Sqlizer) scriptExpr {
+	return scriptExpr{stmts: stmts}
+}
+
+// Transaction wraps the statements into a transaction (an anonymous block for Oracle).
+func (s scriptExpr) Transaction() scriptExpr {
+	s.transaction = true
+	return s
+}
+
+// Dialect sets the dialect used to join the statements.
+// The placeholder format preferred by the dialect is set as well.
+func (s scriptExpr) Dialect(d Dialect) scriptExpr {
+	s.dialect = d
+	if f := d.PlaceholderFormat(); f != nil {
+		s.placeholderFormat = f
+	}
+	return s
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the script.
+func (s scriptExpr) PlaceholderFormat(f PlaceholderFormat) scriptExpr {
+	s.placeholderFormat = f
+	return s
+}
+
+func (s scriptExpr) toSqlRaw() (string, []any, error) {
+	if len(s.stmts) == 0 {
+		return "", nil, fmt.Errorf("script must have at least one statement")
+	}
+	if s.dialect == DialectClickHouse {
+		return "", nil, s.dialect.unsupportedError("multi-statement script")
+	}
+
+	begin, commit := "BEGIN; ", "; COMMIT"
+	switch s.dialect { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB:
+		begin = "START TRANSACTION; "
+	case DialectMSSQL, DialectBigQuery:
+		begin, commit = "BEGIN TRANSACTION; ", "; COMMIT TRANSACTION"
+	}
+
+	sql := &bytes.Buffer{}
+	var args []any
+
+	oracle := s.dialect.isOracle()
+	if oracle {
+		// Oracle runs a single statement per call, multiple ones need an anonymous block
+		_, _ = sql.WriteString("BEGIN ")
+	} else if s.transaction {
+		_, _ = sql.WriteString(begin)
+	}
+
+	for i, stmt := range s.stmts {
+		stmtSql, stmtArgs, err := nestedToSql(stmt)
+		if err != nil {
+			return "", nil, &ScriptError{Index: i, Err: err}
+		}
+
+		if i > 0 {
+			if oracle {
+				_, _ = sql.WriteString(" ")
+			} else {
+				_, _ = sql.WriteString("; ")
+			}
+		}
+		_, _ = sql.WriteString(stmtSql)
+		if oracle {
+			_, _ = sql.WriteString(";")
+		}
+		args = append(args, stmtArgs...)
+	}
+
+	if oracle {
+		if s.transaction {
+			_, _ = sql.WriteString(" COMMIT;")
+		}
+		_, _ = sql.WriteString(" END;")
+	} else if s.transaction {
+		_, _ = sql.WriteString(commit)
+	}
+
+	return sql.String(), args, nil
+}
+
+// ToSql builds the script into a SQL string and bound args.
+func (s scriptExpr) ToSql() (string, []any, error) {
+	sql, args, err := s.toSqlRaw()
+	if err != nil || s.placeholderFormat == nil {
+		return sql, args, err
+	}
+
+	sql, err = s.placeholderFormat.ReplacePlaceholders(sql)
+	return sql, args, err
+}
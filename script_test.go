@@ -0,0 +1,47 @@
+package squirrel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScript(t *testing.T) {
+	s := Script(
+		Insert("users").Columns("id", "name").Values(1, "John"),
+		Update("counters").Set("n", Expr("n + ?", 1)).Where(Eq{"name": "users"}),
+	)
+
+	sql, args, err := s.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (?,?); UPDATE counters SET n = n + ? WHERE name = ?", sql)
+	assert.Equal(t, []any{1, "John", 1, "users"}, args)
+
+	sql, _, err = s.Transaction().Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "BEGIN; INSERT INTO users (id,name) VALUES ($1,$2); UPDATE counters SET n = n + $3 WHERE name = $4; COMMIT", sql)
+
+	sql, _, err = s.Transaction().Dialect(DialectOracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "BEGIN INSERT INTO users (id,name) VALUES (:1,:2); UPDATE counters SET n = n + :3 WHERE name = :4; COMMIT; END;", sql)
+
+	// for the drivers which can't bind the args of a multi-statement query
+	sql, err = InlineArgs(s.Transaction().Dialect(DialectPostgres), DialectPostgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "BEGIN; INSERT INTO users (id,name) VALUES (1,'John'); UPDATE counters SET n = n + 1 WHERE name = 'users'; COMMIT", sql)
+}
+
+func TestScriptErrors(t *testing.T) {
+	_, _, err := Script().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Script(Insert("a").Values(1), Delete("")).ToSql()
+	var scriptErr *ScriptError
+	if assert.True(t, errors.As(err, &scriptErr)) {
+		assert.Equal(t, 1, scriptErr.Index)
+	}
+
+	_, _, err = Script(Insert("a").Values(1)).Dialect(DialectClickHouse).ToSql()
+	assert.Error(t, err)
+}
@@ -2,12 +2,16 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
-
-	"github.com/lann/builder"
 )
 
 // Direction is used in OrderByDir to specify the direction of the ordering.
@@ -91,79 +95,220 @@ type OrderCond struct {
 	Direction Direction
 }
 
+// SelectOption is a known option of the SELECT statement, see SelectBuilder.Option.
+type SelectOption string
+
+const (
+	OptionDistinct SelectOption = "DISTINCT"
+	OptionAll      SelectOption = "ALL"
+
+	// MySQL only
+	OptionHighPriority     SelectOption = "HIGH_PRIORITY"
+	OptionStraightJoin     SelectOption = "STRAIGHT_JOIN"
+	OptionSQLSmallResult   SelectOption = "SQL_SMALL_RESULT"
+	OptionSQLBigResult     SelectOption = "SQL_BIG_RESULT"
+	OptionSQLBufferResult  SelectOption = "SQL_BUFFER_RESULT"
+	OptionSQLNoCache       SelectOption = "SQL_NO_CACHE"
+	OptionSQLCalcFoundRows SelectOption = "SQL_CALC_FOUND_ROWS"
+)
+
+var mysqlSelectOptions = map[SelectOption]bool{
+	OptionHighPriority:     true,
+	OptionStraightJoin:     true,
+	OptionSQLSmallResult:   true,
+	OptionSQLBigResult:     true,
+	OptionSQLBufferResult:  true,
+	OptionSQLNoCache:       true,
+	OptionSQLCalcFoundRows: true,
+}
+
 type selectData struct {
-	PlaceholderFormat PlaceholderFormat
-	Prefixes          []Sqlizer
-	Options           []string
-	Columns           []Sqlizer
-	From              Sqlizer
-	Joins             []Sqlizer
-	WhereParts        []Sqlizer
-	GroupBys          []string
-	HavingParts       []Sqlizer
-	OrderByParts      []Sqlizer
-	Limit             string
-	Offset            string
-	Suffixes          []Sqlizer
-	Paginator         Paginator
-	IDColumn          string // ID column name. Required for pagination by ID.
+	PlaceholderFormat   PlaceholderFormat
+	Dialect             Dialect
+	TimeLocation        *time.Location
+	SizeLimit           SizeLimit
+	Prefixes            []Sqlizer
+	FromFirst           bool
+	Options             []string
+	Columns             []Sqlizer
+	From                Sqlizer
+	AsOf                *asOfClause
+	Final               bool
+	Sample              string
+	Joins               []Sqlizer
+	WhereParts          []Sqlizer
+	GroupByAll          bool
+	GroupBys            []string
+	StrictGroupBy       bool
+	HavingParts         []Sqlizer
+	QualifyParts        []Sqlizer
+	OrderByParts        []Sqlizer
+	LimitBy             string
+	Limit               string
+	LimitExpr           Sqlizer
+	DefaultLimit        string
+	Unlimited           bool
+	Offset              string
+	OffsetExpr          Sqlizer
+	WithTies            bool
+	Settings            []string
+	Suffixes            []Sqlizer
+	Paginator           Paginator
+	IDColumn            string // ID column name. Required for pagination by ID.
+	Keyset              *keysetPage
+	Err                 error // first error recorded by a builder method
+	RejectNilPredicates bool
+	MaxParts            int
+	ctx                 context.Context // set by ToSqlContext
+	size                *sizeTracker    // set by ToSql
+}
+
+// withDefaultLimit returns d with the DefaultLimit as LIMIT, if it applies.
+// Only top level statements are limited: subqueries and CTE bodies are rendered
+// with toSqlRaw, which ignores it.
+func (d *selectData) withDefaultLimit() *selectData {
+	if len(d.DefaultLimit) > 0 && len(d.Limit) == 0 && d.LimitExpr == nil && !d.Unlimited &&
+		d.Paginator.pType == PaginatorTypeUndefined {
+		limited := *d
+		limited.Limit = d.DefaultLimit
+		return &limited
+	}
+	return d
 }
 
 func (d *selectData) ToSql() (sqlStr string, args []any, err error) {
+	d = d.withDefaultLimit()
+	d.size = newSizeTracker(d.SizeLimit)
 	sqlStr, args, err = d.toSqlRaw()
 	if err != nil {
 		return
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	if a := enabledIndexAdvisor(); a != nil {
+		a.observe(d)
+	}
+
+	args = bindArgs(normalizeTimes(args, d.TimeLocation))
+	if d.PlaceholderFormat != nil { // zero value builder
+		sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	}
+	if err == nil {
+		err = d.size.check(len(sqlStr), len(args))
+	}
 	return
 }
 
+// checkBuild returns the error of the ToSqlContext context if it is done, or a
+// TooManyPartsError if a clause has more parts than MaxParts.
+func (d *selectData) checkBuild() error {
+	if err := ctxErr(d.ctx); err != nil {
+		return err
+	}
+	if err := checkParts("SELECT", len(d.Columns), d.MaxParts); err != nil {
+		return err
+	}
+	if err := checkParts("JOIN", len(d.Joins), d.MaxParts); err != nil {
+		return err
+	}
+	if err := checkParts("WHERE", len(d.WhereParts), d.MaxParts); err != nil {
+		return err
+	}
+	if err := checkParts("HAVING", len(d.HavingParts), d.MaxParts); err != nil {
+		return err
+	}
+	return checkParts("ORDER BY", len(d.OrderByParts), d.MaxParts)
+}
+
 func (d *selectData) toSqlRaw() (sqlStr string, args []any, err error) {
-	if len(d.Columns) == 0 {
+	if d.Err != nil {
+		return "", nil, d.Err
+	}
+	if err = d.checkBuild(); err != nil {
+		return "", nil, err
+	}
+
+	if len(d.Columns) == 0 && (!d.FromFirst || d.From == nil) {
 		err = fmt.Errorf("select statements must have at least one result column")
 		return "", nil, err
 	}
 
+	if err = d.validateDialect(); err != nil {
+		return "", nil, err
+	}
+
+	if d.Dialect == DialectOracleLegacy &&
+		(len(d.Limit) > 0 || len(d.Offset) > 0 || d.Paginator.pType != PaginatorTypeUndefined) {
+		return d.rownumToSqlRaw()
+	}
+
+	if d.Dialect != DialectDefault && d.Dialect != DialectPostgres && d.hasJoinUnnest() {
+		return d.joinUnnestFallbackToSqlRaw()
+	}
+
+	if len(d.QualifyParts) > 0 && !d.Dialect.supportsQualify() {
+		return d.qualifyFallbackToSqlRaw()
+	}
+
 	sql := &bytes.Buffer{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("prefix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 
 		_, _ = sql.WriteString(" ")
 	}
 
-	_, _ = sql.WriteString("SELECT ")
+	if d.FromFirst {
+		// DuckDB: FROM t [SELECT ...]
+		from := &bytes.Buffer{}
+		args, err = d.appendFromToSql(from, args)
+		if err != nil {
+			return "", nil, err
+		}
+		_, _ = sql.WriteString(strings.TrimPrefix(from.String(), " "))
+		if err = d.size.clause("FROM", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 
-	if len(d.Options) > 0 {
-		_, _ = sql.WriteString(strings.Join(d.Options, " "))
-		_, _ = sql.WriteString(" ")
+		if len(d.Columns) > 0 {
+			_, _ = sql.WriteString(" ")
+		}
 	}
 
 	if len(d.Columns) > 0 {
-		args, err = appendToSql(d.Columns, sql, ", ", args)
-		if err != nil {
-			return "", nil, err
+		_, _ = sql.WriteString("SELECT ")
+
+		if len(d.Options) > 0 {
+			_, _ = sql.WriteString(strings.Join(d.Options, " "))
+			_, _ = sql.WriteString(" ")
 		}
-	}
 
-	if d.From != nil {
-		_, _ = sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		args, err = appendToSqlContext(d.ctx, d.Columns, sql, ", ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("SELECT", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 	}
 
-	if len(d.Joins) > 0 {
-		_, _ = sql.WriteString(" ")
-		args, err = appendToSql(d.Joins, sql, " ", args)
+	if !d.FromFirst {
+		args, err = d.appendFromToSql(sql, args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("FROM", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if d.From == nil && d.Dialect.isOracle() {
+		_, _ = sql.WriteString(" FROM DUAL")
 	}
 
 	whereParts := make([]Sqlizer, len(d.WhereParts))
@@ -177,82 +322,837 @@ func (d *selectData) toSqlRaw() (sqlStr string, args []any, err error) {
 		whereParts = append(whereParts, Gt{d.IDColumn: d.Paginator.lastID})
 	}
 
+	if d.Keyset != nil {
+		pred, err := d.keysetPredicate()
+		if err != nil {
+			return "", nil, err
+		}
+		if pred != nil {
+			whereParts = append(whereParts, pred)
+		}
+	}
+
 	if len(whereParts) > 0 {
 		_, _ = sql.WriteString(" WHERE ")
-		args, err = appendToSql(whereParts, sql, " AND ", args)
+		args, err = appendToSqlContext(d.ctx, whereParts, sql, " AND ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("WHERE", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 	}
 
-	if len(d.GroupBys) > 0 {
+	if d.GroupByAll {
+		_, _ = sql.WriteString(" GROUP BY ALL")
+	} else if len(d.GroupBys) > 0 {
 		_, _ = sql.WriteString(" GROUP BY ")
 		_, _ = sql.WriteString(strings.Join(d.GroupBys, ", "))
 	}
 
 	if len(d.HavingParts) > 0 {
 		_, _ = sql.WriteString(" HAVING ")
-		args, err = appendToSql(d.HavingParts, sql, " AND ", args)
+		args, err = appendToSqlContext(d.ctx, d.HavingParts, sql, " AND ", args)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = d.size.clause("HAVING", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(d.QualifyParts) > 0 {
+		_, _ = sql.WriteString(" QUALIFY ")
+		args, err = appendToSqlContext(d.ctx, d.QualifyParts, sql, " AND ", args)
 		if err != nil {
 			return "", nil, err
 		}
 	}
 
+	if len(d.Sample) > 0 && d.Dialect == DialectDuckDB {
+		_, _ = sql.WriteString(" USING SAMPLE ")
+		_, _ = sql.WriteString(d.Sample)
+	}
+
 	if len(d.OrderByParts) > 0 {
 		_, _ = sql.WriteString(" ORDER BY ")
-		args, err = appendToSql(d.OrderByParts, sql, ", ", args)
+		args, err = appendToSqlContext(d.ctx, orderByWithDialect(d.OrderByParts, d.Dialect), sql, ", ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("ORDER BY", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(d.LimitBy) > 0 {
+		_, _ = sql.WriteString(" LIMIT ")
+		_, _ = sql.WriteString(d.LimitBy)
+	}
+
+	limit, offset, err := d.limitOffset()
+	if err != nil {
+		return "", nil, err
 	}
 
-	if len(d.Limit) > 0 {
-		if d.Paginator.pType != PaginatorTypeUndefined {
-			return "", nil, fmt.Errorf("limit and paginator cannot be used together")
+	limitValue, offsetValue := limitClause{sql: limit}, limitClause{sql: offset}
+	if d.LimitExpr != nil {
+		if limitValue, err = newLimitClause(d.LimitExpr); err != nil {
+			return "", nil, err
 		}
+	}
+	if d.OffsetExpr != nil {
+		if offsetValue, err = newLimitClause(d.OffsetExpr); err != nil {
+			return "", nil, err
+		}
+	}
+	args = writeLimitOffset(sql, args, d.Dialect, limitValue, offsetValue, d.WithTies, len(d.OrderByParts) > 0)
 
-		_, _ = sql.WriteString(" LIMIT ")
-		_, _ = sql.WriteString(d.Limit)
+	if len(d.Settings) > 0 {
+		_, _ = sql.WriteString(" SETTINGS ")
+		_, _ = sql.WriteString(strings.Join(d.Settings, ", "))
+	}
+
+	if len(d.Suffixes) > 0 {
+		_, _ = sql.WriteString(" ")
+
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, sql, " ", args)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = d.size.clause("suffix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	sqlStr = sql.String()
+	return sqlStr, args, nil
+}
+
+// validateWithTies checks that FETCH FIRST ... WITH TIES can be used.
+func validateWithTies(d Dialect, ordered bool) error {
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectOracle:
+	default:
+		return d.unsupportedError("FETCH FIRST ... WITH TIES")
+	}
+	if !ordered {
+		return fmt.Errorf("FETCH FIRST ... WITH TIES requires an ORDER BY clause")
+	}
+	return nil
+}
+
+// limitClause is the value of a LIMIT or OFFSET clause, empty if not set.
+type limitClause struct {
+	sql  string
+	args []any
+}
+
+// newLimitClause renders a Sqlizer LIMIT or OFFSET value, parenthesizing subqueries.
+func newLimitClause(e Sqlizer) (limitClause, error) {
+	sql, args, err := nestedToSql(e)
+	if err != nil {
+		return limitClause{}, err
 	}
+	switch e.(type) {
+	case SelectBuilder, *SelectBuilder, UnionBuilder, IntersectBuilder, ExceptBuilder:
+		sql = "(" + sql + ")"
+	}
+	return limitClause{sql: sql, args: args}, nil
+}
+
+// writeLimitOffset writes the LIMIT and OFFSET clauses, or the OFFSET / FETCH
+// clauses for the dialects requiring them and for WITH TIES, and returns args
+// with the args of the clauses appended in order. ordered reports whether an
+// ORDER BY clause was written.
+func writeLimitOffset(sql io.StringWriter, args []any, d Dialect, limit, offset limitClause, withTies, ordered bool) []any {
+	if d == DialectMSSQL && len(limit.sql) > 0 && len(offset.sql) == 0 {
+		// SQL Server requires OFFSET before FETCH
+		offset = limitClause{sql: "0"}
+	}
+	if d == DialectMSSQL && len(offset.sql) > 0 && !ordered {
+		// and ORDER BY before OFFSET
+		_, _ = sql.WriteString(" ORDER BY (SELECT NULL)")
+	}
+
+	if d.fetchPagination() || (withTies && len(limit.sql) > 0) {
+		if len(offset.sql) > 0 {
+			_, _ = sql.WriteString(" OFFSET ")
+			_, _ = sql.WriteString(offset.sql)
+			_, _ = sql.WriteString(" ROWS")
+			args = append(args, offset.args...)
+		}
 
-	if len(d.Offset) > 0 {
-		if d.Paginator.pType != PaginatorTypeUndefined {
-			return "", nil, fmt.Errorf("offset and paginator cannot be used together")
+		if len(limit.sql) > 0 {
+			if len(offset.sql) > 0 {
+				_, _ = sql.WriteString(" FETCH NEXT ")
+			} else {
+				_, _ = sql.WriteString(" FETCH FIRST ")
+			}
+			_, _ = sql.WriteString(limit.sql)
+			if withTies {
+				_, _ = sql.WriteString(" ROWS WITH TIES")
+			} else {
+				_, _ = sql.WriteString(" ROWS ONLY")
+			}
+			args = append(args, limit.args...)
 		}
+		return args
+	}
 
+	if len(limit.sql) > 0 {
+		_, _ = sql.WriteString(" LIMIT ")
+		_, _ = sql.WriteString(limit.sql)
+		args = append(args, limit.args...)
+	}
+
+	if len(offset.sql) > 0 {
 		_, _ = sql.WriteString(" OFFSET ")
-		_, _ = sql.WriteString(d.Offset)
+		_, _ = sql.WriteString(offset.sql)
+		args = append(args, offset.args...)
 	}
+	return args
+}
 
-	if d.Paginator.pType == PaginatorTypeByPage {
-		_, _ = sql.WriteString(fmt.Sprintf(" LIMIT %d", d.Paginator.limit))
+// limitOffset returns the LIMIT and OFFSET values of the query, either set directly or
+// computed from the paginator.
+func (d *selectData) limitOffset() (limit, offset string, err error) {
+	if (len(d.Limit) > 0 || d.LimitExpr != nil) && d.Paginator.pType != PaginatorTypeUndefined {
+		return "", "", fmt.Errorf("limit and paginator cannot be used together")
+	}
+
+	if (len(d.Offset) > 0 || d.OffsetExpr != nil) && d.Paginator.pType != PaginatorTypeUndefined {
+		return "", "", fmt.Errorf("offset and paginator cannot be used together")
+	}
+
+	switch d.Paginator.pType {
+	case PaginatorTypeByPage:
+		limit = fmt.Sprintf("%d", d.Paginator.limit)
 		if d.Paginator.page > 1 {
-			_, _ = sql.WriteString(fmt.Sprintf(" OFFSET %d", d.Paginator.limit*(d.Paginator.page-1)))
+			offset = fmt.Sprintf("%d", d.Paginator.limit*(d.Paginator.page-1))
+		}
+	case PaginatorTypeByID:
+		limit = fmt.Sprintf("%d", d.Paginator.limit)
+	case PaginatorTypeUndefined:
+		limit, offset = d.Limit, d.Offset
+	}
+
+	return limit, offset, nil
+}
+
+// rownumToSqlRaw emulates LIMIT and OFFSET with ROWNUM for Oracle before 12c:
+//
+//	SELECT * FROM (SELECT q.*, ROWNUM rnum FROM (<query>) q WHERE ROWNUM <= offset+limit) WHERE rnum > offset
+func (d *selectData) rownumToSqlRaw() (string, []any, error) {
+	limit, offset, err := d.limitOffset()
+	if err != nil {
+		return "", nil, err
+	}
+
+	inner := *d
+	inner.Prefixes = nil
+	inner.Limit = ""
+	inner.Offset = ""
+	inner.Paginator = Paginator{}
+	inner.Suffixes = nil
+
+	if d.Paginator.pType == PaginatorTypeByID {
+		if d.IDColumn == "" {
+			return "", nil, fmt.Errorf("IDColumn is required for pagination by ID")
+		}
+
+		inner.WhereParts = append(inner.WhereParts[:len(inner.WhereParts):len(inner.WhereParts)],
+			Gt{d.IDColumn: d.Paginator.lastID})
+	}
+
+	innerSql, args, err := inner.toSqlRaw()
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql := &bytes.Buffer{}
+
+	if len(d.Prefixes) > 0 {
+		var prefixArgs []any
+		prefixArgs, err = appendToSqlContext(d.ctx, d.Prefixes, sql, " ", nil)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(prefixArgs, args...)
+
+		_, _ = sql.WriteString(" ")
+	}
+
+	if len(offset) == 0 {
+		_, _ = fmt.Fprintf(sql, "SELECT * FROM (%s) WHERE ROWNUM <= %s", innerSql, limit)
+	} else {
+		_, _ = fmt.Fprintf(sql, "SELECT * FROM (SELECT q.*, ROWNUM rnum FROM (%s) q", innerSql)
+		if len(limit) > 0 {
+			l, _ := strconv.ParseUint(limit, 10, 64)
+			o, _ := strconv.ParseUint(offset, 10, 64)
+			_, _ = fmt.Fprintf(sql, " WHERE ROWNUM <= %d", l+o)
 		}
-	} else if d.Paginator.pType == PaginatorTypeByID {
-		_, _ = sql.WriteString(fmt.Sprintf(" LIMIT %d", d.Paginator.limit))
+		_, _ = fmt.Fprintf(sql, ") WHERE rnum > %s", offset)
 	}
 
 	if len(d.Suffixes) > 0 {
 		_, _ = sql.WriteString(" ")
 
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
 	}
 
-	sqlStr = sql.String()
-	return sqlStr, args, nil
+	return sql.String(), args, nil
+}
+
+// appendFromToSql writes the FROM clause with its modifiers and the joins of the query.
+func (d *selectData) appendFromToSql(sql *bytes.Buffer, args []any) ([]any, error) {
+	var err error
+
+	if d.From != nil {
+		_, _ = sql.WriteString(" FROM ")
+		from := d.From
+		if d.AsOf != nil {
+			if from, err = d.AsOf.apply(d.From, d.Dialect); err != nil {
+				return nil, err
+			}
+		}
+		args, err = appendToSqlContext(d.ctx, []Sqlizer{from}, sql, "", args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if d.Final {
+		_, _ = sql.WriteString(" FINAL")
+	}
+
+	if len(d.Sample) > 0 && d.Dialect != DialectDuckDB {
+		_, _ = sql.WriteString(" SAMPLE ")
+		_, _ = sql.WriteString(d.Sample)
+	}
+
+	if len(d.Joins) > 0 {
+		_, _ = sql.WriteString(" ")
+		args, err = appendToSqlContext(d.ctx, d.Joins, sql, " ", args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
+// asOfClause is a point-in-time table reference, see SelectBuilder.AsOf.
+type asOfClause struct {
+	value   any
+	pattern string
+	args    []any
+}
+
+// apply returns from with the point-in-time clause put after the table name,
+// before the alias.
+func (c *asOfClause) apply(from Sqlizer, d Dialect) (Sqlizer, error) {
+	p, ok := from.(*part)
+	if !ok {
+		return nil, fmt.Errorf("AS OF requires a table name in FROM")
+	}
+	table, ok := p.pred.(string)
+	if !ok {
+		return nil, fmt.Errorf("AS OF requires a table name in FROM")
+	}
+
+	table, alias := splitTableAlias(table)
+
+	if len(c.pattern) > 0 {
+		return newPart(fmt.Sprintf(c.pattern, table)+alias, c.args...), nil
+	}
+
+	var clause string
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectMSSQL, DialectBigQuery:
+		clause = "FOR SYSTEM_TIME AS OF ?"
+	case DialectMariaDB:
+		clause = "FOR SYSTEM_TIME AS OF TIMESTAMP ?"
+	case DialectOracle, DialectOracleLegacy:
+		clause = "AS OF TIMESTAMP ?"
+	case DialectSnowflake:
+		clause = "AT(TIMESTAMP => ?)"
+	default:
+		return nil, d.unsupportedError("AS OF")
+	}
+	return newPart(fmt.Sprintf("%s %s%s", table, clause, alias), c.value), nil
+}
+
+// splitTableAlias splits a table reference like "events e" into the table name
+// and the rest of it (" e"), the latter keeping its leading space.
+func splitTableAlias(table string) (string, string) {
+	table = strings.TrimSpace(table)
+	if i := strings.IndexAny(table, " \t\n"); i >= 0 {
+		return table[:i], table[i:]
+	}
+	return table, ""
+}
+
+// tableRefPart is a table reference with a modifier, see SelectBuilder.FromPartition
+// and SelectBuilder.FromOnly.
+type tableRefPart struct {
+	table      string
+	only       bool
+	partitions []string
+}
+
+func (p tableRefPart) ToSql() (string, []any, error) {
+	table, alias := splitTableAlias(p.table)
+	if len(table) == 0 {
+		return "", nil, fmt.Errorf("table reference requires a table name")
+	}
+
+	sql := table
+	if p.only {
+		sql = "ONLY " + sql
+	}
+	if len(p.partitions) > 0 {
+		sql += " PARTITION (" + strings.Join(p.partitions, ", ") + ")"
+	}
+	return sql + alias, nil, nil
+}
+
+// qualifyFallbackToSqlRaw emulates QUALIFY for dialects without it: the query is wrapped
+// into a subquery filtered by the QUALIFY predicates, and ordering, pagination and
+// suffixes are moved to the outer query.
+func (d *selectData) qualifyFallbackToSqlRaw() (string, []any, error) {
+	orderBy, err := d.qualifyOrderBy()
+	if err != nil {
+		return "", nil, err
+	}
+
+	inner := *d
+	inner.Prefixes = nil
+	inner.QualifyParts = nil
+	inner.OrderByParts = nil
+	inner.Limit = ""
+	inner.LimitExpr = nil
+	inner.Offset = ""
+	inner.OffsetExpr = nil
+	inner.WithTies = false
+	inner.Paginator = Paginator{}
+	inner.Keyset = nil
+	inner.Suffixes = nil
+
+	innerSql, innerArgs, err := inner.toSqlRaw()
+	if err != nil {
+		return "", nil, err
+	}
+
+	from := fmt.Sprintf("(%s) AS qualified", innerSql)
+	if d.Dialect.isOracle() {
+		// Oracle doesn't accept AS before table aliases
+		from = fmt.Sprintf("(%s) qualified", innerSql)
+	}
+
+	outer := selectData{
+		Dialect:      d.Dialect,
+		Prefixes:     d.Prefixes,
+		Columns:      []Sqlizer{newPart("*")},
+		From:         newPart(from, innerArgs...),
+		WhereParts:   d.QualifyParts,
+		OrderByParts: orderBy,
+		Limit:        d.Limit,
+		LimitExpr:    d.LimitExpr,
+		Offset:       d.Offset,
+		OffsetExpr:   d.OffsetExpr,
+		WithTies:     d.WithTies,
+		Suffixes:     d.Suffixes,
+		Paginator:    d.Paginator,
+		IDColumn:     d.IDColumn,
+		Keyset:       d.Keyset,
+	}
+
+	return outer.toSqlRaw()
+}
+
+// qualifyOrderByRegexp matches an ORDER BY item on a plain, possibly qualified, column.
+var qualifyOrderByRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)((?:\s+(?i:IS\s+(?:NOT\s+)?NULL))?(?:\s+(?i:ASC|DESC))?(?:\s+(?i:NULLS\s+(?:FIRST|LAST)))?)$`)
+
+// qualifyOrderBy returns the ORDER BY clause of the outer query of
+// qualifyFallbackToSqlRaw, which only sees the columns selected by the subquery:
+// the items must be columns of the select list, and are rewritten to their name
+// or alias in the subquery.
+func (d *selectData) qualifyOrderBy() ([]Sqlizer, error) {
+	if len(d.OrderByParts) == 0 {
+		return nil, nil
+	}
+
+	star := false
+	names := map[string]string{} // lower-cased column or alias -> name in the subquery
+	for _, column := range d.Columns {
+		sql, _, err := nestedToSql(column)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range splitTopLevel(sql) {
+			item = strings.TrimSpace(item)
+			if item == "*" || strings.HasSuffix(item, ".*") || strings.HasPrefix(item, "* ") {
+				star = true
+			} else if m := simpleColumnRegexp.FindStringSubmatch(item); m != nil {
+				name := m[2]
+				if len(name) == 0 {
+					name = m[1][strings.LastIndex(m[1], ".")+1:]
+				}
+				names[strings.ToLower(m[1])] = name
+				names[strings.ToLower(name)] = name
+			} else if m := aliasedColumnRegexp.FindStringSubmatch(item); m != nil {
+				names[strings.ToLower(m[2])] = m[2]
+			}
+		}
+	}
+
+	var items []string
+	for _, orderBy := range orderByWithDialect(d.OrderByParts, d.Dialect) {
+		sql, args, err := nestedToSql(orderBy)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range splitTopLevel(sql) {
+			item = strings.TrimSpace(item)
+			m := qualifyOrderByRegexp.FindStringSubmatch(item)
+			if m == nil || len(args) > 0 {
+				return nil, fmt.Errorf("ORDER BY %s must be a column of the select list to emulate QUALIFY", item)
+			}
+			name, ok := names[strings.ToLower(m[1])]
+			if !ok && star {
+				name, ok = m[1][strings.LastIndex(m[1], ".")+1:], true
+			}
+			if !ok {
+				return nil, fmt.Errorf("ORDER BY %s must be a column of the select list to emulate QUALIFY", item)
+			}
+			items = append(items, name+m[2])
+		}
+	}
+	return []Sqlizer{newPart(strings.Join(items, ", "))}, nil
+}
+
+// validateDialect checks that the query doesn't use constructs unsupported by the dialect.
+func (d *selectData) validateDialect() error {
+	if !d.Dialect.supportsLateral() {
+		if _, ok := d.From.(fromSelectLateralPart); ok {
+			return d.Dialect.unsupportedError("LATERAL")
+		}
+		for _, join := range d.Joins {
+			if _, ok := join.(joinLateralSelectPart); ok {
+				return d.Dialect.unsupportedError("LATERAL")
+			}
+		}
+	}
+
+	if d.Dialect != DialectDefault && d.Dialect != DialectPostgres {
+		if f, ok := d.From.(tableFunction); ok && f.ordinality {
+			return d.Dialect.unsupportedError("WITH ORDINALITY")
+		}
+		for _, join := range d.Joins {
+			if p, ok := join.(joinTableFunctionPart); ok && p.f.ordinality {
+				return d.Dialect.unsupportedError("WITH ORDINALITY")
+			}
+		}
+	}
+
+	if ref, ok := d.From.(tableRefPart); ok {
+		if ref.only && d.Dialect != DialectDefault && d.Dialect != DialectPostgres {
+			return d.Dialect.unsupportedError("FROM ONLY")
+		}
+		if len(ref.partitions) > 0 && !d.Dialect.supportsPartitionSelection() {
+			return d.Dialect.unsupportedError("PARTITION selection")
+		}
+	}
+
+	if !d.Dialect.supportsClickHouseModifiers() {
+		if d.Final {
+			return d.Dialect.unsupportedError("FINAL")
+		}
+		if len(d.Settings) > 0 {
+			return d.Dialect.unsupportedError("SETTINGS")
+		}
+	}
+
+	if len(d.Sample) > 0 && !d.Dialect.supportsClickHouseModifiers() && d.Dialect != DialectDuckDB {
+		return d.Dialect.unsupportedError("SAMPLE")
+	}
+
+	if d.FromFirst && d.Dialect != DialectDefault && d.Dialect != DialectDuckDB {
+		return d.Dialect.unsupportedError("FROM-first syntax")
+	}
+
+	if (d.Final || len(d.Sample) > 0 || d.FromFirst) && d.From == nil {
+		return fmt.Errorf("FINAL, SAMPLE and FROM-first syntax require a FROM clause")
+	}
+
+	if d.WithTies && len(d.Limit) > 0 {
+		if err := validateWithTies(d.Dialect, len(d.OrderByParts) > 0); err != nil {
+			return err
+		}
+	}
+
+	if (d.LimitExpr != nil || d.OffsetExpr != nil) && d.Dialect == DialectOracleLegacy {
+		return d.Dialect.unsupportedError("LIMIT or OFFSET expression")
+	}
+
+	if len(d.LimitBy) > 0 && !d.Dialect.supportsClickHouseModifiers() {
+		return d.Dialect.unsupportedError("LIMIT BY")
+	}
+
+	if d.GroupByAll {
+		switch d.Dialect { //nolint:exhaustive
+		case DialectDefault, DialectDuckDB, DialectSnowflake, DialectClickHouse:
+		default:
+			return d.Dialect.unsupportedError("GROUP BY ALL")
+		}
+		if len(d.GroupBys) > 0 {
+			return fmt.Errorf("GROUP BY ALL cannot be combined with GROUP BY columns")
+		}
+	}
+
+	if err := d.validateOrdinals(); err != nil {
+		return err
+	}
+
+	if err := d.validateAliases(); err != nil {
+		return err
+	}
+
+	if d.StrictGroupBy {
+		if err := d.validateGroupBy(); err != nil {
+			return err
+		}
+	}
+
+	return d.validateOptions()
+}
+
+// validateAliases checks that the table names and aliases of FROM and JOIN
+// clauses are unique. References which can't be parsed (e.g. raw subqueries in
+// join strings) are not checked.
+func (d *selectData) validateAliases() error {
+	refs := make([]Sqlizer, 0, len(d.Joins)+1)
+	if d.From != nil {
+		refs = append(refs, d.From)
+	}
+	refs = append(refs, d.Joins...)
+
+	seen := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		for _, table := range tableRefs(ref) {
+			for _, other := range seen {
+				if strings.EqualFold(table.name, other) {
+					return fmt.Errorf("table name or alias %s is specified more than once in FROM and JOIN clauses", table.name)
+				}
+			}
+			seen = append(seen, table.name)
+		}
+	}
+	return nil
+}
+
+// tableRef is a table of a FROM or JOIN clause. name is the alias the table is
+// referred by, or the table itself when it has none; table is empty for subqueries.
+type tableRef struct {
+	table string
+	name  string
+}
+
+// tableRefs returns the tables of a FROM or JOIN clause.
+func tableRefs(ref Sqlizer) []tableRef {
+	switch ref := ref.(type) {
+	case tableRefPart:
+		table, alias := splitTableAlias(ref.table)
+		return tableNames(table + alias)
+	case aliasExpr:
+		return []tableRef{{name: ref.alias}}
+	case fromSelectLateralPart:
+		return []tableRef{{name: ref.alias}}
+	case joinLateralSelectPart:
+		return []tableRef{{name: ref.alias}}
+	case tableFunction:
+		if name := ref.name(); len(name) > 0 {
+			return []tableRef{{name: name}}
+		}
+	case joinTableFunctionPart:
+		return tableRefs(ref.f)
+	case joinUnnestPart:
+		return []tableRef{{name: ref.alias}}
+	case *part:
+		if ident, ok := ref.pred.(safeIdent); ok {
+			return tableNames(string(ident))
+		}
+		s, ok := ref.pred.(string)
+		if !ok {
+			return nil
+		}
+		upper := strings.ToUpper(s)
+		if strings.Contains(upper, "ARRAY JOIN ") {
+			return nil
+		}
+		if i := strings.Index(upper, "JOIN "); i >= 0 {
+			s = s[i+len("JOIN "):]
+			upper = upper[i+len("JOIN "):]
+			for _, keyword := range []string{" ON ", " ON(", " USING ", " USING("} {
+				if j := strings.Index(upper, keyword); j >= 0 {
+					s, upper = s[:j], upper[:j]
+				}
+			}
+		}
+		return tableNames(s)
+	}
+	return nil
+}
+
+// tableNames parses a list of simple table references like "users u, orgs AS o".
+func tableNames(s string) []tableRef {
+	if strings.ContainsAny(s, "()") {
+		return nil
+	}
+
+	var refs []tableRef
+	for _, ref := range strings.Split(s, ",") {
+		fields := strings.Fields(ref)
+		switch {
+		case len(fields) == 1:
+			refs = append(refs, tableRef{table: fields[0], name: fields[0]})
+		case len(fields) == 2:
+			refs = append(refs, tableRef{table: fields[0], name: fields[1]})
+		case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+			refs = append(refs, tableRef{table: fields[0], name: fields[2]})
+		}
+	}
+	return refs
+}
+
+// simpleColumnRegexp matches a plain, possibly qualified, column with an optional alias.
+var simpleColumnRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)(?:\s+(?i:AS\s+)?([A-Za-z_][A-Za-z0-9_]*))?$`)
+
+// validateGroupBy checks that the plain columns of the select list are grouped.
+// Other columns (expressions, aggregates, subqueries) are not checked.
+func (d *selectData) validateGroupBy() error {
+	if len(d.GroupBys) == 0 {
+		return nil
+	}
+
+	for _, column := range d.Columns {
+		p, ok := column.(*part)
+		if !ok {
+			continue
+		}
+		s, ok := p.pred.(string)
+		if !ok {
+			continue
+		}
+		m := simpleColumnRegexp.FindStringSubmatch(strings.TrimSpace(s))
+		if m == nil {
+			continue
+		}
+
+		if !isGroupedColumn(d.GroupBys, m[1], m[2]) {
+			return fmt.Errorf("column %s must appear in the GROUP BY clause or be used in an aggregate function", m[1])
+		}
+	}
+	return nil
+}
+
+// isGroupedColumn reports whether groupBys contains the column or its alias.
+// A qualified and an unqualified name with the same column are considered equal.
+func isGroupedColumn(groupBys []string, column, alias string) bool {
+	unqualified := func(name string) string {
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			return name[i+1:]
+		}
+		return name
+	}
+
+	for _, groupBy := range groupBys {
+		for _, g := range strings.Split(groupBy, ",") {
+			g = strings.TrimSpace(g)
+			switch {
+			case strings.EqualFold(g, column), len(alias) > 0 && strings.EqualFold(g, alias):
+				return true
+			case (!strings.Contains(g, ".") || !strings.Contains(column, ".")) &&
+				strings.EqualFold(unqualified(g), unqualified(column)):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateOrdinals checks that ORDER BY ordinals refer to selected columns.
+// Star columns are not counted, so the check is skipped when one is selected.
+func (d *selectData) validateOrdinals() error {
+	for _, column := range d.Columns {
+		if _, ok := column.(starExpr); ok {
+			return nil
+		}
+		if p, ok := column.(*part); ok {
+			if s, ok := p.pred.(string); ok && strings.HasSuffix(strings.TrimSpace(s), "*") {
+				return nil
+			}
+		}
+	}
+
+	for _, orderBy := range d.OrderByParts {
+		if ordinal, ok := orderBy.(orderByOrdinal); ok && (ordinal < 1 || int(ordinal) > len(d.Columns)) {
+			return fmt.Errorf("ORDER BY position %d is not in select list of %d columns", ordinal, len(d.Columns))
+		}
+	}
+	return nil
+}
+
+// validateOptions checks the known select options for conflicting combinations
+// and for options the dialect does not support. Custom options are not checked.
+func (d *selectData) validateOptions() error {
+	var distinct, all, distinctOn bool
+	for _, option := range d.Options {
+		upper := strings.ToUpper(strings.TrimSpace(option))
+		switch {
+		case upper == string(OptionDistinct):
+			distinct = true
+		case upper == string(OptionAll):
+			all = true
+		case strings.HasPrefix(upper, "DISTINCT ON"):
+			if distinctOn {
+				return fmt.Errorf("DISTINCT ON can only be used once")
+			}
+			if d.Dialect != DialectDefault && d.Dialect != DialectPostgres && d.Dialect != DialectDuckDB {
+				return d.Dialect.unsupportedError("DISTINCT ON")
+			}
+			distinctOn = true
+		case mysqlSelectOptions[SelectOption(upper)]:
+			if d.Dialect != DialectDefault && !d.Dialect.isMySQL() {
+				return d.Dialect.unsupportedError(upper)
+			}
+		}
+	}
+
+	if distinctOn && (distinct || all) {
+		return fmt.Errorf("DISTINCT ON cannot be combined with DISTINCT or ALL")
+	}
+	if distinct && all {
+		return fmt.Errorf("DISTINCT cannot be combined with ALL")
+	}
+	return nil
 }
 
 // Builder
 
 // SelectBuilder builds SQL SELECT statements.
-type SelectBuilder builder.Builder
+// The zero value is an empty statement using ? placeholders.
+type SelectBuilder struct {
+	core[selectData]
+}
 
-func init() {
-	builder.Register(SelectBuilder{}, selectData{})
+// set returns a copy of b with the data changed by f.
+func (b SelectBuilder) set(f func(d *selectData)) SelectBuilder {
+	return SelectBuilder{b.with(f)}
 }
 
 // Format methods
@@ -260,19 +1160,64 @@ func init() {
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
 // query.
 func (b SelectBuilder) PlaceholderFormat(f PlaceholderFormat) SelectBuilder {
-	return builder.Set(b, "PlaceholderFormat", f).(SelectBuilder)
+	return b.set(func(d *selectData) { d.PlaceholderFormat = f })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when the select list, the
+// joins, or the WHERE, HAVING or ORDER BY clauses have more than n parts.
+// 0 means no limit.
+func (b SelectBuilder) MaxParts(n int) SelectBuilder {
+	return b.set(func(d *selectData) { d.MaxParts = n })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement is over l, e.g. SizeLimit{Args: 65535} for PostgreSQL.
+func (b SelectBuilder) SizeLimit(l SizeLimit) SelectBuilder {
+	return b.set(func(d *selectData) { d.SizeLimit = l })
+}
+
+// TimeLocation converts the time.Time args of the query to loc, e.g. time.UTC,
+// when it is built. A nil loc leaves them as is.
+func (b SelectBuilder) TimeLocation(loc *time.Location) SelectBuilder {
+	return b.set(func(d *selectData) { d.TimeLocation = loc })
+}
+
+// Dialect sets the target database engine (e.g. DialectSQLite) for the query.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b SelectBuilder) Dialect(d Dialect) SelectBuilder {
+	b = b.set(func(data *selectData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
 }
 
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
 func (b SelectBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(selectData)
+	data := b.get()
+	return data.ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done, for builders with so many parts, e.g. from generated code, that building
+// them takes a noticeable time.
+func (b SelectBuilder) ToSqlContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.ToSql()
 }
 
 func (b SelectBuilder) toSqlRaw() (string, []any, error) {
-	data := builder.GetStruct(b).(selectData)
+	data := b.get()
+	return data.toSqlRaw()
+}
+
+func (b SelectBuilder) toSqlRawContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.toSqlRaw()
 }
 
@@ -293,7 +1238,9 @@ func (b SelectBuilder) Prefix(sql string, args ...any) SelectBuilder {
 
 // PrefixExpr adds an expression to the very beginning of the query
 func (b SelectBuilder) PrefixExpr(e Sqlizer) SelectBuilder {
-	return builder.Append(b, "Prefixes", e).(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.Prefixes = appendTo(d.Prefixes, orNilSqlizer(e, "prefix"))
+	})
 }
 
 // Distinct adds a DISTINCT clause to the query.
@@ -301,25 +1248,53 @@ func (b SelectBuilder) Distinct() SelectBuilder {
 	return b.Options("DISTINCT")
 }
 
-// Options adds select option to the query
+// Options adds select option to the query.
+// Any string is accepted, use Option for the known options validated by ToSql.
 func (b SelectBuilder) Options(options ...string) SelectBuilder {
-	return builder.Extend(b, "Options", options).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Options = appendTo(d.Options, options...) })
+}
+
+// Option adds typed select options to the query.
+// Ex: Select("id").Option(OptionSQLNoCache) -> "SELECT SQL_NO_CACHE id"
+func (b SelectBuilder) Option(options ...SelectOption) SelectBuilder {
+	for _, option := range options {
+		b = b.Options(string(option))
+	}
+	return b
+}
+
+// DistinctOn adds a DISTINCT ON (columns) clause to the query.
+// Ex: Select("user_id", "id").DistinctOn("user_id") -> "SELECT DISTINCT ON (user_id) user_id, id"
+func (b SelectBuilder) DistinctOn(columns ...string) SelectBuilder {
+	return b.Options(fmt.Sprintf("DISTINCT ON (%s)", strings.Join(columns, ", ")))
 }
 
 // Columns adds result columns to the query.
 func (b SelectBuilder) Columns(columns ...string) SelectBuilder {
-	parts := make([]any, 0, len(columns))
+	parts := make([]Sqlizer, 0, len(columns))
 	for _, str := range columns {
 		parts = append(parts, newPart(str))
 	}
-	return builder.Extend(b, "Columns", parts).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Columns = appendTo(d.Columns, parts...) })
+}
+
+// ColumnsPrefixed adds result columns qualified with prefix and aliased with the
+// qualified name, so they can be mapped to prefixed struct fields in join queries.
+// Ex: Select().ColumnsPrefixed("u", "id", "name") -> `SELECT u.id AS "u.id", u.name AS "u.name"`
+func (b SelectBuilder) ColumnsPrefixed(prefix string, columns ...string) SelectBuilder {
+	prefixed := make([]string, 0, len(columns))
+	for _, column := range columns {
+		name := prefix + "." + column
+		prefixed = append(prefixed, fmt.Sprintf(`%s AS "%s"`, name, name))
+	}
+	return b.Columns(prefixed...)
 }
 
 // RemoveColumns remove all columns from query.
 // Must add a new column with Column or Columns methods, otherwise
 // return a error.
 func (b SelectBuilder) RemoveColumns() SelectBuilder {
-	return builder.Delete(b, "Columns").(SelectBuilder)
+	return b.set(func(d *selectData) { d.Columns = nil })
 }
 
 // Column adds a result column to the query.
@@ -328,24 +1303,107 @@ func (b SelectBuilder) RemoveColumns() SelectBuilder {
 //
 //	Column("IF(col IN ("+squirrel.Placeholders(3)+"), 1, 0) as col", 1, 2, 3)
 func (b SelectBuilder) Column(column any, args ...any) SelectBuilder {
-	return builder.Append(b, "Columns", newPart(column, args...)).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Columns = appendTo(d.Columns, newPart(column, args...)) })
 }
 
 // From sets the FROM clause of the query.
 func (b SelectBuilder) From(from string) SelectBuilder {
-	return builder.Set(b, "From", newPart(from)).(SelectBuilder)
+	return b.set(func(d *selectData) { d.From = newPart(from) })
+}
+
+// FromExpr sets the FROM clause of the query to an expression, e.g. a SafeIdent.
+func (b SelectBuilder) FromExpr(from Sqlizer) SelectBuilder {
+	return b.set(func(d *selectData) { d.From = newPart(from) })
+}
+
+// FromPartition sets the FROM clause of the query to the given partitions of
+// table (MySQL). The table may be followed by an alias.
+// Ex: Select("*").FromPartition("events e", "p2024_01") -> "SELECT * FROM events PARTITION (p2024_01) e"
+func (b SelectBuilder) FromPartition(table string, partitions ...string) SelectBuilder {
+	return b.set(func(d *selectData) {
+		d.From = tableRefPart{table: table, partitions: partitions}
+	})
+}
+
+// FromOnly sets the FROM clause of the query to table without its descendant
+// tables (PostgreSQL inheritance and partitioning).
+// Ex: Select("*").FromOnly("measurements m") -> "SELECT * FROM ONLY measurements m"
+func (b SelectBuilder) FromOnly(table string) SelectBuilder {
+	return b.set(func(d *selectData) { d.From = tableRefPart{table: table, only: true} })
+}
+
+// AsOf queries the FROM table as it was at the point in time ts (system-versioned
+// or temporal tables). The clause is rendered per dialect, e.g. FOR SYSTEM_TIME AS OF ?
+// for MSSQL and BigQuery, FOR SYSTEM_TIME AS OF TIMESTAMP ? for MariaDB and
+// AS OF TIMESTAMP ? for Oracle.
+// Ex: Select("*").From("employees e").AsOf(ts) -> "SELECT * FROM employees FOR SYSTEM_TIME AS OF ? e"
+func (b SelectBuilder) AsOf(ts any) SelectBuilder {
+	return b.set(func(d *selectData) { d.AsOf = &asOfClause{value: ts} })
+}
+
+// AsOfPattern queries the FROM table at a point in time with a custom pattern,
+// e.g. for history tables maintained by triggers. %s in pattern is replaced with
+// the table name.
+// Ex: Select("*").From("docs d").AsOfPattern("(SELECT * FROM %s_history WHERE sys_period @> ?::timestamptz)", ts)
+// -> "SELECT * FROM (SELECT * FROM docs_history WHERE sys_period @> ?::timestamptz) d"
+func (b SelectBuilder) AsOfPattern(pattern string, args ...any) SelectBuilder {
+	return b.set(func(d *selectData) { d.AsOf = &asOfClause{pattern: pattern, args: args} })
 }
 
 // FromSelect sets a subquery into the FROM clause of the query.
 func (b SelectBuilder) FromSelect(from SelectBuilder, alias string) SelectBuilder {
 	// Prevent misnumbered parameters in nested selects (#183).
 	from = from.PlaceholderFormat(Question)
-	return builder.Set(b, "From", Alias(from, alias)).(SelectBuilder)
+	return b.set(func(d *selectData) { d.From = Alias(from, alias) })
+}
+
+// Final adds the FINAL modifier to the FROM clause of the query (ClickHouse).
+func (b SelectBuilder) Final() SelectBuilder {
+	return b.set(func(d *selectData) { d.Final = true })
+}
+
+// Sample adds a SAMPLE clause after the FROM clause of the query (ClickHouse),
+// or a USING SAMPLE clause for DialectDuckDB.
+// Ex:
+//
+//	Sample("0.1"), Sample("1/10 OFFSET 1/2")
+//	Sample("10%").Dialect(DialectDuckDB) // USING SAMPLE 10%
+func (b SelectBuilder) Sample(sample string) SelectBuilder {
+	return b.set(func(d *selectData) { d.Sample = sample })
+}
+
+// FromFirst renders the FROM clause before the SELECT list (DuckDB):
+// "FROM t SELECT a, b". Columns can be omitted then: "FROM t".
+func (b SelectBuilder) FromFirst() SelectBuilder {
+	return b.set(func(d *selectData) { d.FromFirst = true })
+}
+
+// Setting adds a query-level setting to the SETTINGS clause of the query (ClickHouse).
+// Values are rendered as literals: strings are quoted, other values are written as is.
+// Ex:
+//
+//	Setting("max_threads", 4) // SETTINGS max_threads = 4
+func (b SelectBuilder) Setting(name string, value any) SelectBuilder {
+	return b.set(func(d *selectData) {
+		d.Settings = appendTo(d.Settings, fmt.Sprintf("%s = %s", name, settingValue(value)))
+	})
+}
+
+func settingValue(value any) string {
+	if s, ok := value.(string); ok {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return fmt.Sprintf("%v", value)
 }
 
 // JoinClause adds a join clause to the query.
 func (b SelectBuilder) JoinClause(pred any, args ...any) SelectBuilder {
-	return builder.Append(b, "Joins", newPart(pred, args...)).(SelectBuilder)
+	if pred == nil {
+		return b.set(func(d *selectData) {
+			d.Joins = appendTo[Sqlizer](d.Joins, nilSqlizer{clause: "JOIN"})
+		})
+	}
+	return b.set(func(d *selectData) { d.Joins = appendTo(d.Joins, newPart(pred, args...)) })
 }
 
 // Join adds a JOIN clause to the query.
@@ -373,6 +1431,16 @@ func (b SelectBuilder) CrossJoin(join string, rest ...any) SelectBuilder {
 	return b.JoinClause("CROSS JOIN "+join, rest...)
 }
 
+// ArrayJoin adds an ARRAY JOIN clause to the query (ClickHouse).
+func (b SelectBuilder) ArrayJoin(join string, rest ...any) SelectBuilder {
+	return b.JoinClause("ARRAY JOIN "+join, rest...)
+}
+
+// LeftArrayJoin adds a LEFT ARRAY JOIN clause to the query (ClickHouse).
+func (b SelectBuilder) LeftArrayJoin(join string, rest ...any) SelectBuilder {
+	return b.JoinClause("LEFT ARRAY JOIN "+join, rest...)
+}
+
 // Where adds an expression to the WHERE clause of the query.
 //
 // Expressions are ANDed together in the generated SQL.
@@ -394,27 +1462,111 @@ func (b SelectBuilder) CrossJoin(join string, rest ...any) SelectBuilder {
 //
 // Where will panic if pred isn't any of the above types.
 func (b SelectBuilder) Where(pred any, args ...any) SelectBuilder {
-	if pred == nil || pred == "" {
+	if pred == nil {
+		if pred = b.nilPredicate("WHERE"); pred == nil {
+			return b
+		}
+	}
+	if pred == "" {
 		return b
 	}
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.WhereParts = appendTo(d.WhereParts, newWherePart(pred, args...))
+	})
 }
 
 // GroupBy adds GROUP BY expressions to the query.
 func (b SelectBuilder) GroupBy(groupBys ...string) SelectBuilder {
-	return builder.Extend(b, "GroupBys", groupBys).(SelectBuilder)
+	return b.set(func(d *selectData) { d.GroupBys = appendTo(d.GroupBys, groupBys...) })
+}
+
+// StrictGroupBy makes ToSql return an error when a plain column of the select
+// list (e.g. "name" or "u.name AS n") is missing from the GROUP BY clause, instead
+// of the query being rejected by the database (or silently accepted by MySQL
+// without ONLY_FULL_GROUP_BY). Expressions are not checked, and columns
+// functionally dependent on a grouped primary key have to be grouped explicitly.
+func (b SelectBuilder) StrictGroupBy() SelectBuilder {
+	return b.set(func(d *selectData) { d.StrictGroupBy = true })
+}
+
+// GroupByAll adds a GROUP BY ALL clause to the query, grouping by every
+// non-aggregated column (DuckDB, Snowflake, ClickHouse).
+func (b SelectBuilder) GroupByAll() SelectBuilder {
+	return b.set(func(d *selectData) { d.GroupByAll = true })
 }
 
 // Having adds an expression to the HAVING clause of the query.
 //
 // See Where.
 func (b SelectBuilder) Having(pred any, rest ...any) SelectBuilder {
-	return builder.Append(b, "HavingParts", newWherePart(pred, rest...)).(SelectBuilder)
+	if pred == nil {
+		if pred = b.nilPredicate("HAVING"); pred == nil {
+			return b
+		}
+	}
+	return b.set(func(d *selectData) {
+		d.HavingParts = appendTo(d.HavingParts, newWherePart(pred, rest...))
+	})
+}
+
+// nilPredicate returns the predicate replacing a nil predicate given for clause:
+// a nilSqlizer if RejectNilPredicates is set, nil to skip it otherwise.
+func (b SelectBuilder) nilPredicate(clause string) Sqlizer {
+	if b.get().RejectNilPredicates {
+		return nilSqlizer{clause: clause}
+	}
+	return nil
+}
+
+// RejectNilPredicates makes ToSql return ErrNilSqlizer when a nil predicate is
+// given to Where or Having, instead of skipping it. It must be called before them.
+func (b SelectBuilder) RejectNilPredicates() SelectBuilder {
+	return b.set(func(d *selectData) { d.RejectNilPredicates = true })
+}
+
+// Qualify adds an expression to the QUALIFY clause of the query, used to filter
+// on window functions (BigQuery, Snowflake, DuckDB, ClickHouse).
+//
+// For dialects without QUALIFY the query is wrapped into a subquery filtered by
+// the expression, so it must reference the window functions by their aliases:
+//
+//	Select("id").Column("ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id DESC) AS rn").
+//		From("orders").Qualify("rn = 1")
+//
+// The ORDER BY clause is moved to the outer query as well, so ToSql returns an
+// error unless it orders by columns of the select list.
+//
+// See Where.
+func (b SelectBuilder) Qualify(pred any, rest ...any) SelectBuilder {
+	return b.set(func(d *selectData) {
+		d.QualifyParts = appendTo(d.QualifyParts, newWherePart(pred, rest...))
+	})
 }
 
 // OrderByClause adds ORDER BY clause to the query.
 func (b SelectBuilder) OrderByClause(pred any, args ...any) SelectBuilder {
-	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.OrderByParts = appendTo(d.OrderByParts, newPart(pred, args...))
+	})
+}
+
+// orderByOrdinal is a position of the select list used in ORDER BY.
+type orderByOrdinal int
+
+func (o orderByOrdinal) ToSql() (string, []any, error) {
+	return strconv.Itoa(int(o)), nil, nil
+}
+
+// OrderByOrdinal adds ORDER BY expressions referring to the selected columns by
+// their 1-based position. ToSql returns an error if a position is out of the select list.
+// Ex: Select("country", "count(*)").GroupBy("country").OrderByOrdinal(2) -> "... ORDER BY 2"
+func (b SelectBuilder) OrderByOrdinal(positions ...int) SelectBuilder {
+	for _, position := range positions {
+		b = b.set(func(d *selectData) {
+			d.OrderByParts = appendTo[Sqlizer](d.OrderByParts, orderByOrdinal(position))
+		})
+	}
+	return b
 }
 
 // OrderBy adds ORDER BY expressions to the query.
@@ -446,6 +1598,107 @@ func (o OrderNullsType) String() string {
 	return ""
 }
 
+// orderExpr helps to use ORDER BY terms with direction and NULLs ordering in SQL query
+type orderExpr struct {
+	column    string
+	direction string
+	nulls     OrderNullsType
+	dialect   Dialect
+}
+
+// Order allows to use typed ORDER BY terms in SQL query.
+// For dialects without NULLS FIRST / LAST (MySQL, MSSQL) the NULLs ordering is
+// emulated with an additional "column IS NULL" term. A SelectBuilder renders the
+// term with its own dialect, unless one is set with Dialect.
+// Ex: SelectBuilder.OrderByClause(Order("name").Desc().NullsLast()) -> "name DESC NULLS LAST"
+func Order(column string) orderExpr {
+	return orderExpr{column: column}
+}
+
+// Asc sets the ASC direction.
+func (e orderExpr) Asc() orderExpr {
+	e.direction = Asc.String()
+	return e
+}
+
+// Desc sets the DESC direction.
+func (e orderExpr) Desc() orderExpr {
+	e.direction = Desc.String()
+	return e
+}
+
+// NullsFirst puts NULLs before the other values.
+func (e orderExpr) NullsFirst() orderExpr {
+	e.nulls = OrderNullsFirst
+	return e
+}
+
+// NullsLast puts NULLs after the other values.
+func (e orderExpr) NullsLast() orderExpr {
+	e.nulls = OrderNullsLast
+	return e
+}
+
+// Dialect sets the dialect used to render the term.
+func (e orderExpr) Dialect(d Dialect) orderExpr {
+	e.dialect = d
+	return e
+}
+
+func (e orderExpr) ToSql() (string, []any, error) {
+	sql := e.column
+	if len(e.direction) > 0 {
+		sql += " " + e.direction
+	}
+
+	if e.nulls == OrderNullsUndefined {
+		return sql, nil, nil
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB:
+		// FALSE sorts before TRUE
+		if e.nulls == OrderNullsFirst {
+			return fmt.Sprintf("%s IS NULL DESC, %s", e.column, sql), nil, nil
+		}
+		return fmt.Sprintf("%s IS NULL, %s", e.column, sql), nil, nil
+	case DialectMSSQL:
+		if e.nulls == OrderNullsFirst {
+			return fmt.Sprintf("CASE WHEN %s IS NULL THEN 0 ELSE 1 END, %s", e.column, sql), nil, nil
+		}
+		return fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END, %s", e.column, sql), nil, nil
+	}
+
+	return fmt.Sprintf("%s NULLS %s", sql, e.nulls.String()), nil, nil
+}
+
+// orderByWithDialect sets d on the orderExpr terms of parts without a dialect.
+func orderByWithDialect(parts []Sqlizer, d Dialect) []Sqlizer {
+	if d == DialectDefault {
+		return parts
+	}
+
+	result := make([]Sqlizer, len(parts))
+	for i, p := range parts {
+		result[i] = p
+		if pp, ok := p.(*part); ok {
+			if o, ok := pp.pred.(orderExpr); ok && o.dialect == DialectDefault {
+				result[i] = newPart(o.Dialect(d), pp.args...)
+			}
+		}
+	}
+	return result
+}
+
+// withErr records err on the builder, to be returned by ToSql.
+// Only the first recorded error is kept.
+func (b SelectBuilder) withErr(err error) SelectBuilder {
+	if b.get().Err != nil {
+		return b
+	}
+	return b.set(func(d *selectData) { d.Err = err })
+}
+
 // OrderByCondOption is used to specify additional options for OrderByCond.
 type OrderByCondOption struct {
 	ColumnID  int
@@ -454,7 +1707,8 @@ type OrderByCondOption struct {
 
 // OrderByCond adds ORDER BY expressions with direction to the query.
 // The columns map is used to map OrderCond.ColumnID to the column name.
-// Can be used to avoid hardcoding column names in the code.
+// Can be used to avoid hardcoding column names in the code. An invalid direction
+// or a ColumnID missing from columns makes ToSql return an error.
 func (b SelectBuilder) OrderByCond(columns map[int]string, conds []OrderCond, opts ...OrderByCondOption) SelectBuilder {
 	for i, cond := range conds {
 		if pos := slices.IndexFunc(conds[:i], func(c OrderCond) bool {
@@ -463,9 +1717,13 @@ func (b SelectBuilder) OrderByCond(columns map[int]string, conds []OrderCond, op
 			continue
 		}
 
+		if cond.Direction != Asc && cond.Direction != Desc {
+			return b.withErr(fmt.Errorf("OrderByCond: invalid direction %d for column id %d", cond.Direction, cond.ColumnID))
+		}
+
 		column, ok := columns[cond.ColumnID]
 		if !ok {
-			panic(fmt.Sprintf("column id %d not found in columns map %v", cond.ColumnID, columns))
+			return b.withErr(fmt.Errorf("OrderByCond: column id %d not found in columns map", cond.ColumnID))
 		}
 
 		nullsType := OrderNullsUndefined
@@ -476,11 +1734,13 @@ func (b SelectBuilder) OrderByCond(columns map[int]string, conds []OrderCond, op
 			}
 		}
 
-		if nullsType == OrderNullsUndefined {
-			b = b.OrderByClause(fmt.Sprintf("%s %s", column, cond.Direction.String()))
-		} else {
-			b = b.OrderByClause(fmt.Sprintf("%s %s NULLS %s", column, cond.Direction.String(), nullsType.String()))
+		order := Order(column).Asc()
+		if cond.Direction == Desc {
+			order = order.Desc()
 		}
+		order.nulls = nullsType
+
+		b = b.OrderByClause(order)
 	}
 
 	return b
@@ -521,33 +1781,89 @@ func (b SelectBuilder) PaginateByPage(limit uint64, page uint64) SelectBuilder {
 
 // Paginate adds pagination conditions to the query.
 func (b SelectBuilder) Paginate(p Paginator) SelectBuilder {
-	return builder.Set(b, "Paginator", p).(SelectBuilder)
+	return b.set(func(d *selectData) { d.Paginator = p })
 }
 
 // SetIDColumn sets the column name to be used for pagination by ID.
 // Required in special cases when Paginate function combined with PaginatorByID.
 func (b SelectBuilder) SetIDColumn(column string) SelectBuilder {
-	return builder.Set(b, "IDColumn", column).(SelectBuilder)
+	return b.set(func(d *selectData) { d.IDColumn = column })
 }
 
 // Limit sets a LIMIT clause on the query.
 func (b SelectBuilder) Limit(limit uint64) SelectBuilder {
-	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.LimitExpr = nil
+		d.Limit = fmt.Sprintf("%d", limit)
+	})
+}
+
+// LimitExpr sets a LIMIT clause on the query with an expression, e.g. a bound
+// arg to reuse prepared plans or a subquery.
+// Ex: Select("*").From("posts").LimitExpr(Select("page_size").From("settings"))
+// -> "SELECT * FROM posts LIMIT (SELECT page_size FROM settings)"
+func (b SelectBuilder) LimitExpr(limit Sqlizer) SelectBuilder {
+	return b.set(func(d *selectData) {
+		d.Limit = ""
+		d.LimitExpr = limit
+	})
+}
+
+// FetchFirstWithTies sets a FETCH FIRST n ROWS WITH TIES clause, which also returns
+// the rows tied with the last one by the ORDER BY clause.
+// Ex: Select("id").From("scores").OrderBy("score DESC").FetchFirstWithTies(3)
+// -> "SELECT id FROM scores ORDER BY score DESC FETCH FIRST 3 ROWS WITH TIES"
+func (b SelectBuilder) FetchFirstWithTies(n uint64) SelectBuilder {
+	b = b.Limit(n)
+	return b.set(func(d *selectData) { d.WithTies = true })
+}
+
+// LimitBy sets a ClickHouse LIMIT n BY columns clause, returning at most n rows
+// for every distinct value of the columns.
+// Ex: Select("*").From("events").OrderBy("ts DESC").LimitBy(2, "user_id")
+// -> "SELECT * FROM events ORDER BY ts DESC LIMIT 2 BY user_id"
+func (b SelectBuilder) LimitBy(n uint64, columns ...string) SelectBuilder {
+	return b.set(func(d *selectData) {
+		d.LimitBy = fmt.Sprintf("%d BY %s", n, strings.Join(columns, ", "))
+	})
 }
 
 // RemoveLimit Limit ALL allows to access all records with limit
 func (b SelectBuilder) RemoveLimit() SelectBuilder {
-	return builder.Delete(b, "Limit").(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.LimitExpr = nil
+		d.Limit = ""
+	})
+}
+
+// Unlimited opts the query out of the DefaultLimit of its StatementBuilder.
+func (b SelectBuilder) Unlimited() SelectBuilder {
+	return b.set(func(d *selectData) { d.Unlimited = true })
 }
 
 // Offset sets a OFFSET clause on the query.
 func (b SelectBuilder) Offset(offset uint64) SelectBuilder {
-	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.OffsetExpr = nil
+		d.Offset = fmt.Sprintf("%d", offset)
+	})
+}
+
+// OffsetExpr sets a OFFSET clause on the query with an expression, see LimitExpr.
+// Ex: Select("*").From("posts").OffsetExpr(Expr("?", 40)) -> "SELECT * FROM posts OFFSET ?"
+func (b SelectBuilder) OffsetExpr(offset Sqlizer) SelectBuilder {
+	return b.set(func(d *selectData) {
+		d.Offset = ""
+		d.OffsetExpr = offset
+	})
 }
 
 // RemoveOffset removes OFFSET clause.
 func (b SelectBuilder) RemoveOffset() SelectBuilder {
-	return builder.Delete(b, "Offset").(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.OffsetExpr = nil
+		d.Offset = ""
+	})
 }
 
 // Suffix adds an expression to the end of the query
@@ -557,7 +1873,84 @@ func (b SelectBuilder) Suffix(sql string, args ...any) SelectBuilder {
 
 // SuffixExpr adds an expression to the end of the query
 func (b SelectBuilder) SuffixExpr(e Sqlizer) SelectBuilder {
-	return builder.Append(b, "Suffixes", e).(SelectBuilder)
+	return b.set(func(d *selectData) {
+		d.Suffixes = appendTo(d.Suffixes, orNilSqlizer(e, "suffix"))
+	})
+}
+
+// MergeWith merges the WHERE parts, joins and ORDER BY parts of other into the query,
+// so query fragments (e.g. permission filters) maintained as separate builders
+// can be combined.
+//
+// Conflicts are resolved as follows: the FROM and OFFSET clauses of b are kept,
+// those of other are only used if b has none; the smallest LIMIT wins, and a
+// LimitExpr of other, which cannot be compared, replaces the LIMIT of b; joins
+// already present in b (same SQL and args) are not repeated.
+func (b SelectBuilder) MergeWith(other SelectBuilder) SelectBuilder {
+	data := b.get()
+	otherData := other.get()
+
+	if data.From == nil && otherData.From != nil {
+		b = b.set(func(d *selectData) { d.From = otherData.From })
+	}
+
+	for _, join := range otherData.Joins {
+		if !containsSqlizer(data.Joins, join) {
+			b = b.set(func(d *selectData) { d.Joins = appendTo(d.Joins, join) })
+		}
+	}
+
+	if len(otherData.WhereParts) > 0 {
+		b = b.set(func(d *selectData) {
+			d.WhereParts = appendTo(d.WhereParts, otherData.WhereParts...)
+		})
+	}
+
+	if len(otherData.OrderByParts) > 0 {
+		b = b.set(func(d *selectData) {
+			d.OrderByParts = appendTo(d.OrderByParts, otherData.OrderByParts...)
+		})
+	}
+
+	switch {
+	case otherData.LimitExpr != nil:
+		b = b.LimitExpr(otherData.LimitExpr)
+	case len(otherData.Limit) > 0:
+		limit, _ := strconv.ParseUint(data.Limit, 10, 64)
+		otherLimit, _ := strconv.ParseUint(otherData.Limit, 10, 64)
+		if len(data.Limit) == 0 || otherLimit < limit {
+			b = b.set(func(d *selectData) {
+				d.LimitExpr = nil
+				d.Limit = otherData.Limit
+			})
+		}
+	}
+
+	if len(data.Offset) == 0 && data.OffsetExpr == nil {
+		switch {
+		case otherData.OffsetExpr != nil:
+			b = b.OffsetExpr(otherData.OffsetExpr)
+		case len(otherData.Offset) > 0:
+			b = b.set(func(d *selectData) { d.Offset = otherData.Offset })
+		}
+	}
+
+	return b
+}
+
+// containsSqlizer reports whether parts contains a Sqlizer rendering the same SQL and args as s.
+func containsSqlizer(parts []Sqlizer, s Sqlizer) bool {
+	sSql, sArgs, err := nestedToSql(s)
+	if err != nil {
+		return false
+	}
+	for _, p := range parts {
+		pSql, pArgs, err := nestedToSql(p)
+		if err == nil && pSql == sSql && reflect.DeepEqual(pArgs, sArgs) {
+			return true
+		}
+	}
+	return false
 }
 
 type alias struct {
@@ -409,9 +409,11 @@ func TestOrderByCond(t *testing.T) {
 	assert.Equal(t, "SELECT id FROM users ORDER BY id ASC, created DESC", sql)
 	assert.Empty(t, args)
 
-	assert.Panics(t, func() {
-		_ = Select("id").From("users").OrderByCond(columns, []OrderCond{{3, Asc}})
-	})
+	_, _, err = Select("id").From("users").OrderByCond(columns, []OrderCond{{3, Asc}}).ToSql()
+	assert.EqualError(t, err, "OrderByCond: column id 3 not found in columns map")
+
+	_, _, err = Select("id").From("users").OrderByCond(columns, []OrderCond{{1, Direction(2)}}).ToSql()
+	assert.EqualError(t, err, "OrderByCond: invalid direction 2 for column id 1")
 
 	// test with options
 	sql, args, err = Select("id").From("users").OrderByCond(columns, orderConds,
@@ -539,3 +541,464 @@ func TestSelectWith(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "WITH table1 AS ( SELECT a FROM table2 ) SELECT a FROM table3", sql)
 }
+
+func TestSelectBuilderClickHouse(t *testing.T) {
+	b := Select("id", "tag").
+		From("events").
+		Final().
+		Sample("1/10").
+		ArrayJoin("tags AS tag").
+		Where(Eq{"user_id": 1}).
+		Limit(10).
+		Setting("max_threads", 4).
+		Setting("join_algorithm", "hash").
+		Suffix("FORMAT JSON").
+		Dialect(DialectClickHouse)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT id, tag FROM events FINAL SAMPLE 1/10 ARRAY JOIN tags AS tag " +
+		"WHERE user_id = ? LIMIT 10 SETTINGS max_threads = 4, join_algorithm = 'hash' FORMAT JSON"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestSelectBuilderClickHouseErrors(t *testing.T) {
+	_, _, err := Select("id").From("events").Final().Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("events").Sample("0.1").Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("events").Setting("max_threads", 4).Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("1").Final().ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderQualify(t *testing.T) {
+	b := Select("id").
+		Column("ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id DESC) AS rn").
+		From("orders").
+		Where(Eq{"status": "paid"}).
+		Qualify("rn <= ?", 2).
+		OrderBy("id").
+		Limit(10)
+
+	sql, args, err := b.Dialect(DialectDuckDB).ToSql()
+	assert.NoError(t, err)
+	expectedSql := "SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id DESC) AS rn FROM orders " +
+		"WHERE status = ? QUALIFY rn <= ? ORDER BY id LIMIT 10"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []any{"paid", 2}, args)
+
+	sql, args, err = b.Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	expectedSql = "SELECT * FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id DESC) AS rn FROM orders " +
+		"WHERE status = $1) AS qualified WHERE rn <= $2 ORDER BY id LIMIT 10"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []any{"paid", 2}, args)
+}
+
+func TestSelectBuilderQualifyFallbackOrderBy(t *testing.T) {
+	b := Select("o.id AS order_id", "o.user_id").
+		Column("ROW_NUMBER() OVER (PARTITION BY o.user_id ORDER BY o.id DESC) AS rn").
+		From("orders o").
+		Qualify("rn = 1").
+		Dialect(DialectPostgres)
+
+	sql, _, err := b.OrderBy("o.id DESC", "user_id").OrderByClause(Order("rn").NullsFirst()).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT o.id AS order_id, o.user_id, "+
+		"ROW_NUMBER() OVER (PARTITION BY o.user_id ORDER BY o.id DESC) AS rn FROM orders o) AS qualified "+
+		"WHERE rn = 1 ORDER BY order_id DESC, user_id, rn NULLS FIRST", sql)
+
+	sql, _, err = Select("o.*").Column("ROW_NUMBER() OVER (ORDER BY o.id) AS rn").From("orders o").
+		Qualify("rn = 1").OrderBy("o.created_at").Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT o.*, ROW_NUMBER() OVER (ORDER BY o.id) AS rn FROM orders o) AS qualified "+
+		"WHERE rn = 1 ORDER BY created_at", sql)
+
+	_, _, err = b.OrderBy("o.created_at").ToSql()
+	assert.EqualError(t, err, "ORDER BY o.created_at must be a column of the select list to emulate QUALIFY")
+	_, _, err = b.OrderBy("LOWER(o.id)").ToSql()
+	assert.Error(t, err)
+
+	// QUALIFY is rendered as is when the dialect supports it
+	_, _, err = b.OrderBy("o.created_at").Dialect(DialectDuckDB).ToSql()
+	assert.NoError(t, err)
+}
+
+func TestSelectBuilderDuckDB(t *testing.T) {
+	b := Select().
+		Column(Star().Exclude("secret")).
+		From("users").
+		Where(Eq{"active": true}).
+		Sample("10%").
+		Limit(5).
+		Dialect(DialectDuckDB)
+
+	sql, args, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * EXCLUDE (secret) FROM users WHERE active = ? USING SAMPLE 10% LIMIT 5", sql)
+	assert.Equal(t, []any{true}, args)
+
+	sql, _, err = b.FromFirst().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM users SELECT * EXCLUDE (secret) WHERE active = ? USING SAMPLE 10% LIMIT 5", sql)
+
+	sql, _, err = Select().From("users").Join("emails USING (id)").FromFirst().Dialect(DialectDuckDB).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM users JOIN emails USING (id)", sql)
+
+	_, _, err = Select("id").From("users").FromFirst().Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("users").Sample("10%").Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderOracle(t *testing.T) {
+	sql, _, err := Select("SYSDATE").Dialect(DialectOracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT SYSDATE FROM DUAL", sql)
+
+	b := Select("id").From("users").Where(Eq{"active": 1}).OrderBy("id")
+
+	sql, args, err := b.Limit(10).Offset(20).Dialect(DialectOracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE active = :1 ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, _, err = b.Limit(10).Dialect(DialectOracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users WHERE active = :1 ORDER BY id FETCH FIRST 10 ROWS ONLY", sql)
+
+	sql, _, err = b.Limit(10).Dialect(DialectOracleLegacy).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id FROM users WHERE active = :1 ORDER BY id) WHERE ROWNUM <= 10", sql)
+
+	sql, args, err = b.Paginate(PaginatorByPage(10, 3)).Suffix("/* page */").Dialect(DialectOracleLegacy).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT q.*, ROWNUM rnum FROM (SELECT id FROM users WHERE active = :1 ORDER BY id) q "+
+		"WHERE ROWNUM <= 30) WHERE rnum > 20 /* page */", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, args, err = b.Paginate(PaginatorByID(5, 100)).SetIDColumn("id").Dialect(DialectOracleLegacy).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT id FROM users WHERE active = :1 AND id > :2 ORDER BY id) WHERE ROWNUM <= 5", sql)
+	assert.Equal(t, []any{1, int64(100)}, args)
+
+	_, _, err = b.Limit(1).Paginate(PaginatorByPage(10, 3)).Dialect(DialectOracleLegacy).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderOption(t *testing.T) {
+	sql, _, err := Select("id").From("users").Option(OptionSQLNoCache, OptionSQLCalcFoundRows).Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT SQL_NO_CACHE SQL_CALC_FOUND_ROWS id FROM users", sql)
+
+	sql, _, err = Select("user_id", "id").From("orders").DistinctOn("user_id").Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT ON (user_id) user_id, id FROM orders", sql)
+
+	// custom options are not validated
+	sql, _, err = Select("id").From("users").Options("TOP 10").Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT TOP 10 id FROM users", sql)
+}
+
+func TestSelectBuilderOptionErrors(t *testing.T) {
+	_, _, err := Select("id").From("users").Distinct().DistinctOn("id").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("users").Option(OptionDistinct, OptionAll).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("users").Option(OptionHighPriority).Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("users").DistinctOn("id").Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderFetchFirstWithTies(t *testing.T) {
+	b := Select("id").From("scores").OrderBy("score DESC").FetchFirstWithTies(3)
+
+	sql, _, err := b.Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM scores ORDER BY score DESC FETCH FIRST 3 ROWS WITH TIES", sql)
+
+	sql, _, err = b.Offset(6).Dialect(DialectOracle).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM scores ORDER BY score DESC OFFSET 6 ROWS FETCH NEXT 3 ROWS WITH TIES", sql)
+
+	_, _, err = b.Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("id").From("scores").FetchFirstWithTies(3).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderLimitBy(t *testing.T) {
+	b := Select("*").From("events").OrderBy("ts DESC").LimitBy(2, "user_id", "kind").Limit(100)
+
+	sql, _, err := b.Dialect(DialectClickHouse).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events ORDER BY ts DESC LIMIT 2 BY user_id, kind LIMIT 100", sql)
+
+	_, _, err = b.Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderGroupByAll(t *testing.T) {
+	b := Select("country", "city", "count(*)").From("users").GroupByAll().OrderByOrdinal(3, 1)
+
+	sql, _, err := b.Dialect(DialectDuckDB).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT country, city, count(*) FROM users GROUP BY ALL ORDER BY 3, 1", sql)
+
+	_, _, err = b.Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = b.GroupBy("country").ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderOrderByOrdinal(t *testing.T) {
+	_, _, err := Select("a", "b").From("t").OrderByOrdinal(3).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("a").From("t").OrderByOrdinal(0).ToSql()
+	assert.Error(t, err)
+
+	sql, _, err := Select("*").From("t").OrderByOrdinal(3).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t ORDER BY 3", sql)
+}
+
+func TestSelectBuilderColumnsPrefixed(t *testing.T) {
+	sql, _, err := Select().ColumnsPrefixed("u", "id", "name").ColumnsPrefixed("o", "id").
+		From("users u").Join("orders o ON o.user_id = u.id").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT u.id AS "u.id", u.name AS "u.name", o.id AS "o.id" FROM users u JOIN orders o ON o.user_id = u.id`, sql)
+}
+
+func TestSelectBuilderMergeWith(t *testing.T) {
+	permissions := Select().
+		Join("memberships m ON m.project_id = p.id").
+		Where(Eq{"m.user_id": 7}).
+		Limit(50)
+
+	q := Select("p.id").From("projects p").
+		Join("memberships m ON m.project_id = p.id").
+		Where("p.archived = ?", false).
+		OrderBy("p.id").
+		Limit(100).
+		MergeWith(permissions).
+		MergeWith(Select().From("other").OrderBy("p.name").Offset(10))
+
+	sql, args, err := q.ToSql()
+	assert.NoError(t, err)
+	expectedSql := "SELECT p.id FROM projects p JOIN memberships m ON m.project_id = p.id " +
+		"WHERE p.archived = ? AND m.user_id = ? ORDER BY p.id, p.name LIMIT 50 OFFSET 10"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []any{false, 7}, args)
+
+	sql, _, err = Select("id").MergeWith(Select().From("t").Limit(5)).Limit(10).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM t LIMIT 10", sql)
+
+	// LIMIT and OFFSET expressions
+	sql, args, err = Select("id").From("t").LimitExpr(Expr("?", 20)).OffsetExpr(Expr("?", 40)).
+		MergeWith(Select().Limit(5).Offset(10)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM t LIMIT 5 OFFSET ?", sql)
+	assert.Equal(t, []any{40}, args)
+
+	sql, args, err = Select("id").From("t").Limit(5).
+		MergeWith(Select().LimitExpr(Expr("?", 20)).OffsetExpr(Expr("?", 40))).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM t LIMIT ? OFFSET ?", sql)
+	assert.Equal(t, []any{20, 40}, args)
+
+	sql, args, err = Select("id").From("t").Offset(10).
+		MergeWith(Select().OffsetExpr(Expr("?", 40))).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM t OFFSET 10", sql)
+	assert.Empty(t, args)
+}
+
+func TestSelectBuilderOrderNulls(t *testing.T) {
+	b := Select("id").From("users").OrderByClause(Order("name").Desc().NullsLast()).OrderByClause(Order("age").NullsFirst())
+
+	sql, _, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users ORDER BY name DESC NULLS LAST, age NULLS FIRST", sql)
+
+	sql, _, err = b.Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users ORDER BY name IS NULL, name DESC, age IS NULL DESC, age", sql)
+
+	sql, _, err = b.Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users ORDER BY CASE WHEN name IS NULL THEN 1 ELSE 0 END, name DESC, "+
+		"CASE WHEN age IS NULL THEN 0 ELSE 1 END, age", sql)
+
+	columns := map[int]string{1: "id", 2: "name"}
+	sql, _, err = Select("id").From("users").
+		OrderByCond(columns, []OrderCond{{2, Asc}}, OrderByCondOption{ColumnID: 2, NullsType: OrderNullsLast}).
+		Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM users ORDER BY name IS NULL, name ASC", sql)
+}
+
+func TestSelectBuilderAsOf(t *testing.T) {
+	ts := "2024-01-01 00:00:00"
+	b := Select("*").From("employees e").Where(Eq{"e.id": 1}).AsOf(ts)
+
+	sql, args, err := b.Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM employees FOR SYSTEM_TIME AS OF @p1 e WHERE e.id = @p2", sql)
+	assert.Equal(t, []any{ts, 1}, args)
+
+	sql, _, err = b.Dialect(DialectMariaDB).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM employees FOR SYSTEM_TIME AS OF TIMESTAMP ? e WHERE e.id = ?", sql)
+
+	_, _, err = b.Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	sql, args, err = Select("*").From("docs d").
+		AsOfPattern("(SELECT * FROM %s_history WHERE sys_period @> ?::timestamptz)", ts).
+		Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM (SELECT * FROM docs_history WHERE sys_period @> $1::timestamptz) d", sql)
+	assert.Equal(t, []any{ts}, args)
+}
+
+func TestSelectBuilderFromPartition(t *testing.T) {
+	b := Select("*").FromPartition("events e", "p2024_01", "p2024_02").Where(Eq{"e.kind": "click"})
+
+	sql, args, err := b.Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events PARTITION (p2024_01, p2024_02) e WHERE e.kind = ?", sql)
+	assert.Equal(t, []any{"click"}, args)
+
+	_, _, err = b.Dialect(DialectPostgres).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").FromPartition("", "p0").ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderFromOnly(t *testing.T) {
+	b := Select("*").FromOnly("measurements m").Where("m.id = ?", 1)
+
+	sql, _, err := b.Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM ONLY measurements m WHERE m.id = $1", sql)
+
+	_, _, err = b.Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderStrictGroupBy(t *testing.T) {
+	b := Select("u.country", "city AS c", "COUNT(*)").From("users u").GroupBy("country", "c").StrictGroupBy()
+
+	sql, _, err := b.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT u.country, city AS c, COUNT(*) FROM users u GROUP BY country, c", sql)
+
+	_, _, err = b.Column("u.name").ToSql()
+	assert.EqualError(t, err, "column u.name must appear in the GROUP BY clause or be used in an aggregate function")
+
+	_, _, err = Select("u.name", "o.name").From("users u").Join("orgs o ON o.id = u.org_id").
+		GroupBy("u.name").StrictGroupBy().ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("name", "COUNT(*)").From("users").GroupBy("id").ToSql()
+	assert.NoError(t, err)
+}
+
+func TestSelectBuilderAliasCollision(t *testing.T) {
+	_, _, err := Select("*").From("users u").Join("orgs u ON u.id = u.org_id").ToSql()
+	assert.EqualError(t, err, "table name or alias u is specified more than once in FROM and JOIN clauses")
+
+	_, _, err = Select("*").From("users").LeftJoin("users ON users.id = users.parent_id").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("users u, orgs AS U").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").FromSelect(Select("id").From("users"), "u").
+		JoinLateralSelect(Select("id").From("orders"), "u", Expr("true")).ToSql()
+	assert.Error(t, err)
+
+	sql, _, err := Select("*").From("users u").
+		Join("users p ON p.id = u.parent_id").
+		LeftJoin("(SELECT 1) u ON true").
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users u JOIN users p ON p.id = u.parent_id LEFT JOIN (SELECT 1) u ON true", sql)
+}
+
+func TestSelectBuilderLimitOffsetExpr(t *testing.T) {
+	sql, args, err := Select("*").From("posts").
+		Where(Eq{"author_id": 7}).
+		LimitExpr(Expr("?", 20)).
+		OffsetExpr(Expr("?", 40)).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts WHERE author_id = $1 LIMIT $2 OFFSET $3", sql)
+	assert.Equal(t, []any{7, 20, 40}, args)
+
+	sql, args, err = Select("*").From("posts").
+		OrderBy("id").
+		LimitExpr(Select("page_size").From("settings").Where(Eq{"name": "posts"})).
+		OffsetExpr(Expr("?", 40)).
+		Dialect(DialectMSSQL).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts ORDER BY id OFFSET @p1 ROWS "+
+		"FETCH NEXT (SELECT page_size FROM settings WHERE name = @p2) ROWS ONLY", sql)
+	assert.Equal(t, []any{40, "posts"}, args)
+
+	sql, _, err = Select("*").From("posts").LimitExpr(Expr("?", 20)).Limit(10).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts LIMIT 10", sql)
+
+	sql, _, err = StatementBuilder.DefaultLimit(100).Select("*").From("posts").LimitExpr(Expr("?", 20)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM posts LIMIT ?", sql)
+
+	_, _, err = Select("*").From("posts").LimitExpr(Expr("?", 20)).Dialect(DialectOracleLegacy).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = Select("*").From("posts").LimitExpr(Expr("?", 20)).Paginate(PaginatorByPage(10, 2)).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSelectBuilderNilSqlizer(t *testing.T) {
+	var pred Sqlizer
+
+	sql, _, err := Select("a").From("t").Where(pred).Having(pred).Where(And{pred, Eq{"b": 1}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE (b = ?)", sql)
+
+	_, _, err = Select("a").From("t").RejectNilPredicates().Having(pred).ToSql()
+	assert.ErrorIs(t, err, ErrNilSqlizer)
+	assert.EqualError(t, err, "nil Sqlizer in HAVING")
+
+	_, _, err = StatementBuilder.RejectNilPredicates().Select("a").From("t").Where(pred).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in WHERE")
+
+	_, _, err = Select("a").From("t").JoinClause(nil).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in JOIN")
+
+	_, _, err = Select("a").From("t").SuffixExpr(pred).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in suffix")
+}
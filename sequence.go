@@ -0,0 +1,129 @@
+package squirrel
+
+import (
+	"fmt"
+)
+
+// sequenceExpr helps to use sequence values in SQL query
+type sequenceExpr struct {
+	name    string
+	next    bool
+	dialect Dialect
+}
+
+// NextVal allows to use the next value of a sequence, e.g. to pre-allocate IDs.
+// It is rendered as nextval('seq'), NEXTVAL(seq), seq.NEXTVAL or NEXT VALUE FOR seq
+// depending on the dialect. ClickHouse and BigQuery have no sequences.
+//
+// MySQL and SQLite have no sequences either: name is a table then, and the
+// expression reads its AUTO_INCREMENT counter from information_schema, or its
+// AUTOINCREMENT counter from sqlite_sequence. The value isn't reserved, so it's
+// only a hint for concurrent inserts.
+// Ex: Select().Column(NextVal("users_id_seq")) -> "SELECT nextval('users_id_seq')"
+func NextVal(name string) sequenceExpr {
+	return sequenceExpr{name: name, next: true}
+}
+
+// CurrVal allows to use the value last returned by NextVal in the current session.
+// For MySQL and SQLite it is rendered as LAST_INSERT_ID() and last_insert_rowid(),
+// name is ignored then. Snowflake, ClickHouse and BigQuery aren't supported.
+// Ex: CurrVal("users_id_seq").Dialect(DialectOracle) -> "users_id_seq.CURRVAL"
+func CurrVal(name string) sequenceExpr {
+	return sequenceExpr{name: name}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e sequenceExpr) Dialect(d Dialect) sequenceExpr {
+	e.dialect = d
+	return e
+}
+
+func (e sequenceExpr) ToSql() (string, []any, error) {
+	if len(e.name) == 0 {
+		return "", nil, fmt.Errorf("sequence name must not be empty")
+	}
+
+	d := e.dialect
+	if e.next {
+		switch d { //nolint:exhaustive
+		case DialectDefault, DialectPostgres, DialectDuckDB:
+			return fmt.Sprintf("nextval(%s)", d.stringLiteral(e.name)), nil, nil
+		case DialectMariaDB:
+			return fmt.Sprintf("NEXTVAL(%s)", e.name), nil, nil
+		case DialectOracle, DialectOracleLegacy, DialectSnowflake:
+			return e.name + ".NEXTVAL", nil, nil
+		case DialectMSSQL:
+			return "NEXT VALUE FOR " + e.name, nil, nil
+		case DialectMySQL:
+			return fmt.Sprintf("(SELECT AUTO_INCREMENT FROM information_schema.TABLES "+
+				"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = %s)", d.stringLiteral(e.name)), nil, nil
+		case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+			return fmt.Sprintf("(SELECT COALESCE(MAX(seq), 0) + 1 FROM sqlite_sequence WHERE name = %s)",
+				d.stringLiteral(e.name)), nil, nil
+		}
+		return "", nil, d.unsupportedError("NextVal")
+	}
+
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectDuckDB:
+		return fmt.Sprintf("currval(%s)", d.stringLiteral(e.name)), nil, nil
+	case DialectMariaDB:
+		return fmt.Sprintf("LASTVAL(%s)", e.name), nil, nil
+	case DialectOracle, DialectOracleLegacy:
+		return e.name + ".CURRVAL", nil, nil
+	case DialectMSSQL:
+		return fmt.Sprintf("(SELECT current_value FROM sys.sequences WHERE name = %s)", d.stringLiteral(e.name)), nil, nil
+	case DialectMySQL:
+		return "LAST_INSERT_ID()", nil, nil
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		return "last_insert_rowid()", nil, nil
+	}
+	return "", nil, d.unsupportedError("CurrVal")
+}
+
+// setValExpr helps to restart a sequence
+type setValExpr struct {
+	name    string
+	value   int64
+	dialect Dialect
+}
+
+// SetVal builds a statement making value the next value returned by NextVal.
+// It is rendered as a setval call or an ALTER SEQUENCE statement depending on the
+// dialect; for MySQL and SQLite name is a table and its AUTO_INCREMENT counter,
+// or its row of sqlite_sequence, is changed. DuckDB, Snowflake, ClickHouse,
+// BigQuery and Oracle before 12c can't restart a sequence.
+// Ex: SetVal("users_id_seq", 100) -> "SELECT setval('users_id_seq', 100, false)"
+func SetVal(name string, value int64) setValExpr {
+	return setValExpr{name: name, value: value}
+}
+
+// Dialect sets the dialect used to render the statement.
+func (e setValExpr) Dialect(d Dialect) setValExpr {
+	e.dialect = d
+	return e
+}
+
+func (e setValExpr) ToSql() (string, []any, error) {
+	if len(e.name) == 0 {
+		return "", nil, fmt.Errorf("sequence name must not be empty")
+	}
+
+	d := e.dialect
+	switch d { //nolint:exhaustive
+	case DialectDefault, DialectPostgres:
+		return fmt.Sprintf("SELECT setval(%s, %d, false)", d.stringLiteral(e.name), e.value), nil, nil
+	case DialectMariaDB:
+		return fmt.Sprintf("SELECT SETVAL(%s, %d, 0)", e.name, e.value), nil, nil
+	case DialectMSSQL:
+		return fmt.Sprintf("ALTER SEQUENCE %s RESTART WITH %d", e.name, e.value), nil, nil
+	case DialectOracle:
+		return fmt.Sprintf("ALTER SEQUENCE %s RESTART START WITH %d", e.name, e.value), nil, nil
+	case DialectMySQL:
+		return fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = %d", e.name, e.value), nil, nil
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		// sqlite_sequence holds the last value used
+		return fmt.Sprintf("UPDATE sqlite_sequence SET seq = %d WHERE name = %s", e.value-1, d.stringLiteral(e.name)), nil, nil
+	}
+	return "", nil, d.unsupportedError("SetVal")
+}
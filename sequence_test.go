@@ -0,0 +1,107 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextVal(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectDefault, "nextval('users_id_seq')"},
+		{DialectPostgres, "nextval('users_id_seq')"},
+		{DialectMariaDB, "NEXTVAL(users_id_seq)"},
+		{DialectOracle, "users_id_seq.NEXTVAL"},
+		{DialectSnowflake, "users_id_seq.NEXTVAL"},
+		{DialectMSSQL, "NEXT VALUE FOR users_id_seq"},
+		{DialectMySQL, "(SELECT AUTO_INCREMENT FROM information_schema.TABLES " +
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'users_id_seq')"},
+		{DialectDuckDB, "nextval('users_id_seq')"},
+		{DialectOracleLegacy, "users_id_seq.NEXTVAL"},
+		{DialectSQLite, "(SELECT COALESCE(MAX(seq), 0) + 1 FROM sqlite_sequence WHERE name = 'users_id_seq')"},
+		{DialectSQLiteUpdateDeleteLimit, "(SELECT COALESCE(MAX(seq), 0) + 1 FROM sqlite_sequence WHERE name = 'users_id_seq')"},
+	}
+	for _, tt := range tests {
+		sql, args, err := NextVal("users_id_seq").Dialect(tt.dialect).ToSql()
+		assert.NoError(t, err, tt.dialect)
+		assert.Equal(t, tt.want, sql, tt.dialect)
+		assert.Empty(t, args)
+	}
+
+	for _, d := range []Dialect{DialectClickHouse, DialectBigQuery} {
+		_, _, err := NextVal("users_id_seq").Dialect(d).ToSql()
+		assert.Error(t, err, d)
+	}
+
+	_, _, err := NextVal("").ToSql()
+	assert.Error(t, err)
+}
+
+func TestNextValInInsert(t *testing.T) {
+	sql, args, err := Insert("users").Columns("id", "name").
+		Values(NextVal("users_id_seq"), "John").
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES (nextval('users_id_seq'),$1)", sql)
+	assert.Equal(t, []any{"John"}, args)
+}
+
+func TestCurrVal(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, "currval('users_id_seq')"},
+		{DialectMariaDB, "LASTVAL(users_id_seq)"},
+		{DialectOracle, "users_id_seq.CURRVAL"},
+		{DialectMSSQL, "(SELECT current_value FROM sys.sequences WHERE name = N'users_id_seq')"},
+		{DialectMySQL, "LAST_INSERT_ID()"},
+		{DialectDefault, "currval('users_id_seq')"},
+		{DialectDuckDB, "currval('users_id_seq')"},
+		{DialectOracleLegacy, "users_id_seq.CURRVAL"},
+		{DialectSQLite, "last_insert_rowid()"},
+		{DialectSQLiteUpdateDeleteLimit, "last_insert_rowid()"},
+	}
+	for _, tt := range tests {
+		sql, _, err := CurrVal("users_id_seq").Dialect(tt.dialect).ToSql()
+		assert.NoError(t, err, tt.dialect)
+		assert.Equal(t, tt.want, sql, tt.dialect)
+	}
+
+	for _, d := range []Dialect{DialectSnowflake, DialectClickHouse, DialectBigQuery} {
+		_, _, err := CurrVal("users_id_seq").Dialect(d).ToSql()
+		assert.Error(t, err, d)
+	}
+}
+
+func TestSetVal(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, "SELECT setval('users_id_seq', 100, false)"},
+		{DialectMariaDB, "SELECT SETVAL(users_id_seq, 100, 0)"},
+		{DialectMSSQL, "ALTER SEQUENCE users_id_seq RESTART WITH 100"},
+		{DialectOracle, "ALTER SEQUENCE users_id_seq RESTART START WITH 100"},
+		{DialectMySQL, "ALTER TABLE users_id_seq AUTO_INCREMENT = 100"},
+		{DialectSQLite, "UPDATE sqlite_sequence SET seq = 99 WHERE name = 'users_id_seq'"},
+		{DialectSQLiteUpdateDeleteLimit, "UPDATE sqlite_sequence SET seq = 99 WHERE name = 'users_id_seq'"},
+	}
+	for _, tt := range tests {
+		sql, args, err := SetVal("users_id_seq", 100).Dialect(tt.dialect).ToSql()
+		assert.NoError(t, err, tt.dialect)
+		assert.Equal(t, tt.want, sql, tt.dialect)
+		assert.Empty(t, args)
+	}
+
+	for _, d := range []Dialect{DialectOracleLegacy, DialectDuckDB, DialectSnowflake, DialectClickHouse, DialectBigQuery} {
+		_, _, err := SetVal("users_id_seq", 100).Dialect(d).ToSql()
+		assert.Error(t, err, d)
+	}
+
+	_, _, err := SetVal("", 100).ToSql()
+	assert.Error(t, err)
+}
@@ -0,0 +1,258 @@
+package squirrel
+
+import (
+	"context"
+	"time"
+)
+
+// IntersectBuilder and ExceptBuilder share the setOpData of UnionBuilder, so
+// that all set operations are rendered and checked the same way: they embed a
+// setOpBuilder, whose methods convert the builder to a UnionBuilder and back.
+
+// named sets the name of the set operation in error messages.
+func (b UnionBuilder) named(name string) UnionBuilder {
+	return b.set(func(d *setOpData) { d.Name = name })
+}
+
+// setOpWrapper is implemented by the builders embedding a setOpBuilder.
+type setOpWrapper[B any] interface {
+	// setOpName is the name of the set operation in error messages.
+	setOpName() string
+	// wrap returns the builder of u.
+	wrap(u UnionBuilder) B
+}
+
+// setOpBuilder has the methods IntersectBuilder and ExceptBuilder share with
+// UnionBuilder, returning the embedding builder B.
+type setOpBuilder[B setOpWrapper[B]] struct {
+	core[setOpData]
+}
+
+// union returns b as a UnionBuilder named after B, zero value builders
+// included.
+func (b setOpBuilder[B]) union() UnionBuilder {
+	var w B
+	return UnionBuilder{b.core}.named(w.setOpName())
+}
+
+// apply returns the B of the UnionBuilder method f applied to b.
+func (b setOpBuilder[B]) apply(f func(u UnionBuilder) UnionBuilder) B {
+	var w B
+	return w.wrap(f(b.union()))
+}
+
+// OrderBy sets ORDER BY on the whole statement.
+func (b setOpBuilder[B]) OrderBy(exprs ...string) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.OrderBy(exprs...) })
+}
+
+// Limit sets LIMIT on the whole statement.
+func (b setOpBuilder[B]) Limit(n uint64) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.Limit(n) })
+}
+
+// LimitExpr sets LIMIT on the whole statement with an expression, see UnionBuilder.LimitExpr.
+func (b setOpBuilder[B]) LimitExpr(e Sqlizer) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.LimitExpr(e) })
+}
+
+// FetchFirst sets FETCH FIRST n ROWS on the whole statement, see UnionBuilder.FetchFirst.
+func (b setOpBuilder[B]) FetchFirst(n uint64) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.FetchFirst(n) })
+}
+
+// WithTies makes FetchFirst return the rows tied with the last one as well.
+func (b setOpBuilder[B]) WithTies() B {
+	return b.apply(UnionBuilder.WithTies)
+}
+
+// Offset sets OFFSET on the whole statement.
+func (b setOpBuilder[B]) Offset(n uint64) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.Offset(n) })
+}
+
+// OffsetExpr sets OFFSET on the whole statement with an expression, see LimitExpr.
+func (b setOpBuilder[B]) OffsetExpr(e Sqlizer) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.OffsetExpr(e) })
+}
+
+// PrefixExpr prepends a leading expression to the statement, see
+// UnionBuilder.PrefixExpr.
+func (b setOpBuilder[B]) PrefixExpr(e Sqlizer) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.PrefixExpr(e) })
+}
+
+// Suffix appends trailing SQL fragments (e.g., comments/hints) to the statement.
+func (b setOpBuilder[B]) Suffix(exprs ...Sqlizer) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.Suffix(exprs...) })
+}
+
+// SuffixExpr appends a single trailing expression to the statement.
+func (b setOpBuilder[B]) SuffixExpr(e Sqlizer) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.SuffixExpr(e) })
+}
+
+// PlaceholderFormat sets the placeholder format (Question, Dollar, Colon, etc.).
+func (b setOpBuilder[B]) PlaceholderFormat(f PlaceholderFormat) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.PlaceholderFormat(f) })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when the statement has more than
+// n subqueries. 0 means no limit.
+func (b setOpBuilder[B]) MaxParts(n int) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.MaxParts(n) })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement is over l.
+func (b setOpBuilder[B]) SizeLimit(l SizeLimit) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.SizeLimit(l) })
+}
+
+// TimeLocation converts the time.Time args of the query to loc when it is built.
+func (b setOpBuilder[B]) TimeLocation(loc *time.Location) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.TimeLocation(loc) })
+}
+
+// Dialect sets the target database engine for the statement, and its preferred
+// placeholder format.
+func (b setOpBuilder[B]) Dialect(d Dialect) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.Dialect(d) })
+}
+
+// CheckColumnCount makes ToSql check that the subqueries select the same number
+// of columns, see UnionBuilder.CheckColumnCount.
+func (b setOpBuilder[B]) CheckColumnCount() B {
+	return b.apply(UnionBuilder.CheckColumnCount)
+}
+
+// Align rewrites every subquery to select exactly columns in this order, see
+// UnionBuilder.Align.
+func (b setOpBuilder[B]) Align(columns ...string) B {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.Align(columns...) })
+}
+
+// Compact enables one-line SQL output (no newlines / duplicate spaces).
+func (b setOpBuilder[B]) Compact() B {
+	return b.apply(UnionBuilder.Compact)
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (b setOpBuilder[B]) ToSql() (string, []any, error) {
+	return b.union().ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done, see UnionBuilder.ToSqlContext.
+func (b setOpBuilder[B]) ToSqlContext(ctx context.Context) (string, []any, error) {
+	return b.union().ToSqlContext(ctx)
+}
+
+func (b setOpBuilder[B]) toSqlRaw() (string, []any, error) {
+	return b.union().toSqlRaw()
+}
+
+func (b setOpBuilder[B]) toSqlRawContext(ctx context.Context) (string, []any, error) {
+	return b.union().toSqlRawContext(ctx)
+}
+
+// MustSql builds the query into a SQL string and bound args.
+// It panics if there are any errors.
+func (b setOpBuilder[B]) MustSql() (string, []any) {
+	return b.union().MustSql()
+}
+
+// EstimatedSize returns the size of the statement and of its clauses, see
+// StatementSize. The subqueries are accounted for as a single INTERSECT or
+// EXCEPT clause.
+func (b setOpBuilder[B]) EstimatedSize() (StatementSize, error) {
+	return b.union().EstimatedSize()
+}
+
+// ---------------- INTERSECT ----------------
+
+// IntersectBuilder builds SQL for (SELECT ...) INTERSECT [ALL] (SELECT ...) ... chains, with the
+// same semantics and options as UnionBuilder: the subselects are parenthesized
+// and ORDER BY / LIMIT / OFFSET apply to the whole intersection.
+type IntersectBuilder struct {
+	setOpBuilder[IntersectBuilder]
+}
+
+// ensure we satisfy Sqlizer at compile time.
+var _ Sqlizer = (IntersectBuilder{})
+
+func (IntersectBuilder) setOpName() string { return "intersect" }
+
+func (IntersectBuilder) wrap(u UnionBuilder) IntersectBuilder {
+	return IntersectBuilder{setOpBuilder[IntersectBuilder]{u.core}}
+}
+
+// Intersect constructs a INTERSECT (DISTINCT) chain with the given subqueries.
+// Ex: Intersect(Select("id").From("users"), Select("user_id").From("orders"))
+// -> (SELECT id FROM users) INTERSECT (SELECT user_id FROM orders)
+func Intersect(parts ...Sqlizer) IntersectBuilder {
+	return IntersectBuilder{}.appendParts("Intersect", intersectDistinct, parts)
+}
+
+// IntersectAll constructs a INTERSECT ALL chain with the given subqueries.
+func IntersectAll(parts ...Sqlizer) IntersectBuilder {
+	return IntersectBuilder{}.appendParts("IntersectAll", intersectAll, parts)
+}
+
+func (b IntersectBuilder) appendParts(method string, op setOp, parts []Sqlizer) IntersectBuilder {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.appendParts(method, op, parts) })
+}
+
+// Intersect appends another subquery with INTERSECT (DISTINCT).
+func (b IntersectBuilder) Intersect(q Sqlizer) IntersectBuilder {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.appendPart("Intersect", intersectDistinct, q) })
+}
+
+// IntersectAll appends another subquery with INTERSECT ALL.
+func (b IntersectBuilder) IntersectAll(q Sqlizer) IntersectBuilder {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.appendPart("IntersectAll", intersectAll, q) })
+}
+
+// ---------------- EXCEPT ----------------
+
+// ExceptBuilder builds SQL for (SELECT ...) EXCEPT [ALL] (SELECT ...) ... chains, with the
+// same semantics and options as UnionBuilder: the subselects are parenthesized
+// and ORDER BY / LIMIT / OFFSET apply to the whole difference.
+type ExceptBuilder struct {
+	setOpBuilder[ExceptBuilder]
+}
+
+// ensure we satisfy Sqlizer at compile time.
+var _ Sqlizer = (ExceptBuilder{})
+
+func (ExceptBuilder) setOpName() string { return "except" }
+
+func (ExceptBuilder) wrap(u UnionBuilder) ExceptBuilder {
+	return ExceptBuilder{setOpBuilder[ExceptBuilder]{u.core}}
+}
+
+// Except constructs a EXCEPT (DISTINCT) chain with the given subqueries.
+// Ex: Except(Select("id").From("users"), Select("user_id").From("banned_users"))
+// -> (SELECT id FROM users) EXCEPT (SELECT user_id FROM banned_users)
+func Except(parts ...Sqlizer) ExceptBuilder {
+	return ExceptBuilder{}.appendParts("Except", exceptDistinct, parts)
+}
+
+// ExceptAll constructs a EXCEPT ALL chain with the given subqueries.
+func ExceptAll(parts ...Sqlizer) ExceptBuilder {
+	return ExceptBuilder{}.appendParts("ExceptAll", exceptAll, parts)
+}
+
+func (b ExceptBuilder) appendParts(method string, op setOp, parts []Sqlizer) ExceptBuilder {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.appendParts(method, op, parts) })
+}
+
+// Except appends another subquery with EXCEPT (DISTINCT).
+func (b ExceptBuilder) Except(q Sqlizer) ExceptBuilder {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.appendPart("Except", exceptDistinct, q) })
+}
+
+// ExceptAll appends another subquery with EXCEPT ALL.
+func (b ExceptBuilder) ExceptAll(q Sqlizer) ExceptBuilder {
+	return b.apply(func(u UnionBuilder) UnionBuilder { return u.appendPart("ExceptAll", exceptAll, q) })
+}
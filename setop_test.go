@@ -0,0 +1,192 @@
+package squirrel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntersect(t *testing.T) {
+	sql, args, err := Intersect(
+		Select("id").From("users").Where(Eq{"active": true}),
+		Select("user_id").From("orders").Where(Expr("total > ?", 100)),
+	).IntersectAll(Select("user_id").From("reviews")).
+		OrderBy("id").Limit(10).Offset(20).
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM users WHERE active = $1) "+
+		"INTERSECT (SELECT user_id FROM orders WHERE total > $2) "+
+		"INTERSECT ALL (SELECT user_id FROM reviews) ORDER BY id LIMIT 10 OFFSET 20", sql)
+	assert.Equal(t, []any{true, 100}, args)
+
+	sql, _, err = IntersectAll(Select("id").From("a"), Select("id").From("b")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a) INTERSECT ALL (SELECT id FROM b)", sql)
+}
+
+func TestExcept(t *testing.T) {
+	sql, args, err := Except(
+		Select("id").From("users"),
+		Select("user_id").From("banned_users").Where(Expr("until > ?", 1)),
+	).Except(Select("user_id").From("deleted_users")).
+		OrderBy("id DESC").LimitExpr(Expr("?", 5)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM users) "+
+		"EXCEPT (SELECT user_id FROM banned_users WHERE until > ?) "+
+		"EXCEPT (SELECT user_id FROM deleted_users) ORDER BY id DESC LIMIT ?", sql)
+	assert.Equal(t, []any{1, 5}, args)
+
+	sql, _, err = ExceptAll(Select("id").From("a")).ExceptAll(Select("id").From("b")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a) EXCEPT ALL (SELECT id FROM b)", sql)
+}
+
+func TestSetOpDialects(t *testing.T) {
+	a, b := Select("id").From("a"), Select("id").From("b")
+	tests := []struct {
+		q    Sqlizer
+		want string
+	}{
+		{Except(a, b).Dialect(DialectOracle), "(SELECT id FROM a) MINUS (SELECT id FROM b)"},
+		{Intersect(a, b).Dialect(DialectOracle), "(SELECT id FROM a) INTERSECT (SELECT id FROM b)"},
+		{Except(a, b).Dialect(DialectBigQuery), "(SELECT id FROM a) EXCEPT DISTINCT (SELECT id FROM b)"},
+		{Intersect(a, b).Dialect(DialectClickHouse), "SELECT id FROM a INTERSECT DISTINCT SELECT id FROM b"},
+		{Except(a, b).Dialect(DialectSQLite), "SELECT id FROM a EXCEPT SELECT id FROM b"},
+		{ExceptAll(a, b).Dialect(DialectPostgres), "(SELECT id FROM a) EXCEPT ALL (SELECT id FROM b)"},
+		{IntersectAll(a, b).Dialect(DialectMySQL), "(SELECT id FROM a) INTERSECT ALL (SELECT id FROM b)"},
+		{
+			Intersect(a, b).OrderBy("id").Limit(5).Dialect(DialectClickHouse),
+			"SELECT * FROM (SELECT id FROM a INTERSECT DISTINCT SELECT id FROM b) ORDER BY id LIMIT 5",
+		},
+	}
+	for _, tt := range tests {
+		sql, _, err := tt.q.ToSql()
+		assert.NoError(t, err, tt.want)
+		assert.Equal(t, tt.want, sql)
+	}
+
+	for _, d := range []Dialect{DialectSQLite, DialectMSSQL, DialectOracle, DialectBigQuery, DialectSnowflake, DialectClickHouse} {
+		_, _, err := IntersectAll(a, b).Dialect(d).ToSql()
+		assert.Error(t, err, d.String())
+		_, _, err = ExceptAll(a, b).Dialect(d).ToSql()
+		assert.Error(t, err, d.String())
+	}
+}
+
+func TestSetOpNested(t *testing.T) {
+	sql, _, err := Union(
+		Intersect(Select("id").From("a"), Select("id").From("b")),
+		Except(Select("id").From("c"), Select("id").From("d")),
+	).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "((SELECT id FROM a) INTERSECT (SELECT id FROM b)) "+
+		"UNION ((SELECT id FROM c) EXCEPT (SELECT id FROM d))", sql)
+
+	sql, args, err := Select("*").From("users").
+		Where(Expr("id IN (?)", Except(Select("id").From("a"), Select("id").From("b").Where(Eq{"x": 1})))).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id IN ((SELECT id FROM a) EXCEPT (SELECT id FROM b WHERE x = ?))", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, _, err = With("recent", Select("id").From("orders")).
+		Except(Except(Select("id").From("users"), Select("id").From("recent"))).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH recent AS (SELECT id FROM orders) (SELECT id FROM users) EXCEPT (SELECT id FROM recent)", sql)
+}
+
+func TestSetOpStatementBuilder(t *testing.T) {
+	b := StatementBuilder.Dialect(DialectPostgres).MaxParts(2)
+
+	sql, args, err := b.Intersect(Select("id").From("a").Where(Eq{"x": 1}), Select("id").From("b")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a WHERE x = $1) INTERSECT (SELECT id FROM b)", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, _, err = b.ExceptAll(Select("id").From("a"), Select("id").From("b")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a) EXCEPT ALL (SELECT id FROM b)", sql)
+
+	_, _, err = b.Except(Select("id").From("a"), Select("id").From("b"), Select("id").From("c")).ToSql()
+	var tooMany *TooManyPartsError
+	if assert.True(t, errors.As(err, &tooMany)) {
+		assert.Equal(t, "EXCEPT", tooMany.Clause)
+	}
+}
+
+func TestSetOpErrors(t *testing.T) {
+	_, _, err := Intersect().ToSql()
+	assert.EqualError(t, err, "squirrel: intersect requires at least one SELECT")
+
+	_, _, err = Except(Select("id").From("a"), nil).ToSql()
+	assert.ErrorIs(t, err, ErrNilSqlizer)
+	assert.Contains(t, err.Error(), "in Except subquery 2")
+
+	_, _, err = Intersect(Select("id").From("a")).IntersectAll(nil).ToSql()
+	assert.ErrorIs(t, err, ErrNilSqlizer)
+
+	_, _, err = Except(Select("id", "name").From("a"), Select("id").From("b")).CheckColumnCount().ToSql()
+	assert.EqualError(t, err, "squirrel: except subquery 1 selects 1 columns, but subquery 0 selects 2")
+
+	// zero value builders are named after their set operation too
+	_, _, err = IntersectBuilder{}.ToSql()
+	assert.EqualError(t, err, "squirrel: intersect requires at least one SELECT")
+	_, _, err = ExceptBuilder{}.Except(Select("id").From("a")).WithTies().ToSql()
+	assert.EqualError(t, err, "squirrel: except WITH TIES requires FetchFirst")
+	sql, _, err := ExceptBuilder{}.Except(Select("id").From("a")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a)", sql)
+
+	count, ok := unionColumnCount(Intersect(Select("id", "name").From("a")))
+	assert.True(t, ok)
+	assert.Equal(t, 2, count)
+}
+
+func TestSetOpOptions(t *testing.T) {
+	sql, _, err := Intersect(
+		Select("id", "name").From("users"),
+		Select("id", "title AS name", "url").From("pages"),
+	).Align("id", "name").PrefixExpr(Expr("/* report */")).Suffix(Expr("FOR UPDATE")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "/* report */ (SELECT id, name FROM users) INTERSECT (SELECT id, title AS name FROM pages) FOR UPDATE", sql)
+
+	sql, _, err = Except(Select("id").From("a"), Select("id").From("b")).
+		OrderBy("id").FetchFirst(3).WithTies().Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a) EXCEPT (SELECT id FROM b) ORDER BY id FETCH FIRST 3 ROWS WITH TIES", sql)
+
+	size, err := Except(Select("id").From("a").Where(Eq{"x": 1}), Select("id").From("b")).EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, size.Args)
+	if assert.Len(t, size.Clauses, 1) {
+		assert.Equal(t, "EXCEPT", size.Clauses[0].Clause)
+	}
+}
+
+func TestSetOpNestedDollar(t *testing.T) {
+	psql := StatementBuilder.PlaceholderFormat(Dollar)
+	a := psql.Select("id").From("a").Where("x = ?", 1)
+	b := psql.Select("id").From("b").Where("y = ?", 2)
+
+	for _, q := range []struct {
+		op  string
+		set Sqlizer
+	}{
+		{"INTERSECT", psql.Intersect(a, b)},
+		{"EXCEPT", psql.Except(a, b)},
+	} {
+		sql, args, err := psql.Select("*").From("t").Where(Eq{"z": 0}).Where(Expr("id IN (?)", q.set)).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM t WHERE z = $1 AND id IN ((SELECT id FROM a WHERE x = $2) "+
+			q.op+" (SELECT id FROM b WHERE y = $3))", sql)
+		assert.Equal(t, []any{0, 1, 2}, args)
+
+		sql, args, err = psql.With("c", psql.Select("id").From("d").Where("w = ?", 0)).
+			Select(psql.Select("*").From("c").Where(Expr("id IN (?)", q.set))).ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, "WITH c AS (SELECT id FROM d WHERE w = $1) SELECT * FROM c WHERE id IN ((SELECT id FROM a WHERE x = $2) "+
+			q.op+" (SELECT id FROM b WHERE y = $3))", sql)
+		assert.Equal(t, []any{0, 1, 2}, args)
+	}
+}
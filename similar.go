@@ -0,0 +1,84 @@
+package squirrel
+
+import (
+	"fmt"
+)
+
+// similarExpr helps to match strings similar to a search query
+type similarExpr struct {
+	column    string
+	query     string
+	threshold float64
+	dialect   Dialect
+}
+
+// Similar allows to match the rows where column is similar to query, using the
+// trigram matching of the pg_trgm extension for PostgreSQL: col % ? when threshold
+// is 0, which uses the pg_trgm.similarity_threshold setting (0.3 by default) and a
+// trigram index, similarity(col, ?) > ? otherwise. Other dialects fall back to a
+// case-sensitive substring match, see Contains.
+// Ex: Similar("name", "squirel", 0) -> "name % ?"
+func Similar(column, query string, threshold float64) similarExpr {
+	return similarExpr{column: column, query: query, threshold: threshold}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e similarExpr) Dialect(d Dialect) similarExpr {
+	e.dialect = d
+	return e
+}
+
+func (e similarExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 {
+		return "", nil, fmt.Errorf("similar requires a column")
+	}
+
+	switch {
+	case e.dialect != DialectDefault && e.dialect != DialectPostgres:
+		return Contains(e.column, e.query).Dialect(e.dialect).ToSql()
+	case e.threshold > 0:
+		return fmt.Sprintf("similarity(%s, ?) > ?", e.column), []any{e.query, e.threshold}, nil
+	}
+	return fmt.Sprintf("%s %% ?", e.column), []any{e.query}, nil
+}
+
+// similarityDistanceExpr helps to order rows by similarity to a search query
+type similarityDistanceExpr struct {
+	column  string
+	query   string
+	dialect Dialect
+}
+
+// SimilarityDistance allows to order rows from the most to the least similar to
+// query: the trigram distance col <-> ? for PostgreSQL (pg_trgm), which can use a
+// GiST trigram index. Other dialects fall back to ranking the rows starting with
+// query first.
+// Ex:
+//
+//	Select("*").From("products").Where(Similar("name", q, 0)).
+//		OrderByClause(SimilarityDistance("name", q)).Limit(10)
+//	// SELECT * FROM products WHERE name % ? ORDER BY name <-> ? LIMIT 10
+func SimilarityDistance(column, query string) similarityDistanceExpr {
+	return similarityDistanceExpr{column: column, query: query}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e similarityDistanceExpr) Dialect(d Dialect) similarityDistanceExpr {
+	e.dialect = d
+	return e
+}
+
+func (e similarityDistanceExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 {
+		return "", nil, fmt.Errorf("similarity distance requires a column")
+	}
+
+	if e.dialect != DialectDefault && e.dialect != DialectPostgres {
+		sql, args, err := StartsWith(e.column, e.query).Dialect(e.dialect).ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("CASE WHEN %s THEN 0 ELSE 1 END", sql), args, nil
+	}
+	return fmt.Sprintf("%s <-> ?", e.column), []any{e.query}, nil
+}
@@ -0,0 +1,39 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilar(t *testing.T) {
+	sql, args, err := Select("*").From("products").Where(Similar("name", "squirel", 0)).
+		OrderByClause(SimilarityDistance("name", "squirel")).Limit(10).
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM products WHERE name % $1 ORDER BY name <-> $2 LIMIT 10", sql)
+	assert.Equal(t, []any{"squirel", "squirel"}, args)
+
+	sql, args, err = Similar("name", "squirel", 0.5).Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "similarity(name, ?) > ?", sql)
+	assert.Equal(t, []any{"squirel", 0.5}, args)
+
+	_, _, err = Similar("", "squirel", 0).ToSql()
+	assert.Error(t, err)
+}
+
+func TestSimilarFallback(t *testing.T) {
+	sql, args, err := Similar("name", "50%", 0.5).Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `name LIKE ? ESCAPE '\'`, sql)
+	assert.Equal(t, []any{`%50\%%`}, args)
+
+	sql, args, err = SimilarityDistance("name", "sq").Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, `CASE WHEN name LIKE ? ESCAPE '\\' THEN 0 ELSE 1 END`, sql)
+	assert.Equal(t, []any{`sq%`}, args)
+
+	_, _, err = SimilarityDistance("", "sq").ToSql()
+	assert.Error(t, err)
+}
@@ -0,0 +1,348 @@
+package squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SizeLimit is the maximum size of a rendered statement, e.g. the 65535 bound
+// args of PostgreSQL. Zero fields mean no limit.
+type SizeLimit struct {
+	Bytes int // length of the SQL
+	Args  int // number of bound args
+}
+
+// ClauseSize is the size of the parts of a clause of a statement.
+type ClauseSize struct {
+	Clause string
+	Bytes  int
+	Args   int
+}
+
+// StatementSize is the estimated size of a statement, and of its clauses.
+// Keywords and separators are not accounted for in the clauses, and numbered
+// placeholders are counted as ?, so Bytes is a lower bound of the length of the
+// SQL.
+type StatementSize struct {
+	Bytes   int
+	Args    int
+	Clauses []ClauseSize
+}
+
+// StatementTooLargeError is returned by ToSql when the rendered statement is over
+// the SizeLimit of the builder. ToSql stops at the first clause over the limit,
+// so Size is the size of the statement up to that clause, and unlike the
+// EstimatedSize of a builder its clauses include their keywords.
+type StatementTooLargeError struct {
+	Size  StatementSize
+	Limit SizeLimit
+}
+
+func (e *StatementTooLargeError) Error() string {
+	var over []string
+	if e.Limit.Bytes > 0 && e.Size.Bytes > e.Limit.Bytes {
+		over = append(over, fmt.Sprintf("%d bytes, more than the limit of %d", e.Size.Bytes, e.Limit.Bytes))
+	}
+	if e.Limit.Args > 0 && e.Size.Args > e.Limit.Args {
+		over = append(over, fmt.Sprintf("%d args, more than the limit of %d", e.Size.Args, e.Limit.Args))
+	}
+	msg := "squirrel: statement has " + strings.Join(over, " and ")
+	if c, ok := e.largestClause(); ok {
+		msg += fmt.Sprintf(" (largest clause: %s with %d bytes and %d args)", c.Clause, c.Bytes, c.Args)
+	}
+	return msg
+}
+
+// largestClause returns the clause with the most args, or bytes if the args are
+// within the limit.
+func (e *StatementTooLargeError) largestClause() (ClauseSize, bool) {
+	byArgs := e.Limit.Args > 0 && e.Size.Args > e.Limit.Args
+	var largest ClauseSize
+	for _, c := range e.Size.Clauses {
+		if byArgs && c.Args > largest.Args || !byArgs && c.Bytes > largest.Bytes {
+			largest = c
+		}
+	}
+	return largest, largest.Clause != ""
+}
+
+// exceeded reports whether a statement of the given size is over the limit.
+func (l SizeLimit) exceeded(bytes, args int) bool {
+	return l.Bytes > 0 && bytes > l.Bytes || l.Args > 0 && args > l.Args
+}
+
+// sizeTracker accounts for the clauses of a statement while ToSql renders it, so
+// that ToSql stops as soon as the statement is over the SizeLimit, without
+// rendering it again to size its clauses. A nil sizeTracker, for builders
+// without SizeLimit, does nothing.
+type sizeTracker struct {
+	limit SizeLimit
+	size  StatementSize // of the clauses rendered so far
+}
+
+func newSizeTracker(l SizeLimit) *sizeTracker {
+	if l == (SizeLimit{}) {
+		return nil
+	}
+	return &sizeTracker{limit: l}
+}
+
+// clause accounts for the SQL and args rendered since the previous clause as
+// clause, bytes and args being the size of the statement so far, and returns a
+// StatementTooLargeError if it is over the limit.
+func (t *sizeTracker) clause(clause string, bytes, args int) error {
+	if t == nil {
+		return nil
+	}
+	bytes, args = bytes-t.size.Bytes, args-t.size.Args
+	if n := len(t.size.Clauses); n > 0 && t.size.Clauses[n-1].Clause == clause {
+		// e.g. the rows of VALUES, accounted for one at a time
+		t.size.Bytes += bytes
+		t.size.Args += args
+		t.size.Clauses[n-1].Bytes += bytes
+		t.size.Clauses[n-1].Args += args
+	} else {
+		t.size.add(clause, bytes, args)
+	}
+	return t.check(t.size.Bytes, t.size.Args)
+}
+
+// check returns a StatementTooLargeError, with the clauses accounted for so far,
+// if a statement of the given size is over the limit.
+func (t *sizeTracker) check(bytes, args int) error {
+	if t == nil || !t.limit.exceeded(bytes, args) {
+		return nil
+	}
+	size := StatementSize{Bytes: bytes, Args: args, Clauses: append([]ClauseSize(nil), t.size.Clauses...)}
+	return &StatementTooLargeError{Size: size, Limit: t.limit}
+}
+
+// add accounts for a clause of the given SQL and args.
+func (s *StatementSize) add(clause string, bytes, args int) {
+	if bytes == 0 && args == 0 {
+		return
+	}
+	s.Bytes += bytes
+	s.Args += args
+	s.Clauses = append(s.Clauses, ClauseSize{Clause: clause, Bytes: bytes, Args: args})
+}
+
+// addParts renders parts to account for them as clause.
+func (s *StatementSize) addParts(clause string, parts ...Sqlizer) error {
+	bytes, args := 0, 0
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		sql, partArgs, err := nestedToSql(p)
+		if err != nil {
+			return err
+		}
+		bytes += len(sql)
+		args += len(partArgs)
+	}
+	s.add(clause, bytes, args)
+	return nil
+}
+
+// addStrings accounts for the SQL strings as clause.
+func (s *StatementSize) addStrings(clause string, sqls ...string) {
+	bytes := 0
+	for _, sql := range sqls {
+		bytes += len(sql)
+	}
+	s.add(clause, bytes, 0)
+}
+
+// valuesSize returns the size of the values of an INSERT, without rendering the
+// values which aren't Sqlizers.
+func valuesSize(rows [][]any) (bytes, args int, err error) {
+	for _, row := range rows {
+		for _, v := range row {
+			if s, ok := v.(Sqlizer); ok {
+				sql, vArgs, err := nestedToSql(s)
+				if err != nil {
+					return 0, 0, err
+				}
+				bytes += len(sql)
+				args += len(vArgs)
+			} else {
+				bytes++
+				args++
+			}
+		}
+	}
+	return bytes, args, nil
+}
+
+// EstimatedSize returns the size of the statement and of its clauses, see
+// StatementSize.
+func (b SelectBuilder) EstimatedSize() (StatementSize, error) {
+	d := b.get()
+	return d.estimatedSize()
+}
+
+func (d *selectData) estimatedSize() (StatementSize, error) {
+	var s StatementSize
+	for _, c := range []struct {
+		clause string
+		parts  []Sqlizer
+	}{
+		{"prefix", d.Prefixes},
+		{"SELECT", d.Columns},
+		{"FROM", []Sqlizer{d.From}},
+		{"JOIN", d.Joins},
+		{"WHERE", d.WhereParts},
+		{"HAVING", d.HavingParts},
+		{"ORDER BY", d.OrderByParts},
+		{"suffix", d.Suffixes},
+	} {
+		if err := s.addParts(c.clause, c.parts...); err != nil {
+			return StatementSize{}, err
+		}
+	}
+	s.addStrings("GROUP BY", d.GroupBys...)
+	return s, nil
+}
+
+// EstimatedSize returns the size of the statement and of its clauses, see
+// StatementSize. The values which aren't Sqlizers are not rendered.
+func (b InsertBuilder) EstimatedSize() (StatementSize, error) {
+	d := b.get()
+	return d.estimatedSize()
+}
+
+func (d *insertData) estimatedSize() (StatementSize, error) {
+	var s StatementSize
+	if err := s.addParts("prefix", d.Prefixes...); err != nil {
+		return StatementSize{}, err
+	}
+	s.addStrings("INTO", d.Into)
+	s.addStrings("columns", d.Columns...)
+	if d.Select != nil {
+		if err := s.addParts("SELECT", *d.Select); err != nil {
+			return StatementSize{}, err
+		}
+	} else {
+		bytes, args, err := valuesSize(d.Values)
+		if err != nil {
+			return StatementSize{}, err
+		}
+		s.add("VALUES", bytes, args)
+	}
+	if err := s.addParts("suffix", d.Suffixes...); err != nil {
+		return StatementSize{}, err
+	}
+	return s, nil
+}
+
+// EstimatedSize returns the size of the statement and of its clauses, see
+// StatementSize.
+func (b UpdateBuilder) EstimatedSize() (StatementSize, error) {
+	d := b.get()
+	return d.estimatedSize()
+}
+
+func (d *updateData) estimatedSize() (StatementSize, error) {
+	var s StatementSize
+	if err := s.addParts("prefix", d.Prefixes...); err != nil {
+		return StatementSize{}, err
+	}
+	s.addStrings("UPDATE", d.Table)
+	values := make([][]any, len(d.SetClauses))
+	columns := make([]string, len(d.SetClauses))
+	for i, c := range d.SetClauses {
+		values[i], columns[i] = []any{c.value}, c.column
+	}
+	bytes, args, err := valuesSize(values)
+	if err != nil {
+		return StatementSize{}, err
+	}
+	s.add("SET", bytes+len(strings.Join(columns, "")), args)
+	for _, c := range []struct {
+		clause string
+		parts  []Sqlizer
+	}{
+		{"FROM", []Sqlizer{d.From}},
+		{"WHERE", d.WhereParts},
+		{"suffix", d.Suffixes},
+	} {
+		if err := s.addParts(c.clause, c.parts...); err != nil {
+			return StatementSize{}, err
+		}
+	}
+	return s, nil
+}
+
+// EstimatedSize returns the size of the statement and of its clauses, see
+// StatementSize.
+func (b DeleteBuilder) EstimatedSize() (StatementSize, error) {
+	d := b.get()
+	return d.estimatedSize()
+}
+
+func (d *deleteData) estimatedSize() (StatementSize, error) {
+	var s StatementSize
+	if err := s.addParts("prefix", d.Prefixes...); err != nil {
+		return StatementSize{}, err
+	}
+	s.addStrings("FROM", d.From)
+	if err := s.addParts("WHERE", d.WhereParts...); err != nil {
+		return StatementSize{}, err
+	}
+	if err := s.addParts("suffix", d.Suffixes...); err != nil {
+		return StatementSize{}, err
+	}
+	return s, nil
+}
+
+// EstimatedSize returns the size of the union and of its clauses, see
+// StatementSize. The subqueries are accounted for as a single UNION clause.
+func (b UnionBuilder) EstimatedSize() (StatementSize, error) {
+	d := b.get()
+	return d.estimatedSize()
+}
+
+func (d *setOpData) estimatedSize() (StatementSize, error) {
+	var s StatementSize
+	if err := s.addParts("prefix", d.Prefixes...); err != nil {
+		return StatementSize{}, err
+	}
+	queries := make([]Sqlizer, len(d.Parts))
+	for i, p := range d.Parts {
+		queries[i] = p.query
+	}
+	if err := s.addParts(strings.ToUpper(d.name()), queries...); err != nil {
+		return StatementSize{}, err
+	}
+	s.addStrings("ORDER BY", d.OrderBy...)
+	if err := s.addParts("suffix", d.Suffixes...); err != nil {
+		return StatementSize{}, err
+	}
+	return s, nil
+}
+
+// EstimatedSize returns the size of the statement and of its clauses, see
+// StatementSize. The ctes are accounted for as a single WITH clause.
+func (b CommonTableExpressionsBuilder) EstimatedSize() (StatementSize, error) {
+	d := b.get()
+	return d.estimatedSize()
+}
+
+func (d *commonTableExpressionsData) estimatedSize() (StatementSize, error) {
+	var s StatementSize
+	for _, c := range []struct {
+		clause string
+		parts  []Sqlizer
+	}{
+		{"prefix", d.Prefixes},
+		{"WITH", d.Ctes},
+		{"statement", []Sqlizer{d.Statement}},
+		{"suffix", d.Suffixes},
+	} {
+		if err := s.addParts(c.clause, c.parts...); err != nil {
+			return StatementSize{}, err
+		}
+	}
+	return s, nil
+}
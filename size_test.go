@@ -0,0 +1,122 @@
+package squirrel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimatedSize(t *testing.T) {
+	size, err := Select("id", "name").From("users").
+		Where(Eq{"id": []int{1, 2, 3}}).Where("deleted_at IS NULL").
+		OrderBy("id").EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, StatementSize{Bytes: 44, Args: 3, Clauses: []ClauseSize{
+		{"SELECT", 6, 0},
+		{"FROM", 5, 0},
+		{"WHERE", 31, 3},
+		{"ORDER BY", 2, 0},
+	}}, size)
+
+	size, err = Insert("users").Columns("id", "name").Values(1, "a").Values(2, Expr("UPPER(?)", "b")).EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, StatementSize{Bytes: 22, Args: 4, Clauses: []ClauseSize{
+		{"INTO", 5, 0},
+		{"columns", 6, 0},
+		{"VALUES", 11, 4},
+	}}, size)
+
+	size, err = Update("users").Set("name", "a").Where(Eq{"id": 1}).EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, size.Args)
+
+	size, err = Delete("users").Where(Eq{"id": 1}).EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, size.Args)
+
+	size, err = Union(Select("id").From("a").Where(Eq{"x": 1}), Select("id").From("b")).EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, []ClauseSize{{"UNION", 44, 1}}, size.Clauses)
+
+	_, err = Select("id").Where(Expr("a = ?")).Where(nilSqlizer{"WHERE"}).EstimatedSize()
+	assert.Error(t, err)
+}
+
+func TestSizeLimit(t *testing.T) {
+	b := StatementBuilder.Dialect(DialectPostgres).SizeLimit(SizeLimit{Args: 3})
+
+	_, _, err := b.Select("*").From("users").Where(Eq{"id": []int{1, 2, 3}}).ToSql()
+	assert.NoError(t, err)
+
+	_, _, err = b.Select("*").From("users").Where(Eq{"id": []int{1, 2, 3, 4}}).ToSql()
+	var tooLarge *StatementTooLargeError
+	if assert.True(t, errors.As(err, &tooLarge)) {
+		assert.Equal(t, 4, tooLarge.Size.Args)
+		assert.Equal(t, []ClauseSize{{"SELECT", 8, 0}, {"FROM", 11, 0}, {"WHERE", 22, 4}}, tooLarge.Size.Clauses)
+	}
+	assert.EqualError(t, err, "squirrel: statement has 4 args, more than the limit of 3 "+
+		"(largest clause: WHERE with 22 bytes and 4 args)")
+
+	_, _, err = b.Insert("t").Values(1, 2).Values(3, 4).ToSql()
+	assert.EqualError(t, err, "squirrel: statement has 4 args, more than the limit of 3 "+
+		"(largest clause: VALUES with 18 bytes and 4 args)")
+
+	_, _, err = b.Update("t").Set("a", 1).Where(Eq{"id": []int{1, 2, 3}}).ToSql()
+	assert.True(t, errors.As(err, &tooLarge))
+
+	_, _, err = b.Delete("t").Where(Eq{"id": []int{1, 2, 3, 4}}).ToSql()
+	assert.True(t, errors.As(err, &tooLarge))
+
+	_, _, err = b.Union(Select("*").From("a").Where(Eq{"id": []int{1, 2}}), Select("*").From("b").Where(Eq{"id": []int{1, 2}})).ToSql()
+	assert.True(t, errors.As(err, &tooLarge))
+
+	_, _, err = Select("*").From("users").SizeLimit(SizeLimit{Bytes: 10}).ToSql()
+	assert.EqualError(t, err, "squirrel: statement has 19 bytes, more than the limit of 10 "+
+		"(largest clause: FROM with 11 bytes and 0 args)")
+
+	_, _, err = b.With("ids", Select("id").From("a").Where(Eq{"x": []int{1, 2}})).
+		Select(Select("*").From("ids").Where(Eq{"y": []int{1, 2}})).ToSql()
+	if assert.True(t, errors.As(err, &tooLarge)) {
+		assert.Equal(t, 4, tooLarge.Size.Args)
+		assert.Equal(t, "statement", tooLarge.Size.Clauses[len(tooLarge.Size.Clauses)-1].Clause)
+	}
+}
+
+// countingSqlizer counts how many times it is rendered.
+type countingSqlizer struct{ n *int }
+
+func (c countingSqlizer) ToSql() (string, []any, error) {
+	*c.n++
+	return "x = ?", []any{1}, nil
+}
+
+func TestSizeLimitStopsEarly(t *testing.T) {
+	var n int
+	_, _, err := Select("*").From("t").Where(Eq{"id": []int{1, 2}}).
+		Where(countingSqlizer{&n}).Suffix("?", countingSqlizer{&n}).
+		SizeLimit(SizeLimit{Args: 2}).ToSql()
+	var tooLarge *StatementTooLargeError
+	if assert.True(t, errors.As(err, &tooLarge)) {
+		assert.Equal(t, 3, tooLarge.Size.Args)
+	}
+	// the WHERE clause is rendered once, and the suffix not at all
+	assert.Equal(t, 1, n)
+
+	rows := Insert("t").Columns("a", "b").SizeLimit(SizeLimit{Args: 3})
+	for i := 0; i < 100; i++ {
+		rows = rows.Values(i, countingSqlizer{&n})
+	}
+	n = 0
+	_, _, err = rows.ToSql()
+	if assert.True(t, errors.As(err, &tooLarge)) {
+		assert.Equal(t, 4, tooLarge.Size.Args)
+	}
+	assert.Equal(t, 2, n)
+
+	size, err := With("ids", Select("id").From("a").Where(Eq{"x": 1})).
+		Select(Select("*").From("ids")).EstimatedSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, size.Args)
+	assert.Equal(t, "WITH", size.Clauses[0].Clause)
+}
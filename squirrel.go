@@ -1,10 +1,21 @@
 // Package squirrel provides a fluent SQL generator.
 //
 // See https://github.com/Masterminds/squirrel for examples.
+//
+// Builders are immutable: every method returns a new builder and leaves the
+// receiver untouched, so a base builder can be shared between goroutines and
+// branched concurrently. The registries (RegisterSchema, RegisterIdents, ...)
+// are safe for concurrent use as well.
+//
+// Identical builders always produce byte-for-byte identical SQL and args: the
+// map-based conditions (Eq, Lt, Like, ...) and SetMap render their keys in sorted
+// order, so the output can be used as a prepared statement cache key or for
+// query fingerprinting.
 package squirrel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 )
@@ -23,6 +34,19 @@ type rawSqlizer interface {
 	toSqlRaw() (string, []any, error)
 }
 
+// sqlizerContext is a Sqlizer with a ToSqlContext method, e.g. the builders
+// which don't have a raw rendering.
+type sqlizerContext interface {
+	Sqlizer
+	ToSqlContext(ctx context.Context) (string, []any, error)
+}
+
+// contextSqlizer is a rawSqlizer returning the error of ctx as soon as it is
+// done, for the nested queries of a ToSqlContext.
+type contextSqlizer interface {
+	toSqlRawContext(ctx context.Context) (string, []any, error)
+}
+
 // DebugSqlizer calls ToSql on s and shows the approximate SQL to be executed
 //
 // If ToSql returns an error, the result of this method will look like:
@@ -33,6 +57,24 @@ type rawSqlizer interface {
 // not try very hard to ensure it. Additionally, executing the output of this
 // function with any untrusted user input is certainly insecure.
 func DebugSqlizer(s Sqlizer) string {
+	return debugSqlizer(s, nil, DialectDefault, false)
+}
+
+// DebugSqlizerRedacted works like DebugSqlizer, but asks r for every bound arg
+// and prints the replacement instead of the value when r redacts it.
+// This keeps the shape of the query in logs without leaking e.g. emails or tokens.
+func DebugSqlizerRedacted(s Sqlizer, r Redactor) string {
+	return debugSqlizer(s, r, DialectDefault, false)
+}
+
+// DebugSqlizerDialect works like DebugSqlizer, but renders bound args as literals
+// of the dialect d: strings are escaped, booleans, NULL, binary and time values are
+// written the way the target engine expects them (see Dialect.Literal).
+func DebugSqlizerDialect(s Sqlizer, d Dialect) string {
+	return debugSqlizer(s, nil, d, true)
+}
+
+func debugSqlizer(s Sqlizer, r Redactor, d Dialect, literals bool) string {
 	sql, args, err := s.ToSql()
 	if err != nil {
 		return fmt.Sprintf("[ToSql error: %s]", err)
@@ -48,6 +90,7 @@ func DebugSqlizer(s Sqlizer) string {
 	// TODO: dedupe this with placeholder.go
 	buf := &bytes.Buffer{}
 	i := 0
+	column := ""
 	for {
 		p := strings.Index(sql, placeholder)
 		if p == -1 {
@@ -67,7 +110,16 @@ func DebugSqlizer(s Sqlizer) string {
 					sql, len(args))
 			}
 			buf.WriteString(sql[:p])
-			fmt.Fprintf(buf, "'%v'", args[i])
+			if r != nil {
+				column = placeholderColumn(sql[:p], column)
+			}
+			if replacement, redacted := redactArg(r, column, i, args[i]); redacted {
+				fmt.Fprintf(buf, "'%s'", replacement)
+			} else if _, ok := args[i].(boundArg); ok || literals {
+				buf.WriteString(d.Literal(args[i]))
+			} else {
+				fmt.Fprintf(buf, "'%v'", args[i])
+			}
 			// advance our sql string "cursor" beyond the arg we placed
 			sql = sql[p+1:]
 			i++
@@ -142,3 +142,11 @@ func TestDebugSqlizerErrors(t *testing.T) {
 	errorMsg = DebugSqlizer(Lt{"x": nil}) // Cannot use nil values with Lt
 	assert.True(t, strings.HasPrefix(errorMsg, "[ToSql error: "))
 }
+
+func TestDebugSqlizerDialect(t *testing.T) {
+	b := Select("*").From("users").
+		Where(Eq{"active": true, "name": "O'Reilly", "deleted_at": nil})
+	assert.Equal(t,
+		"SELECT * FROM users WHERE active = 1 AND deleted_at IS NULL AND name = N'O''Reilly'",
+		DebugSqlizerDialect(b, DialectMSSQL))
+}
@@ -1,55 +1,253 @@
 package squirrel
 
-import "github.com/lann/builder"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statementData holds what StatementBuilderType passes on to the builders.
+type statementData struct {
+	PlaceholderFormat   PlaceholderFormat
+	Dialect             Dialect
+	WhereParts          []Sqlizer
+	DefaultLimit        string
+	RejectNilPredicates bool
+	TimeLocation        *time.Location
+	MaxParts            int
+	SizeLimit           SizeLimit
+}
 
 // StatementBuilderType is the type of StatementBuilder.
-type StatementBuilderType builder.Builder
+type StatementBuilderType struct {
+	core[statementData]
+}
+
+// set returns a copy of b with the data changed by f.
+func (b StatementBuilderType) set(f func(d *statementData)) StatementBuilderType {
+	return StatementBuilderType{b.with(f)}
+}
 
 // Select returns a SelectBuilder for this StatementBuilderType.
 func (b StatementBuilderType) Select(columns ...string) SelectBuilder {
-	return SelectBuilder(b).Columns(columns...)
+	s := b.get()
+	return SelectBuilder{}.set(func(d *selectData) {
+		d.PlaceholderFormat = s.PlaceholderFormat
+		d.Dialect = s.Dialect
+		d.WhereParts = s.WhereParts
+		d.DefaultLimit = s.DefaultLimit
+		d.RejectNilPredicates = s.RejectNilPredicates
+		d.TimeLocation = s.TimeLocation
+		d.SizeLimit = s.SizeLimit
+		d.MaxParts = s.MaxParts
+	}).Columns(columns...)
 }
 
 // Insert returns a InsertBuilder for this StatementBuilderType.
 func (b StatementBuilderType) Insert(into string) InsertBuilder {
-	return InsertBuilder(b).Into(into)
+	return b.insert().Into(into)
 }
 
 // Replace returns a InsertBuilder for this StatementBuilderType with the
 // statement keyword set to "REPLACE".
 func (b StatementBuilderType) Replace(into string) InsertBuilder {
-	return InsertBuilder(b).statementKeyword("REPLACE").Into(into)
+	return b.insert().statementKeyword("REPLACE").Into(into)
+}
+
+func (b StatementBuilderType) insert() InsertBuilder {
+	s := b.get()
+	return InsertBuilder{}.set(func(d *insertData) {
+		d.PlaceholderFormat = s.PlaceholderFormat
+		d.Dialect = s.Dialect
+		d.TimeLocation = s.TimeLocation
+		d.SizeLimit = s.SizeLimit
+		d.MaxParts = s.MaxParts
+	})
 }
 
 // Update returns a UpdateBuilder for this StatementBuilderType.
 func (b StatementBuilderType) Update(table string) UpdateBuilder {
-	return UpdateBuilder(b).Table(table)
+	s := b.get()
+	return UpdateBuilder{}.set(func(d *updateData) {
+		d.PlaceholderFormat = s.PlaceholderFormat
+		d.Dialect = s.Dialect
+		d.WhereParts = s.WhereParts
+		d.TimeLocation = s.TimeLocation
+		d.SizeLimit = s.SizeLimit
+		d.MaxParts = s.MaxParts
+	}).Table(table)
 }
 
 // Delete returns a DeleteBuilder for this StatementBuilderType.
 func (b StatementBuilderType) Delete(from string) DeleteBuilder {
-	return DeleteBuilder(b).From(from)
+	s := b.get()
+	return DeleteBuilder{}.set(func(d *deleteData) {
+		d.PlaceholderFormat = s.PlaceholderFormat
+		d.Dialect = s.Dialect
+		d.WhereParts = s.WhereParts
+		d.TimeLocation = s.TimeLocation
+		d.SizeLimit = s.SizeLimit
+		d.MaxParts = s.MaxParts
+	}).From(from)
+}
+
+// With returns a CommonTableExpressionsBuilder for this StatementBuilderType.
+// Only the placeholder format, the dialect, the time location, MaxParts and
+// SizeLimit are inherited. If as is given, it is set as the expression of the
+// cte: more than one expression makes ToSql return an error.
+//
+// Ex: StatementBuilder.PlaceholderFormat(Dollar).With("lab", Select("col").From("tab"))
+func (b StatementBuilderType) With(cte string, as ...Sqlizer) CommonTableExpressionsBuilder {
+	s := b.get()
+	w := CommonTableExpressionsBuilder{}.set(func(d *commonTableExpressionsData) {
+		d.PlaceholderFormat = s.PlaceholderFormat
+		d.Dialect = s.Dialect
+		d.TimeLocation = s.TimeLocation
+		d.SizeLimit = s.SizeLimit
+		d.MaxParts = s.MaxParts
+	})
+
+	w = w.Cte(cte)
+	switch len(as) {
+	case 0:
+		return w
+	case 1:
+		return w.As(as[0])
+	default:
+		return w.withErr(fmt.Errorf("With takes at most one expression for cte %s, got %d", cte, len(as)))
+	}
+}
+
+// Union returns a UnionBuilder for this StatementBuilderType.
+// Only the placeholder format, the dialect, the time location, MaxParts and
+// SizeLimit are inherited.
+//
+// See Union.
+func (b StatementBuilderType) Union(parts ...Sqlizer) UnionBuilder {
+	return b.configureUnion(Union(parts...))
+}
+
+// UnionAll returns a UnionBuilder with UNION ALL for this StatementBuilderType.
+// Only the placeholder format, the dialect, the time location, MaxParts and
+// SizeLimit are inherited.
+//
+// See UnionAll.
+func (b StatementBuilderType) UnionAll(parts ...Sqlizer) UnionBuilder {
+	return b.configureUnion(UnionAll(parts...))
+}
+
+// Intersect returns an IntersectBuilder for this StatementBuilderType, with the
+// same inherited settings as Union.
+//
+// See Intersect.
+func (b StatementBuilderType) Intersect(parts ...Sqlizer) IntersectBuilder {
+	return IntersectBuilder{}.wrap(b.configureUnion(Intersect(parts...).union()))
+}
+
+// IntersectAll returns an IntersectBuilder with INTERSECT ALL for this
+// StatementBuilderType.
+//
+// See IntersectAll.
+func (b StatementBuilderType) IntersectAll(parts ...Sqlizer) IntersectBuilder {
+	return IntersectBuilder{}.wrap(b.configureUnion(IntersectAll(parts...).union()))
+}
+
+// Except returns an ExceptBuilder for this StatementBuilderType, with the same
+// inherited settings as Union.
+//
+// See Except.
+func (b StatementBuilderType) Except(parts ...Sqlizer) ExceptBuilder {
+	return ExceptBuilder{}.wrap(b.configureUnion(Except(parts...).union()))
+}
+
+// ExceptAll returns an ExceptBuilder with EXCEPT ALL for this
+// StatementBuilderType.
+//
+// See ExceptAll.
+func (b StatementBuilderType) ExceptAll(parts ...Sqlizer) ExceptBuilder {
+	return ExceptBuilder{}.wrap(b.configureUnion(ExceptAll(parts...).union()))
 }
 
-// With returns a CommonTableExpressionsBuilder for this StatementBuilderType
-func (b StatementBuilderType) With(cte string) CommonTableExpressionsBuilder {
-	return CommonTableExpressionsBuilder(b).Cte(cte)
+func (b StatementBuilderType) configureUnion(u UnionBuilder) UnionBuilder {
+	s := b.get()
+	return u.set(func(d *setOpData) {
+		d.PlaceholderFormat = s.PlaceholderFormat
+		d.Dialect = s.Dialect
+		d.TimeLocation = s.TimeLocation
+		d.SizeLimit = s.SizeLimit
+		d.MaxParts = s.MaxParts
+	})
+}
+
+// DefaultLimit sets a LIMIT n on the SELECT statements built by this
+// StatementBuilderType without a Limit or a Paginator of their own, e.g. to
+// protect API endpoints from unbounded result sets. Only the top level statement
+// is limited, i.e. the final SELECT of a WITH statement, not its subqueries and
+// CTE bodies. Use SelectBuilder.Unlimited to opt out.
+//
+// Ex: StatementBuilder.DefaultLimit(100).Select("*").From("users")
+// -> "SELECT * FROM users LIMIT 100"
+func (b StatementBuilderType) DefaultLimit(n uint64) StatementBuilderType {
+	return b.set(func(d *statementData) { d.DefaultLimit = fmt.Sprintf("%d", n) })
+}
+
+// RejectNilPredicates makes the SELECT statements built by this
+// StatementBuilderType return ErrNilSqlizer when a nil predicate is given to
+// Where or Having, instead of skipping it. UPDATE and DELETE statements always
+// reject nil predicates.
+func (b StatementBuilderType) RejectNilPredicates() StatementBuilderType {
+	return b.set(func(d *statementData) { d.RejectNilPredicates = true })
+}
+
+// TimeLocation makes the statements built by this StatementBuilderType convert
+// their time.Time args to loc when they are built, so timestamps in mixed time
+// zones are all bound in the same one.
+//
+// Ex: StatementBuilder.TimeLocation(time.UTC).Select("*").From("events").Where(Gt{"at": t})
+func (b StatementBuilderType) TimeLocation(loc *time.Location) StatementBuilderType {
+	return b.set(func(d *statementData) { d.TimeLocation = loc })
+}
+
+// MaxParts sets the MaxParts of the SELECT, INSERT, UPDATE, DELETE, UNION and
+// WITH statements built by this StatementBuilderType, see SelectBuilder.MaxParts.
+func (b StatementBuilderType) MaxParts(n int) StatementBuilderType {
+	return b.set(func(d *statementData) { d.MaxParts = n })
+}
+
+// SizeLimit sets the SizeLimit of the SELECT, INSERT, UPDATE, DELETE, UNION and WITH
+// statements built by this StatementBuilderType, see SelectBuilder.SizeLimit.
+// Ex: StatementBuilder.Dialect(DialectPostgres).SizeLimit(SizeLimit{Args: 65535})
+func (b StatementBuilderType) SizeLimit(l SizeLimit) StatementBuilderType {
+	return b.set(func(d *statementData) { d.SizeLimit = l })
 }
 
 // PlaceholderFormat sets the PlaceholderFormat field for any child builders.
 func (b StatementBuilderType) PlaceholderFormat(f PlaceholderFormat) StatementBuilderType {
-	return builder.Set(b, "PlaceholderFormat", f).(StatementBuilderType)
+	return b.set(func(d *statementData) { d.PlaceholderFormat = f })
+}
+
+// Dialect sets the target database engine (e.g. DialectSQLite) for the query.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b StatementBuilderType) Dialect(d Dialect) StatementBuilderType {
+	b = b.set(func(data *statementData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
 }
 
 // Where adds WHERE expressions to the query.
 //
 // See SelectBuilder.Where for more information.
 func (b StatementBuilderType) Where(pred any, args ...any) StatementBuilderType {
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(StatementBuilderType)
+	return b.set(func(d *statementData) {
+		d.WhereParts = appendTo(d.WhereParts, newWherePart(pred, args...))
+	})
 }
 
 // StatementBuilder is a parent builder for other builders, e.g. SelectBuilder.
-var StatementBuilder = StatementBuilderType(builder.EmptyBuilder).PlaceholderFormat(Question)
+var StatementBuilder = StatementBuilderType{}.PlaceholderFormat(Question)
 
 // Select returns a new SelectBuilder, optionally setting some result columns.
 //
@@ -87,11 +285,12 @@ func Delete(from string) DeleteBuilder {
 	return StatementBuilder.Delete(from)
 }
 
-// With returns a new CommonTableExpressionsBuilder with the given first cte name
+// With returns a new CommonTableExpressionsBuilder with the given first cte name,
+// and optionally its expression.
 //
-// See CommonTableExpressionsBuilder.Cte
-func With(cte string) CommonTableExpressionsBuilder {
-	return StatementBuilder.With(cte)
+// See CommonTableExpressionsBuilder.Cte, CommonTableExpressionsBuilder.As
+func With(cte string, as ...Sqlizer) CommonTableExpressionsBuilder {
+	return StatementBuilder.With(cte, as...)
 }
 
 // WithRecursive returns a new CommonTableExpressionsBuilder with the RECURSIVE option and the given first cte name
@@ -101,10 +300,263 @@ func WithRecursive(cte string) CommonTableExpressionsBuilder {
 	return StatementBuilder.With(cte).Recursive(true)
 }
 
+// InsertOrGet returns the get-or-create query of a row for PostgreSQL: the row is
+// inserted unless it conflicts on conflictColumns, and is returned either way.
+// ToSql returns an error if a conflict column is not in values.
+//
+// Ex:
+//
+//	InsertOrGet("tags", []string{"name"}, map[string]any{"name": "go"})
+//	// WITH ins AS (INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING RETURNING *)
+//	// (SELECT * FROM ins) UNION (SELECT * FROM tags WHERE name = ?)
+func InsertOrGet(table string, conflictColumns []string, values map[string]any) CommonTableExpressionsBuilder {
+	existing := Eq{}
+	var err error
+	for _, column := range conflictColumns {
+		value, ok := values[column]
+		if !ok && err == nil {
+			err = fmt.Errorf("InsertOrGet: conflict column %s not found in values", column)
+		}
+		existing[column] = value
+	}
+
+	insert := Insert(table).
+		SetMap(values).
+		Suffix(fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(conflictColumns, ", "))).
+		Returning("*")
+
+	union := Union(
+		Select("*").From("ins"),
+		Select("*").From(table).Where(existing),
+	)
+
+	b := With("ins", insert).Union(union)
+	if err != nil {
+		return b.withErr(err)
+	}
+	return b
+}
+
+// SelectOpts describes a SELECT statement for SelectWith.
+// Zero values are omitted: e.g. Limit 0 means no LIMIT clause.
+type SelectOpts struct {
+	Dialect  Dialect
+	Distinct bool
+	Columns  []string
+	From     string
+	Joins    []string
+	Where    []Sqlizer
+	GroupBy  []string
+	Having   []Sqlizer
+	OrderBy  []string
+	Limit    uint64
+	Offset   uint64
+}
+
+// SelectWith returns a new SelectBuilder configured from opts.
+//
+// Ex:
+//
+//	SelectWith(SelectOpts{Columns: []string{"id"}, From: "users", Where: []Sqlizer{Eq{"id": 1}}})
+func SelectWith(opts SelectOpts) SelectBuilder {
+	b := Select(opts.Columns...).Dialect(opts.Dialect)
+	if opts.Distinct {
+		b = b.Distinct()
+	}
+	if len(opts.From) > 0 {
+		b = b.From(opts.From)
+	}
+	for _, join := range opts.Joins {
+		b = b.Join(join)
+	}
+	for _, where := range opts.Where {
+		b = b.Where(where)
+	}
+	if len(opts.GroupBy) > 0 {
+		b = b.GroupBy(opts.GroupBy...)
+	}
+	for _, having := range opts.Having {
+		b = b.Having(having)
+	}
+	if len(opts.OrderBy) > 0 {
+		b = b.OrderBy(opts.OrderBy...)
+	}
+	if opts.Limit > 0 {
+		b = b.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		b = b.Offset(opts.Offset)
+	}
+	return b
+}
+
+// InsertOpts describes an INSERT statement for InsertWith.
+// Either Columns and Values or SetMap can be used.
+type InsertOpts struct {
+	Dialect   Dialect
+	Into      string
+	Columns   []string
+	Values    [][]any
+	SetMap    map[string]any
+	Returning []string
+}
+
+// InsertWith returns a new InsertBuilder configured from opts.
+func InsertWith(opts InsertOpts) InsertBuilder {
+	b := Insert(opts.Into).Dialect(opts.Dialect)
+	if len(opts.Columns) > 0 {
+		b = b.Columns(opts.Columns...)
+	}
+	for _, values := range opts.Values {
+		b = b.Values(values...)
+	}
+	if len(opts.SetMap) > 0 {
+		b = b.SetMap(opts.SetMap)
+	}
+	if len(opts.Returning) > 0 {
+		b = b.Returning(opts.Returning...)
+	}
+	return b
+}
+
+// UpdateOpts describes an UPDATE statement for UpdateWith.
+type UpdateOpts struct {
+	Dialect   Dialect
+	Table     string
+	SetMap    map[string]any
+	Where     []Sqlizer
+	Returning []string
+}
+
+// UpdateWith returns a new UpdateBuilder configured from opts.
+func UpdateWith(opts UpdateOpts) UpdateBuilder {
+	b := Update(opts.Table).Dialect(opts.Dialect)
+	if len(opts.SetMap) > 0 {
+		b = b.SetMap(opts.SetMap)
+	}
+	for _, where := range opts.Where {
+		b = b.Where(where)
+	}
+	if len(opts.Returning) > 0 {
+		b = b.Returning(opts.Returning...)
+	}
+	return b
+}
+
+// DeleteOpts describes a DELETE statement for DeleteWith.
+type DeleteOpts struct {
+	Dialect   Dialect
+	From      string
+	Where     []Sqlizer
+	Returning []string
+}
+
+// DeleteWith returns a new DeleteBuilder configured from opts.
+func DeleteWith(opts DeleteOpts) DeleteBuilder {
+	b := Delete(opts.From).Dialect(opts.Dialect)
+	for _, where := range opts.Where {
+		b = b.Where(where)
+	}
+	if len(opts.Returning) > 0 {
+		b = b.Returning(opts.Returning...)
+	}
+	return b
+}
+
+// UnionOpts describes a UNION statement for UnionWith.
+// All makes it a UNION ALL.
+type UnionOpts struct {
+	Dialect Dialect
+	Parts   []Sqlizer
+	All     bool
+	OrderBy []string
+	Limit   uint64
+	Offset  uint64
+}
+
+// UnionWith returns a new UnionBuilder configured from opts.
+func UnionWith(opts UnionOpts) UnionBuilder {
+	b := Union(opts.Parts...)
+	if opts.All {
+		b = UnionAll(opts.Parts...)
+	}
+	b = b.Dialect(opts.Dialect)
+	if len(opts.OrderBy) > 0 {
+		b = b.OrderBy(opts.OrderBy...)
+	}
+	if opts.Limit > 0 {
+		b = b.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		b = b.Offset(opts.Offset)
+	}
+	return b
+}
+
+// CTEOpts describes a WITH statement for CTEWith: the Ctes are rendered in
+// order, followed by Statement.
+type CTEOpts struct {
+	Dialect   Dialect
+	Recursive bool
+	Ctes      []CTEOpt
+	Statement Sqlizer
+}
+
+// CTEOpt is a common table expression of CTEOpts.
+type CTEOpt struct {
+	Name string
+	As   Sqlizer
+}
+
+// CTEWith returns a new CommonTableExpressionsBuilder configured from opts.
+//
+// Ex:
+//
+//	CTEWith(CTEOpts{Ctes: []CTEOpt{{"a", Select("id").From("t")}}, Statement: Select("*").From("a")})
+func CTEWith(opts CTEOpts) CommonTableExpressionsBuilder {
+	b := CommonTableExpressionsBuilder{}.Dialect(opts.Dialect).Recursive(opts.Recursive)
+	for _, cte := range opts.Ctes {
+		b = b.Cte(cte.Name).As(cte.As)
+	}
+	if opts.Statement != nil {
+		b = b.set(func(d *commonTableExpressionsData) { d.Statement = opts.Statement })
+	}
+	return b
+}
+
+// CaseOpts describes a CASE expression for CaseWith.
+// A nil What or Else is omitted.
+type CaseOpts struct {
+	What  any
+	Whens []CaseWhenOpt
+	Else  any
+}
+
+// CaseWhenOpt is a WHEN ... THEN ... part of CaseOpts.
+type CaseWhenOpt struct {
+	When any
+	Then any
+}
+
+// CaseWith returns a new CaseBuilder configured from opts.
+func CaseWith(opts CaseOpts) CaseBuilder {
+	b := Case()
+	if opts.What != nil {
+		b = Case(opts.What)
+	}
+	for _, when := range opts.Whens {
+		b = b.When(when.When, when.Then)
+	}
+	if opts.Else != nil {
+		b = b.Else(opts.Else)
+	}
+	return b
+}
+
 // Case returns a new CaseBuilder
 // "what" represents case value
 func Case(what ...any) CaseBuilder {
-	b := CaseBuilder(builder.EmptyBuilder)
+	b := CaseBuilder{}
 
 	switch len(what) {
 	case 0:
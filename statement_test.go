@@ -18,3 +18,225 @@ func TestStatementBuilderWhere(t *testing.T) {
 	expectedArgs := []any{1, 2}
 	assert.Equal(t, expectedArgs, args)
 }
+
+func TestStatementBuilderUnion(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(Dollar).Where("x = ?", 1)
+
+	sql, args, err := sb.Union(
+		sb.Select("id").From("a"),
+		sb.Select("id").From("b").Where("y = ?", 2),
+	).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a WHERE x = $1) UNION (SELECT id FROM b WHERE x = $2 AND y = $3)", sql)
+	assert.Equal(t, []any{1, 1, 2}, args)
+
+	sql, _, err = StatementBuilder.Dialect(DialectSQLite).UnionAll(Select("id").From("a"), Select("id").From("b")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id FROM a UNION ALL SELECT id FROM b", sql)
+}
+
+func TestStatementBuilderWith(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(Dollar).Where("deleted = ?", false)
+
+	sql, args, err := sb.With("lab", Select("col").From("tab").Where("a = ?", 1)).
+		Select(Select("col").From("lab").Where("b = ?", 2)).
+		ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH lab AS (SELECT col FROM tab WHERE a = $1) SELECT col FROM lab WHERE b = $2", sql)
+	assert.Equal(t, []any{1, 2}, args)
+}
+
+func TestSelectWithOpts(t *testing.T) {
+	sql, args, err := SelectWith(SelectOpts{
+		Columns: []string{"u.id", "count(*)"},
+		From:    "users u",
+		Joins:   []string{"orders o ON o.user_id = u.id"},
+		Where:   []Sqlizer{Eq{"u.active": true}, Gt{"o.total": 10}},
+		GroupBy: []string{"u.id"},
+		Having:  []Sqlizer{Expr("count(*) > ?", 1)},
+		OrderBy: []string{"u.id"},
+		Limit:   10,
+		Offset:  20,
+	}).ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "SELECT u.id, count(*) FROM users u JOIN orders o ON o.user_id = u.id " +
+		"WHERE u.active = ? AND o.total > ? GROUP BY u.id HAVING count(*) > ? ORDER BY u.id LIMIT 10 OFFSET 20"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []any{true, 10, 1}, args)
+}
+
+func TestInsertUpdateDeleteWith(t *testing.T) {
+	sql, args, err := InsertWith(InsertOpts{
+		Dialect:   DialectPostgres,
+		Into:      "users",
+		Columns:   []string{"id", "name"},
+		Values:    [][]any{{1, "John"}, {2, "Jane"}},
+		Returning: []string{"id"},
+	}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES ($1,$2),($3,$4) RETURNING id", sql)
+	assert.Equal(t, []any{1, "John", 2, "Jane"}, args)
+
+	sql, args, err = UpdateWith(UpdateOpts{
+		Table:  "users",
+		SetMap: map[string]any{"name": "John"},
+		Where:  []Sqlizer{Eq{"id": 1}},
+	}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = ?", sql)
+	assert.Equal(t, []any{"John", 1}, args)
+
+	sql, args, err = DeleteWith(DeleteOpts{From: "users", Where: []Sqlizer{Eq{"id": 1}}}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM users WHERE id = ?", sql)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestInsertOrGet(t *testing.T) {
+	sql, args, err := InsertOrGet("tags", []string{"owner_id", "name"}, map[string]any{"name": "go", "owner_id": 7, "color": "blue"}).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	assert.NoError(t, err)
+
+	expectedSql := "WITH ins AS (INSERT INTO tags (color,name,owner_id) VALUES ($1,$2,$3) " +
+		"ON CONFLICT (owner_id, name) DO NOTHING RETURNING *) " +
+		"(SELECT * FROM ins) UNION (SELECT * FROM tags WHERE name = $4 AND owner_id = $5)"
+	assert.Equal(t, expectedSql, sql)
+	assert.Equal(t, []any{"blue", "go", 7, "go", 7}, args)
+
+	_, _, err = InsertOrGet("tags", []string{"missing"}, map[string]any{"name": "go"}).ToSql()
+	assert.EqualError(t, err, "InsertOrGet: conflict column missing not found in values")
+}
+
+func TestStatementBuilderDefaultLimit(t *testing.T) {
+	sb := StatementBuilder.DefaultLimit(100)
+
+	sql, _, err := sb.Select("*").From("users").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users LIMIT 100", sql)
+
+	sql, _, err = sb.Select("*").From("users").Limit(10).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users LIMIT 10", sql)
+
+	sql, _, err = sb.Select("*").From("users").Unlimited().ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users", sql)
+
+	sql, _, err = sb.Select("*").From("users").Where(Eq{"id": sb.Select("user_id").From("orders")}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders) LIMIT 100", sql)
+
+	sql, _, err = sb.Select("*").From("users").
+		Where(Expr("id IN (?)", sb.Select("user_id").From("orders"))).
+		Where(In("org_id", sb.Select("id").From("orgs"))).
+		CrossJoinLateralSelect(sb.Select("*").From("posts").Where("posts.user_id = users.id"), "p").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users CROSS JOIN LATERAL (SELECT * FROM posts WHERE posts.user_id = users.id) AS p "+
+		"WHERE id IN (SELECT user_id FROM orders) AND org_id IN (SELECT id FROM orgs) LIMIT 100", sql)
+
+	sql, _, err = sb.With("x", sb.Select("*").From("a")).Select(sb.Select("*").From("x")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH x AS (SELECT * FROM a) SELECT * FROM x LIMIT 100", sql)
+
+	sql, _, err = sb.Insert("archive").Select(sb.Select("*").From("users")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO archive SELECT * FROM users", sql)
+
+	sql, _, err = sb.Update("users").Set("active", false).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET active = ?", sql)
+}
+
+func TestZeroValueBuilders(t *testing.T) {
+	var s SelectBuilder
+	_, _, err := s.ToSql()
+	assert.Error(t, err)
+
+	sql, args, err := s.Columns("a").From("t").Where(Eq{"b": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE b = ?", sql)
+	assert.Equal(t, []any{1}, args)
+
+	var i InsertBuilder
+	sql, _, err = i.Into("t").Values(1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO t VALUES (?)", sql)
+
+	var u UpdateBuilder
+	sql, _, err = u.Table("t").Set("a", 1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = ?", sql)
+
+	var d DeleteBuilder
+	sql, _, err = d.From("t").Where("a = ?", 1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM t WHERE a = ?", sql)
+
+	var c CommonTableExpressionsBuilder
+	sql, _, err = c.Cte("x").As(Select("a")).Select(Select("*").From("x")).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH x AS (SELECT a) SELECT * FROM x", sql)
+
+	var sb StatementBuilderType
+	sql, _, err = sb.Select("a").From("t").Where("b = ?", 1).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT a FROM t WHERE b = ?", sql)
+}
+
+func TestPrefixArgsComeFirst(t *testing.T) {
+	sb := StatementBuilder.PlaceholderFormat(Dollar)
+	tests := []struct {
+		b   Sqlizer
+		sql string
+	}{
+		{sb.Select("*").Prefix("/* ? */", 1).From("t").Where("a = ?", 2),
+			"/* $1 */ SELECT * FROM t WHERE a = $2"},
+		{sb.Insert("t").Prefix("/* ? */", 1).Columns("a").Values(2),
+			"/* $1 */ INSERT INTO t (a) VALUES ($2)"},
+		{sb.Update("t").Prefix("/* ? */", 1).Set("a", 2),
+			"/* $1 */ UPDATE t SET a = $2"},
+		{sb.Delete("t").Prefix("/* ? */", 1).Where("a = ?", 2),
+			"/* $1 */ DELETE FROM t WHERE a = $2"},
+	}
+	for _, test := range tests {
+		sql, args, err := test.b.ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, test.sql, sql)
+		assert.Equal(t, []any{1, 2}, args)
+	}
+}
+
+func TestUnionCTECaseWith(t *testing.T) {
+	sql, args, err := UnionWith(UnionOpts{
+		Dialect: DialectPostgres,
+		Parts:   []Sqlizer{Select("id").From("a").Where(Eq{"x": 1}), Select("id").From("b")},
+		All:     true,
+		OrderBy: []string{"id"},
+		Limit:   10,
+	}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(SELECT id FROM a WHERE x = $1) UNION ALL (SELECT id FROM b) ORDER BY id LIMIT 10", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, args, err = CTEWith(CTEOpts{
+		Ctes: []CTEOpt{
+			{"a", Select("id").From("t").Where(Eq{"x": 1})},
+			{"b", Select("id").From("a")},
+		},
+		Statement: Select("*").From("b"),
+	}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH a AS (SELECT id FROM t WHERE x = ?), b AS (SELECT id FROM a) SELECT * FROM b", sql)
+	assert.Equal(t, []any{1}, args)
+
+	sql, args, err = CaseWith(CaseOpts{
+		What:  "status",
+		Whens: []CaseWhenOpt{{When: Expr("?", 1), Then: Expr("?", "one")}},
+		Else:  Expr("?", "other"),
+	}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CASE status WHEN ? THEN ? ELSE ? END", sql)
+	assert.Equal(t, []any{1, "one", "other"}, args)
+}
@@ -0,0 +1,184 @@
+package squirrel
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fingerprint returns the shape of sql: literals and placeholders are replaced
+// with ?, lists of placeholders with (?...), rows of placeholder lists with
+// (?...), ... and whitespace is collapsed, so that the queries built by the same
+// code share their fingerprint whatever their args and IN list sizes.
+// Ex: Fingerprint("SELECT * FROM t WHERE id IN ($1,$2) AND name = 'x'") -> "SELECT * FROM t WHERE id IN (?...) AND name = ?"
+func Fingerprint(sql string) string {
+	return fingerprintListsRegexp.ReplaceAllString(
+		fingerprintListRegexp.ReplaceAllString(normalizeSql(sql), "(?...)"), "(?...), ...")
+}
+
+var (
+	fingerprintListRegexp  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fingerprintListsRegexp = regexp.MustCompile(`\(\?\.\.\.\)(?:\s*,\s*\(\?\.\.\.\))+`)
+	inListRegexp           = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	statsTableRegexp       = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE)\s+(?:ONLY\s+)?([A-Za-z_][A-Za-z0-9_.]*)`)
+)
+
+// normalizeSql replaces the literals and placeholders of sql with ? and collapses
+// its whitespace.
+func normalizeSql(sql string) string {
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+
+	buf := &bytes.Buffer{}
+	space := false
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = buf.Len() > 0
+			continue
+		case c == '\'':
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			c = '?'
+		case isDigit(c) && (i == 0 || !isIdentifierChar(sql[i-1])):
+			for i+1 < len(sql) && (isDigit(sql[i+1]) || sql[i+1] == '.') {
+				i++
+			}
+			c = '?'
+		default:
+			if _, length := numberedPlaceholderAt(sql, i); length > 0 {
+				i += length - 1
+				c = '?'
+			}
+		}
+
+		if space {
+			_ = buf.WriteByte(' ')
+			space = false
+		}
+		_ = buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// QueryStats are the statistics of the queries sharing a fingerprint.
+type QueryStats struct {
+	Fingerprint string
+	// Count is the number of recorded queries.
+	Count int64
+	// TotalArgs is the number of bound args of all the recorded queries.
+	TotalArgs int64
+	// InLists and InListItems are the number of IN lists of placeholders of all
+	// the recorded queries and the number of placeholders they had: see
+	// AvgInListSize. MaxInListSize is the size of the longest list.
+	InLists       int64
+	InListItems   int64
+	MaxInListSize int
+	// Tables are the tables of the FROM, JOIN, INTO and UPDATE clauses.
+	Tables []string
+}
+
+// AvgInListSize returns the average number of placeholders of the IN lists.
+func (s QueryStats) AvgInListSize() float64 {
+	if s.InLists == 0 {
+		return 0
+	}
+	return float64(s.InListItems) / float64(s.InLists)
+}
+
+// QueryStatsCollector records statistics per query fingerprint (see Fingerprint),
+// e.g. to find the queries executed far more often than expected (N+1 patterns)
+// or with unbounded IN lists. Record the queries where they are executed and dump
+// the statistics periodically with Flush. It is safe for concurrent use.
+type QueryStatsCollector struct {
+	mu    sync.Mutex
+	stats map[string]*QueryStats
+}
+
+// NewQueryStatsCollector returns a QueryStatsCollector without recorded queries.
+func NewQueryStatsCollector() *QueryStatsCollector {
+	return &QueryStatsCollector{stats: map[string]*QueryStats{}}
+}
+
+// Record records a query and its args.
+func (c *QueryStatsCollector) Record(sql string, args []any) {
+	normalized := normalizeSql(sql)
+	fingerprint := Fingerprint(sql)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats, ok := c.stats[fingerprint]
+	if !ok {
+		stats = &QueryStats{Fingerprint: fingerprint}
+		for _, m := range statsTableRegexp.FindAllStringSubmatch(normalized, -1) {
+			if !strings.EqualFold(m[1], "SET") {
+				stats.Tables = appendNew(stats.Tables, m[1])
+			}
+		}
+		sort.Strings(stats.Tables)
+		c.stats[fingerprint] = stats
+	}
+
+	stats.Count++
+	stats.TotalArgs += int64(len(args))
+	for _, list := range inListRegexp.FindAllString(normalized, -1) {
+		size := strings.Count(list, "?")
+		stats.InLists++
+		stats.InListItems += int64(size)
+		if size > stats.MaxInListSize {
+			stats.MaxInListSize = size
+		}
+	}
+}
+
+// Observe builds s and records the query, returning the ToSql error if any.
+func (c *QueryStatsCollector) Observe(s Sqlizer) error {
+	sql, args, err := s.ToSql()
+	if err != nil {
+		return err
+	}
+	c.Record(sql, args)
+	return nil
+}
+
+// Stats returns the statistics of the recorded queries, the most recorded first.
+func (c *QueryStatsCollector) Stats() []QueryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot()
+}
+
+// Flush returns the statistics like Stats and forgets the recorded queries.
+func (c *QueryStatsCollector) Flush() []QueryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.snapshot()
+	c.stats = map[string]*QueryStats{}
+	return stats
+}
+
+func (c *QueryStatsCollector) snapshot() []QueryStats {
+	stats := make([]QueryStats, 0, len(c.stats))
+	for _, s := range c.stats {
+		s := *s
+		s.Tables = append([]string{}, s.Tables...)
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Fingerprint < stats[j].Fingerprint
+	})
+	return stats
+}
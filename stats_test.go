@@ -0,0 +1,46 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM t WHERE id IN ($1,$2) AND name = 'x'", "SELECT * FROM t WHERE id IN (?...) AND name = ?"},
+		{"SELECT  *\n FROM t WHERE id IN (?)  LIMIT 10", "SELECT * FROM t WHERE id IN (?...) LIMIT ?"},
+		{"INSERT INTO t (a,b) VALUES (?,?),(?,?),(?,?)", "INSERT INTO t (a,b) VALUES (?...), ..."},
+		{"SELECT a1, 'it''s', x::int FROM t2 WHERE v > 1.5", "SELECT a1, ?, x::int FROM t2 WHERE v > ?"},
+		{"UPDATE t SET a = :1 WHERE b = @p2", "UPDATE t SET a = ? WHERE b = ?"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Fingerprint(tt.sql), tt.sql)
+	}
+}
+
+func TestQueryStatsCollector(t *testing.T) {
+	c := NewQueryStatsCollector()
+	for _, ids := range [][]int{{1}, {1, 2, 3}, {1, 2, 3, 4, 5, 6, 7, 8}} {
+		assert.NoError(t, c.Observe(Select("*").From("users u").Join("orgs o ON o.id = u.org_id").Where(Eq{"u.id": ids})))
+	}
+	c.Record("UPDATE users SET name = $1 WHERE id = $2", []any{"x", 1})
+	assert.Error(t, c.Observe(Select()))
+
+	stats := c.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "SELECT * FROM users u JOIN orgs o ON o.id = u.org_id WHERE u.id IN (?...)", stats[0].Fingerprint)
+	assert.Equal(t, int64(3), stats[0].Count)
+	assert.Equal(t, int64(12), stats[0].TotalArgs)
+	assert.Equal(t, 4.0, stats[0].AvgInListSize())
+	assert.Equal(t, 8, stats[0].MaxInListSize)
+	assert.Equal(t, []string{"orgs", "users"}, stats[0].Tables)
+	assert.Equal(t, []string{"users"}, stats[1].Tables)
+	assert.Equal(t, 0.0, stats[1].AvgInListSize())
+
+	assert.Len(t, c.Flush(), 2)
+	assert.Empty(t, c.Stats())
+}
@@ -0,0 +1,77 @@
+package squirrel
+
+import (
+	"fmt"
+	"time"
+)
+
+// normalizeTimes converts the time.Time and *time.Time args to loc. args is
+// copied first if one is converted, so the args of the builder parts are left as
+// is.
+func normalizeTimes(args []any, loc *time.Location) []any {
+	if loc == nil {
+		return args
+	}
+	normalized := args
+	for i, arg := range args {
+		var t time.Time
+		switch v := arg.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				continue
+			}
+			t = *v
+		default:
+			continue
+		}
+		if &normalized[0] == &args[0] {
+			normalized = append([]any(nil), args...)
+		}
+		normalized[i] = t.In(loc)
+	}
+	return normalized
+}
+
+// atTimeZoneExpr helps to convert timestamps to a time zone in SQL
+type atTimeZoneExpr struct {
+	column  string
+	tz      string
+	dialect Dialect
+}
+
+// AtTimeZone allows to convert the timestamps of column to the time zone tz, e.g.
+// "UTC" or "Europe/Paris", in SQL. It is rendered as column AT TIME ZONE ?, as
+// CONVERT_TZ for MySQL and MariaDB, as toTimeZone for ClickHouse, as DATETIME for
+// BigQuery and as CONVERT_TIMEZONE for Snowflake. SQLite has no time zone support.
+// Ex: Select("id").Column(Alias(AtTimeZone("created_at", "UTC"), "created_utc")) -> "SELECT id, (created_at AT TIME ZONE ?) AS created_utc"
+func AtTimeZone(column, tz string) atTimeZoneExpr {
+	return atTimeZoneExpr{column: column, tz: tz}
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e atTimeZoneExpr) Dialect(d Dialect) atTimeZoneExpr {
+	e.dialect = d
+	return e
+}
+
+func (e atTimeZoneExpr) ToSql() (string, []any, error) {
+	if len(e.column) == 0 || len(e.tz) == 0 {
+		return "", nil, fmt.Errorf("at time zone requires a column and a time zone")
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB:
+		return fmt.Sprintf("CONVERT_TZ(%s, @@session.time_zone, ?)", e.column), []any{e.tz}, nil
+	case DialectClickHouse:
+		return fmt.Sprintf("toTimeZone(%s, ?)", e.column), []any{e.tz}, nil
+	case DialectBigQuery:
+		return fmt.Sprintf("DATETIME(%s, ?)", e.column), []any{e.tz}, nil
+	case DialectSnowflake:
+		return fmt.Sprintf("CONVERT_TIMEZONE(?, %s)", e.column), []any{e.tz}, nil
+	case DialectSQLite, DialectSQLiteUpdateDeleteLimit:
+		return "", nil, e.dialect.unsupportedError("time zone conversion")
+	}
+	return fmt.Sprintf("%s AT TIME ZONE ?", e.column), []any{e.tz}, nil
+}
@@ -0,0 +1,68 @@
+package squirrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeLocation(t *testing.T) {
+	paris := time.FixedZone("Paris", 2*60*60)
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, paris)
+	utc := at.UTC()
+
+	b := StatementBuilder.TimeLocation(time.UTC)
+
+	_, args, err := b.Select("*").From("events").
+		Where(Gt{"at": at}).Where(Expr("at < ?", &at)).Where(Eq{"id": 1}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{utc, utc, 1}, args)
+	assert.Equal(t, time.UTC, args[0].(time.Time).Location())
+
+	_, args, err = b.Insert("events").Columns("at").Values(at).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{utc}, args)
+
+	_, args, err = b.Update("events").Set("at", at).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{utc}, args)
+
+	_, args, err = b.Delete("events").Where(Lt{"at": at}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{utc}, args)
+
+	_, args, err = b.Union(Select("*").From("events").Where(Gt{"at": at})).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{utc}, args)
+
+	_, args, err = Select("*").From("events").Where(Gt{"at": at}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, paris, args[0].(time.Time).Location())
+
+	_, args, err = Select("*").From("events").Where(Gt{"at": at}).TimeLocation(paris).
+		Where(Lt{"at": utc}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, paris, args[1].(time.Time).Location())
+}
+
+func TestAtTimeZone(t *testing.T) {
+	sql, args, err := AtTimeZone("created_at", "UTC").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at AT TIME ZONE ?", sql)
+	assert.Equal(t, []any{"UTC"}, args)
+
+	sql, _, err = AtTimeZone("created_at", "UTC").Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "CONVERT_TZ(created_at, @@session.time_zone, ?)", sql)
+
+	sql, _, err = AtTimeZone("created_at", "UTC").Dialect(DialectClickHouse).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "toTimeZone(created_at, ?)", sql)
+
+	_, _, err = AtTimeZone("created_at", "UTC").Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	_, _, err = AtTimeZone("created_at", "").ToSql()
+	assert.Error(t, err)
+}
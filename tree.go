@@ -0,0 +1,147 @@
+package squirrel
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// treeQuery helps to walk hierarchies stored as adjacency lists
+type treeQuery struct {
+	table             string
+	idColumn          string
+	parentColumn      string
+	columns           []string
+	start             Sqlizer
+	maxDepth          int
+	ancestors         bool
+	dialect           Dialect
+	placeholderFormat PlaceholderFormat
+}
+
+// TreeQuery allows to select the descendants (or, with Ancestors, the ancestors)
+// of the rows of table matching StartWith, where parentColumn references the
+// idColumn of the parent row. A recursive CTE named tree is generated, with a
+// depth column counting the levels from the starting rows (0).
+// Ex:
+//
+//	TreeQuery("categories", "id", "parent_id").StartWith(Eq{"id": 1}).Depth(3)
+//	// WITH RECURSIVE tree AS (SELECT t.*, 0 AS depth FROM categories t WHERE id = ?
+//	// UNION ALL SELECT t.*, tree.depth + 1 FROM categories t JOIN tree ON t.parent_id = tree.id
+//	// WHERE tree.depth < 3) SELECT * FROM tree
+func TreeQuery(table, idColumn, parentColumn string) treeQuery {
+	return treeQuery{table: table, idColumn: idColumn, parentColumn: parentColumn}
+}
+
+// StartWith sets the predicate of the starting rows, see SelectBuilder.Where.
+func (q treeQuery) StartWith(pred any, args ...any) treeQuery {
+	q.start = newWherePart(pred, args...)
+	return q
+}
+
+// Depth limits the walk to maxDepth levels below (or above) the starting rows.
+func (q treeQuery) Depth(maxDepth int) treeQuery {
+	q.maxDepth = maxDepth
+	return q
+}
+
+// Ancestors walks up the hierarchy, from the starting rows to the roots.
+func (q treeQuery) Ancestors() treeQuery {
+	q.ancestors = true
+	return q
+}
+
+// Columns sets the columns of table to select instead of all of them. They are
+// required for Oracle, which needs the column list of recursive CTEs.
+func (q treeQuery) Columns(columns ...string) treeQuery {
+	q.columns = append(q.columns[:len(q.columns):len(q.columns)], columns...)
+	return q
+}
+
+// Dialect sets the dialect used to render the query.
+// The placeholder format preferred by the dialect is set as well.
+func (q treeQuery) Dialect(d Dialect) treeQuery {
+	q.dialect = d
+	if f := d.PlaceholderFormat(); f != nil {
+		q.placeholderFormat = f
+	}
+	return q
+}
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the query.
+func (q treeQuery) PlaceholderFormat(f PlaceholderFormat) treeQuery {
+	q.placeholderFormat = f
+	return q
+}
+
+// recursiveWith returns the WITH clause keyword of recursive CTEs for d.
+func recursiveWith(d Dialect) string {
+	if d == DialectMSSQL || d.isOracle() {
+		return "WITH "
+	}
+	return "WITH RECURSIVE "
+}
+
+// recursiveColumns returns the column list of a recursive CTE, selected from alias.
+func recursiveColumns(columns []string, alias string) string {
+	if len(columns) == 0 {
+		return alias + ".*"
+	}
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = alias + "." + column
+	}
+	return strings.Join(qualified, ", ")
+}
+
+func (q treeQuery) toSqlRaw() (string, []any, error) {
+	if len(q.table) == 0 || len(q.idColumn) == 0 || len(q.parentColumn) == 0 {
+		return "", nil, fmt.Errorf("tree query requires a table, an id column and a parent column")
+	}
+	if q.start == nil {
+		return "", nil, fmt.Errorf("tree query requires a StartWith predicate")
+	}
+	if q.dialect == DialectClickHouse {
+		return "", nil, q.dialect.unsupportedError("recursive CTE")
+	}
+	if q.dialect.isOracle() && len(q.columns) == 0 {
+		return "", nil, fmt.Errorf("tree query requires Columns for Oracle dialect")
+	}
+
+	startSql, args, err := nestedToSql(q.start)
+	if err != nil {
+		return "", nil, err
+	}
+
+	columns := recursiveColumns(q.columns, "t")
+	join := fmt.Sprintf("t.%s = tree.%s", q.parentColumn, q.idColumn)
+	if q.ancestors {
+		join = fmt.Sprintf("t.%s = tree.%s", q.idColumn, q.parentColumn)
+	}
+
+	sql := &bytes.Buffer{}
+	_, _ = sql.WriteString(recursiveWith(q.dialect))
+	_, _ = sql.WriteString("tree")
+	if len(q.columns) > 0 && q.dialect.isOracle() {
+		_, _ = fmt.Fprintf(sql, " (%s, depth)", strings.Join(q.columns, ", "))
+	}
+	_, _ = fmt.Fprintf(sql, " AS (SELECT %s, 0 AS depth FROM %s t WHERE %s", columns, q.table, startSql)
+	_, _ = fmt.Fprintf(sql, " UNION ALL SELECT %s, tree.depth + 1 FROM %s t JOIN tree ON %s", columns, q.table, join)
+	if q.maxDepth > 0 {
+		_, _ = fmt.Fprintf(sql, " WHERE tree.depth < %d", q.maxDepth)
+	}
+	_, _ = sql.WriteString(") SELECT * FROM tree")
+
+	return sql.String(), args, nil
+}
+
+// ToSql builds the query into a SQL string and bound args.
+func (q treeQuery) ToSql() (string, []any, error) {
+	sql, args, err := q.toSqlRaw()
+	if err != nil || q.placeholderFormat == nil {
+		return sql, args, err
+	}
+
+	sql, err = q.placeholderFormat.ReplacePlaceholders(sql)
+	return sql, args, err
+}
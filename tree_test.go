@@ -0,0 +1,55 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeQueryDescendants(t *testing.T) {
+	sql, args, err := TreeQuery("categories", "id", "parent_id").
+		StartWith(Eq{"id": 1}).Depth(3).
+		Dialect(DialectPostgres).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH RECURSIVE tree AS ("+
+		"SELECT t.*, 0 AS depth FROM categories t WHERE id = $1 "+
+		"UNION ALL SELECT t.*, tree.depth + 1 FROM categories t JOIN tree ON t.parent_id = tree.id "+
+		"WHERE tree.depth < 3) SELECT * FROM tree", sql)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestTreeQueryAncestors(t *testing.T) {
+	sql, _, err := TreeQuery("categories", "id", "parent_id").
+		StartWith("slug = ?", "shoes").Ancestors().
+		Dialect(DialectMSSQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH tree AS ("+
+		"SELECT t.*, 0 AS depth FROM categories t WHERE slug = @p1 "+
+		"UNION ALL SELECT t.*, tree.depth + 1 FROM categories t JOIN tree ON t.id = tree.parent_id"+
+		") SELECT * FROM tree", sql)
+}
+
+func TestTreeQueryOracle(t *testing.T) {
+	q := TreeQuery("categories", "id", "parent_id").StartWith(Eq{"id": 1}).Dialect(DialectOracle)
+
+	_, _, err := q.ToSql()
+	assert.Error(t, err)
+
+	sql, _, err := q.Columns("id", "parent_id", "name").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "WITH tree (id, parent_id, name, depth) AS ("+
+		"SELECT t.id, t.parent_id, t.name, 0 AS depth FROM categories t WHERE id = :1 "+
+		"UNION ALL SELECT t.id, t.parent_id, t.name, tree.depth + 1 FROM categories t JOIN tree ON t.parent_id = tree.id"+
+		") SELECT * FROM tree", sql)
+}
+
+func TestTreeQueryErr(t *testing.T) {
+	_, _, err := TreeQuery("categories", "id", "parent_id").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = TreeQuery("", "id", "parent_id").StartWith("id = 1").ToSql()
+	assert.Error(t, err)
+
+	_, _, err = TreeQuery("categories", "id", "parent_id").StartWith("id = 1").Dialect(DialectClickHouse).ToSql()
+	assert.Error(t, err)
+}
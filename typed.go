@@ -0,0 +1,99 @@
+package squirrel
+
+// Column is a handle on a column holding values of type T, so the values of the
+// conditions on the column are checked at compile time. Column handles are meant
+// to be declared once per table, e.g. generated from the schema. The conditions
+// render as the matching Eq, Lt etc. condition.
+// Ex:
+//
+//	var users = struct {
+//		ID    Column[int64]
+//		Email Column[string]
+//	}{Col[int64]("users.id"), Col[string]("users.email")}
+//
+//	Select("*").From("users").Where(users.ID.Eq(42)) // SELECT * FROM users WHERE users.id = ?
+//	users.ID.Eq("42") // doesn't compile
+type Column[T any] struct {
+	name string
+}
+
+// Col returns a handle on the column holding values of type T.
+func Col[T any](name string) Column[T] {
+	return Column[T]{name: name}
+}
+
+// Name returns the name of the column.
+func (c Column[T]) Name() string {
+	return c.name
+}
+
+// ToSql returns the name of the column, so it can be used in the select list.
+func (c Column[T]) ToSql() (string, []any, error) {
+	return c.name, nil, nil
+}
+
+// compare returns cond, or the condition column op v if T is a slice or an
+// array: v is then bound as a single value, e.g. the []string of a text[] column,
+// where cond would compare the column with the items of v.
+func (c Column[T]) compare(cond Sqlizer, op string, v T) Sqlizer {
+	if isListType(v) {
+		return Expr(c.name+" "+op+" ?", v)
+	}
+	return cond
+}
+
+// Eq returns the condition column = v.
+func (c Column[T]) Eq(v T) Sqlizer {
+	return c.compare(Eq{c.name: v}, "=", v)
+}
+
+// NotEq returns the condition column <> v.
+func (c Column[T]) NotEq(v T) Sqlizer {
+	return c.compare(NotEq{c.name: v}, "<>", v)
+}
+
+// Lt returns the condition column < v.
+func (c Column[T]) Lt(v T) Sqlizer {
+	return c.compare(Lt{c.name: v}, "<", v)
+}
+
+// LtOrEq returns the condition column <= v.
+func (c Column[T]) LtOrEq(v T) Sqlizer {
+	return c.compare(LtOrEq{c.name: v}, "<=", v)
+}
+
+// Gt returns the condition column > v.
+func (c Column[T]) Gt(v T) Sqlizer {
+	return c.compare(Gt{c.name: v}, ">", v)
+}
+
+// GtOrEq returns the condition column >= v.
+func (c Column[T]) GtOrEq(v T) Sqlizer {
+	return c.compare(GtOrEq{c.name: v}, ">=", v)
+}
+
+// In returns the condition column IN (vs...), false if vs is empty.
+func (c Column[T]) In(vs ...T) Sqlizer {
+	return Eq{c.name: append([]T{}, vs...)}
+}
+
+// NotIn returns the condition column NOT IN (vs...), true if vs is empty.
+func (c Column[T]) NotIn(vs ...T) Sqlizer {
+	return NotEq{c.name: append([]T{}, vs...)}
+}
+
+// IsNull returns the condition column IS NULL.
+func (c Column[T]) IsNull() Sqlizer {
+	return Eq{c.name: nil}
+}
+
+// IsNotNull returns the condition column IS NOT NULL.
+func (c Column[T]) IsNotNull() Sqlizer {
+	return NotEq{c.name: nil}
+}
+
+// Set returns the column and value for UpdateBuilder.SetMap and InsertBuilder.SetMap.
+// Ex: Update("users").SetMap(users.Email.Set("a@b.c")) -> "UPDATE users SET users.email = ?"
+func (c Column[T]) Set(v T) map[string]any {
+	return map[string]any{c.name: v}
+}
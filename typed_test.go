@@ -0,0 +1,69 @@
+package squirrel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumn(t *testing.T) {
+	id, email := Col[int64]("id"), Col[string]("email")
+
+	tests := []struct {
+		pred Sqlizer
+		sql  string
+		args []any
+	}{
+		{id.Eq(42), "id = ?", []any{int64(42)}},
+		{id.NotEq(42), "id <> ?", []any{int64(42)}},
+		{id.Lt(1), "id < ?", []any{int64(1)}},
+		{id.LtOrEq(1), "id <= ?", []any{int64(1)}},
+		{id.Gt(1), "id > ?", []any{int64(1)}},
+		{id.GtOrEq(1), "id >= ?", []any{int64(1)}},
+		{id.In(1, 2), "id IN (?,?)", []any{int64(1), int64(2)}},
+		{id.In(), "(1=0)", []any{}},
+		{id.NotIn(1), "id NOT IN (?)", []any{int64(1)}},
+		{email.IsNull(), "email IS NULL", nil},
+		{email.IsNotNull(), "email IS NOT NULL", nil},
+	}
+	for _, tt := range tests {
+		sql, args, err := tt.pred.ToSql()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.sql, sql)
+		assert.Equal(t, tt.args, args)
+	}
+}
+
+func TestColumnInBuilders(t *testing.T) {
+	id, email := Col[int64]("id"), Col[string]("email")
+
+	sql, args, err := Select().Column(id).Column(email).From("users").Where(id.Eq(42)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT id, email FROM users WHERE id = ?", sql)
+	assert.Equal(t, []any{int64(42)}, args)
+
+	sql, args, err = Update("users").SetMap(email.Set("a@b.c")).Where(id.Eq(1)).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET email = ? WHERE id = ?", sql)
+	assert.Equal(t, []any{"a@b.c", int64(1)}, args)
+	assert.Equal(t, "email", email.Name())
+}
+
+func TestColumnSliceValues(t *testing.T) {
+	tags := Col[[]string]("tags")
+
+	sql, args, err := tags.Eq([]string{"a", "b"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "tags = ?", sql)
+	assert.Equal(t, []any{[]string{"a", "b"}}, args)
+
+	sql, args, err = tags.GtOrEq([]string{"a"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "tags >= ?", sql)
+	assert.Equal(t, []any{[]string{"a"}}, args)
+
+	sql, args, err = tags.In([]string{"a"}, []string{"b"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "tags IN (?,?)", sql)
+	assert.Equal(t, []any{[]string{"a"}, []string{"b"}}, args)
+}
@@ -2,21 +2,25 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"regexp"
 	"strings"
-
-	"github.com/lann/builder"
+	"time"
 )
 
 // UnionBuilder builds SQL for (SELECT ...) UNION [ALL] (SELECT ...) ... chains.
 // It intentionally parenthesizes each subselect so ORDER BY / LIMIT / OFFSET
-// apply to the *whole* union across dialects.
+// apply to the *whole* union across dialects. Dialects rejecting parenthesized
+// subselects (SQLite, ClickHouse) are rendered without them, see Dialect.
 //
 // API:
 //   sq.Union(   sq.Select(...), sq.Select(...), ...).OrderBy(...).Limit(...)
 //   sq.UnionAll(sq.Select(...), sq.Select(...), ...).Union(sq.Select(...))
 //   // Optional: .Compact() to strip newlines / collapse spaces
 //
+// IntersectBuilder and ExceptBuilder share its implementation, see setop.go.
+//
 // When composing with your CTE builder, pass the UnionBuilder as the *final statement*
 // via a convenience method on your CTE builder (see snippet below).
 //
@@ -25,36 +29,91 @@ import (
 // so replacement happens exactly once end-to-end. If you set it on the union itself,
 // that’s fine too; just don’t double-replace.
 
-type unionOp string
+// setOp is the operator of a set operation: UNION, INTERSECT or EXCEPT.
+type setOp string
 
 const (
-	unionDistinct unionOp = "UNION"
-	unionAll      unionOp = "UNION ALL"
+	unionDistinct     setOp = "UNION"
+	unionAll          setOp = "UNION ALL"
+	intersectDistinct setOp = "INTERSECT"
+	intersectAll      setOp = "INTERSECT ALL"
+	exceptDistinct    setOp = "EXCEPT"
+	exceptAll         setOp = "EXCEPT ALL"
 )
 
-// one union segment: [op] (subquery)
+// sql returns the keyword of op for the dialect d.
+func (op setOp) sql(d Dialect) (string, error) {
+	switch op {
+	case unionDistinct:
+		if d == DialectClickHouse {
+			// ClickHouse requires an explicit mode unless union_default_mode is set.
+			return "UNION DISTINCT", nil
+		}
+	case intersectDistinct, exceptDistinct:
+		if d == DialectClickHouse || d == DialectBigQuery {
+			return string(op) + " DISTINCT", nil
+		}
+		if op == exceptDistinct && d.isOracle() {
+			return "MINUS", nil
+		}
+	case intersectAll, exceptAll:
+		switch {
+		case d.isSQLite(), d.isOracle(), d == DialectClickHouse, d == DialectBigQuery,
+			d == DialectSnowflake, d == DialectMSSQL:
+			return "", d.unsupportedError(string(op))
+		}
+	}
+	return string(op), nil
+}
+
+// one set operation segment: [op] (subquery)
 // The first segment has op="" (no leading operator).
-type unionPart struct {
-	op    unionOp
+type setOpPart struct {
+	op    setOp
 	query Sqlizer
 }
 
-// internal state carried by the builder.
-type unionData struct {
+// internal state carried by UnionBuilder, IntersectBuilder and ExceptBuilder.
+type setOpData struct {
+	// Name of the set operation in error messages: "union" (if empty), "intersect"
+	// or "except".
+	Name string
+
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	TimeLocation      *time.Location
+	SizeLimit         SizeLimit
 
-	Parts   []unionPart // ordered list of subqueries composing the union
+	Parts   []setOpPart // ordered list of subqueries composing the union
 	OrderBy []string    // whole-union ORDER BY
 
-	LimitSet  bool
-	Limit     uint64
-	OffsetSet bool
-	Offset    uint64
+	LimitSet   bool
+	Limit      uint64
+	LimitExpr  Sqlizer
+	OffsetSet  bool
+	Offset     uint64
+	OffsetExpr Sqlizer
+	WithTies   bool
 
+	Prefixes []Sqlizer // leading expressions (e.g., WITH clauses, comments)
 	Suffixes []Sqlizer // trailing expressions (e.g., hints, comments)
 
 	// If true, ToSql compacts whitespace (no '\n' or duplicate spaces).
 	CompactOutput bool
+
+	// If true, ToSql checks that the subqueries select the same number of columns.
+	CheckColumnCount bool
+
+	// If set, the SelectBuilder subqueries are rewritten to select these columns.
+	AlignColumns []string
+
+	// If set, ToSql fails with a TooManyPartsError for more subqueries.
+	MaxParts int
+
+	// First error recorded by a builder method, returned by ToSql.
+	Err error
+
+	ctx context.Context // set by ToSqlContext
 }
 
 // ensure we satisfy Sqlizer at compile time.
@@ -62,29 +121,119 @@ var _ Sqlizer = (UnionBuilder{})
 
 // ---------------- Rendering ----------------
 
-func (d *unionData) toSql() (string, []any, error) {
+func (d *setOpData) toSql() (string, []any, error) {
+	return d.render(false)
+}
+
+// toSqlRaw renders the statement without finalizing placeholders, for nested
+// statements.
+func (d *setOpData) toSqlRaw() (string, []any, error) {
+	return d.render(true)
+}
+
+func (d *setOpData) render(raw bool) (string, []any, error) {
+	if d.Err != nil {
+		return "", nil, d.Err
+	}
 	if len(d.Parts) == 0 {
-		return "", nil, fmt.Errorf("squirrel: union requires at least one SELECT")
+		return "", nil, fmt.Errorf("squirrel: %s requires at least one SELECT", d.name())
+	}
+	if err := checkParts(strings.ToUpper(d.name()), len(d.Parts), d.MaxParts); err != nil {
+		return "", nil, err
+	}
+	if len(d.AlignColumns) > 0 {
+		parts, err := d.alignedParts()
+		if err != nil {
+			return "", nil, err
+		}
+		d.Parts = parts
+	}
+	if d.CheckColumnCount {
+		if err := d.checkColumnCount(); err != nil {
+			return "", nil, err
+		}
+	}
+	if d.WithTies {
+		if !d.LimitSet && d.LimitExpr == nil {
+			return "", nil, fmt.Errorf("squirrel: %s WITH TIES requires FetchFirst", d.name())
+		}
+		if err := validateWithTies(d.Dialect, len(d.OrderBy) > 0); err != nil {
+			return "", nil, err
+		}
 	}
 
 	var buf bytes.Buffer
 	var args []any
+	size := newSizeTracker(d.SizeLimit)
+
+	// Prefixes (same behavior as SelectBuilder): their args come first.
+	if len(d.Prefixes) > 0 {
+		var err error
+		args, err = appendToSqlContext(d.ctx, d.Prefixes, &buf, " ", args)
+		if err != nil {
+			return "", nil, err
+		}
+		if err = size.clause("prefix", buf.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+		buf.WriteByte(' ')
+	}
 
-	// Body: (SELECT ...) [UNION|UNION ALL] (SELECT ...) ...
+	// Some engines (SQLite, ClickHouse) reject parenthesized subselects, and
+	// ClickHouse applies a trailing ORDER BY / LIMIT to the last subselect only,
+	// so the whole union is wrapped into a subquery there.
+	parens := d.Dialect.parenthesizedUnions()
+	wrap := d.Dialect == DialectClickHouse && (len(d.OrderBy) > 0 || d.LimitSet || d.OffsetSet ||
+		d.LimitExpr != nil || d.OffsetExpr != nil)
+	if wrap {
+		buf.WriteString("SELECT * FROM (")
+	}
+
+	// Body: (SELECT ...) [UNION|INTERSECT|EXCEPT [ALL]] (SELECT ...) ...
 	for i, p := range d.Parts {
-		subSQL, subArgs, err := p.query.ToSql()
+		if err := ctxErr(d.ctx); err != nil {
+			return "", nil, err
+		}
+		// With a union-level format, placeholders are replaced once for the
+		// whole union, so the subqueries are rendered raw; as they are when the
+		// union is nested.
+		var subSQL string
+		var subArgs []any
+		var err error
+		if raw || d.PlaceholderFormat != nil {
+			subSQL, subArgs, err = nestedToSqlContext(d.ctx, p.query)
+		} else if c, ok := p.query.(sqlizerContext); ok && d.ctx != nil {
+			subSQL, subArgs, err = c.ToSqlContext(d.ctx)
+		} else {
+			subSQL, subArgs, err = p.query.ToSql()
+		}
 		if err != nil {
-			return "", nil, fmt.Errorf("squirrel: union subquery %d: %w", i, err)
+			return "", nil, fmt.Errorf("squirrel: %s subquery %d: %w", d.name(), i, err)
 		}
 		if i > 0 {
+			op, err := p.op.sql(d.Dialect)
+			if err != nil {
+				return "", nil, err
+			}
 			buf.WriteByte(' ')
-			buf.WriteString(string(p.op))
+			buf.WriteString(op)
 			buf.WriteByte(' ')
 		}
-		buf.WriteByte('(')
+		if parens {
+			buf.WriteByte('(')
+		}
 		buf.WriteString(subSQL)
-		buf.WriteByte(')')
+		if parens {
+			buf.WriteByte(')')
+		}
 		args = append(args, subArgs...)
+		if err = size.clause(strings.ToUpper(d.name()), buf.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if wrap {
+		buf.WriteByte(')')
 	}
 
 	// Whole-union clauses.
@@ -92,27 +241,47 @@ func (d *unionData) toSql() (string, []any, error) {
 		buf.WriteString(" ORDER BY ")
 		buf.WriteString(strings.Join(d.OrderBy, ", "))
 	}
+	var limit, offset limitClause
 	if d.LimitSet {
-		fmt.Fprintf(&buf, " LIMIT %d", d.Limit)
+		limit.sql = fmt.Sprintf("%d", d.Limit)
 	}
 	if d.OffsetSet {
-		fmt.Fprintf(&buf, " OFFSET %d", d.Offset)
+		offset.sql = fmt.Sprintf("%d", d.Offset)
+	}
+	if d.LimitExpr != nil {
+		var err error
+		if limit, err = newLimitClause(d.LimitExpr); err != nil {
+			return "", nil, err
+		}
 	}
+	if d.OffsetExpr != nil {
+		var err error
+		if offset, err = newLimitClause(d.OffsetExpr); err != nil {
+			return "", nil, err
+		}
+	}
+	args = writeLimitOffset(&buf, args, d.Dialect, limit, offset, d.WithTies, len(d.OrderBy) > 0)
 
 	// Suffixes (same behavior as SelectBuilder).
 	if len(d.Suffixes) > 0 {
 		buf.WriteByte(' ')
 		var err error
-		args, err = appendToSql(d.Suffixes, &buf, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, &buf, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = size.clause("suffix", buf.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 	}
 
 	sqlStr := buf.String()
+	if !raw {
+		args = bindArgs(normalizeTimes(args, d.TimeLocation))
+	}
 
 	// Placeholder replacement last.
-	if d.PlaceholderFormat != nil {
+	if d.PlaceholderFormat != nil && !raw {
 		var err error
 		sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
 		if err != nil {
@@ -125,10 +294,193 @@ func (d *unionData) toSql() (string, []any, error) {
 		sqlStr = compactSQL(sqlStr)
 	}
 
+	if err := size.check(len(sqlStr), len(args)); err != nil {
+		return "", nil, err
+	}
 	return sqlStr, args, nil
 }
 
-func (d *unionData) ToSql() (string, []any, error) { return d.toSql() }
+func (d *setOpData) ToSql() (string, []any, error) { return d.toSql() }
+
+// name returns the name of the set operation in error messages.
+func (d *setOpData) name() string {
+	if len(d.Name) == 0 {
+		return "union"
+	}
+	return d.Name
+}
+
+// checkColumnCount checks that the subqueries whose columns can be counted select
+// the same number of columns.
+func (d *setOpData) checkColumnCount() error {
+	first, firstCount := -1, 0
+	for i, p := range d.Parts {
+		count, ok := unionColumnCount(p.query)
+		if !ok {
+			continue
+		}
+		if first < 0 {
+			first, firstCount = i, count
+			continue
+		}
+		if count != firstCount {
+			return fmt.Errorf("squirrel: %s subquery %d selects %d columns, but subquery %d selects %d",
+				d.name(), i, count, first, firstCount)
+		}
+	}
+	return nil
+}
+
+// unionColumnCount returns the number of columns selected by q, or false if they
+// can't be counted, e.g. for SELECT * or a raw SQL subquery.
+func unionColumnCount(q Sqlizer) (int, bool) {
+	switch q := q.(type) {
+	case SelectBuilder:
+		data := q.get()
+		return data.columnCount()
+	case *SelectBuilder:
+		return unionColumnCount(*q)
+	case UnionBuilder:
+		data := q.get()
+		if len(data.Parts) == 0 {
+			return 0, false
+		}
+		return unionColumnCount(data.Parts[0].query)
+	case IntersectBuilder:
+		return unionColumnCount(q.union())
+	case ExceptBuilder:
+		return unionColumnCount(q.union())
+	}
+	return 0, false
+}
+
+// columnCount returns the number of columns of the select list, or false if a
+// star makes it unknown.
+func (d *selectData) columnCount() (int, bool) {
+	if len(d.Columns) == 0 {
+		return 0, false
+	}
+
+	count := 0
+	for _, column := range d.Columns {
+		sql, _, err := nestedToSql(column)
+		if err != nil {
+			return 0, false
+		}
+		for _, item := range splitTopLevel(sql) {
+			item = strings.TrimSpace(item)
+			if item == "*" || strings.HasSuffix(item, ".*") || strings.HasPrefix(item, "* ") {
+				return 0, false
+			}
+			count++
+		}
+	}
+	return count, true
+}
+
+// aliasedColumnRegexp matches a select list item with an alias: expr AS alias.
+var aliasedColumnRegexp = regexp.MustCompile(`^(?is:(.*\S))\s+(?i:AS)\s+([A-Za-z_][A-Za-z0-9_]*)$`)
+
+// alignedParts returns the parts with the SelectBuilder subqueries rewritten to
+// select the AlignColumns, see UnionBuilder.Align.
+func (d *setOpData) alignedParts() ([]setOpPart, error) {
+	parts := make([]setOpPart, len(d.Parts))
+	for i, p := range d.Parts {
+		var q SelectBuilder
+		switch query := p.query.(type) {
+		case SelectBuilder:
+			q = query
+		case *SelectBuilder:
+			q = *query
+		default:
+			return nil, fmt.Errorf("squirrel: %s subquery %d is not a SelectBuilder and can't be aligned", d.name(), i)
+		}
+
+		aligned, err := alignColumns(q, d.AlignColumns)
+		if err != nil {
+			return nil, fmt.Errorf("squirrel: %s subquery %d: %w", d.name(), i, err)
+		}
+		parts[i] = setOpPart{op: p.op, query: aligned}
+	}
+	return parts, nil
+}
+
+// alignColumns rewrites q to select columns, in order, with NULL for the columns q
+// doesn't select. Columns are matched by name or alias, case-insensitively.
+func alignColumns(q SelectBuilder, columns []string) (SelectBuilder, error) {
+	data := q.get()
+
+	named := map[string]Sqlizer{}
+	for _, column := range data.Columns {
+		sql, args, err := nestedToSql(column)
+		if err != nil {
+			return q, err
+		}
+		items := splitTopLevel(sql)
+		if len(items) > 1 && len(args) > 0 {
+			return q, fmt.Errorf("can't align column list %q with args, use one Column per column", sql)
+		}
+
+		for _, item := range items {
+			item = strings.TrimSpace(item)
+			if item == "*" || strings.HasSuffix(item, ".*") || strings.HasPrefix(item, "* ") {
+				return q, fmt.Errorf("can't align %q, list the columns instead", item)
+			}
+
+			name := ""
+			if m := simpleColumnRegexp.FindStringSubmatch(item); m != nil {
+				name = m[2]
+				if len(name) == 0 {
+					name = m[1][strings.LastIndex(m[1], ".")+1:]
+				}
+			} else if m := aliasedColumnRegexp.FindStringSubmatch(item); m != nil {
+				name = m[2]
+			}
+			if len(name) == 0 {
+				continue
+			}
+
+			expr := column
+			if len(items) > 1 {
+				expr = newPart(item)
+			}
+			if _, ok := named[strings.ToLower(name)]; !ok {
+				named[strings.ToLower(name)] = expr
+			}
+		}
+	}
+
+	q = q.RemoveColumns()
+	for _, column := range columns {
+		if expr, ok := named[strings.ToLower(column)]; ok {
+			q = q.Column(expr)
+		} else {
+			q = q.Column("NULL AS " + column)
+		}
+	}
+	return q, nil
+}
+
+// splitTopLevel splits sql at the commas outside of parentheses and quotes.
+func splitTopLevel(sql string) []string {
+	var items []string
+	depth, last := 0, 0
+	scanSql(sql, func(i int) int {
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, sql[last:i])
+				last = i + 1
+			}
+		}
+		return 0
+	})
+	return append(items, sql[last:])
+}
 
 // compactSQL collapses all whitespace into single spaces.
 // Useful to normalize output if upstream builders include newlines.
@@ -139,48 +491,73 @@ func compactSQL(s string) string {
 
 // ---------------- Builder ----------------
 
-type UnionBuilder builder.Builder
+type UnionBuilder struct {
+	core[setOpData]
+}
 
-func init() {
-	builder.Register(UnionBuilder{}, unionData{})
+// set returns a copy of b with the data changed by f.
+func (b UnionBuilder) set(f func(d *setOpData)) UnionBuilder {
+	return UnionBuilder{b.with(f)}
 }
 
 // Union constructs a UNION (DISTINCT) chain with the given subqueries.
 // The first subquery has no leading operator; subsequent ones use "UNION".
 func Union(parts ...Sqlizer) UnionBuilder {
-	u := UnionBuilder{}
-	for i, p := range parts {
-		if i == 0 {
-			u = builder.Append(u, "Parts", unionPart{op: "", query: p}).(UnionBuilder)
-		} else {
-			u = builder.Append(u, "Parts", unionPart{op: unionDistinct, query: p}).(UnionBuilder)
-		}
-	}
-	return u
+	return UnionBuilder{}.appendParts("Union", unionDistinct, parts)
 }
 
 // UnionAll constructs a UNION ALL chain with the given subqueries.
 // The first subquery has no leading operator; subsequent ones use "UNION ALL".
 func UnionAll(parts ...Sqlizer) UnionBuilder {
-	u := UnionBuilder{}
+	return UnionBuilder{}.appendParts("UnionAll", unionAll, parts)
+}
+
+// appendParts appends the subqueries parts with op, except for the first
+// subquery of the builder, which has no leading operator. method names the
+// caller in the error recorded for a nil subquery.
+func (b UnionBuilder) appendParts(method string, op setOp, parts []Sqlizer) UnionBuilder {
 	for i, p := range parts {
-		if i == 0 {
-			u = builder.Append(u, "Parts", unionPart{op: "", query: p}).(UnionBuilder)
-		} else {
-			u = builder.Append(u, "Parts", unionPart{op: unionAll, query: p}).(UnionBuilder)
+		if p == nil {
+			return b.withErr(fmt.Errorf("squirrel: %w in %s subquery %d", ErrNilSqlizer, method, i+1))
 		}
+		partOp := op
+		if len(b.get().Parts) == 0 {
+			partOp = ""
+		}
+		b = b.set(func(d *setOpData) {
+			d.Parts = appendTo(d.Parts, setOpPart{op: partOp, query: p})
+		})
+	}
+	return b
+}
+
+// appendPart appends the subquery q with op, see appendParts.
+func (b UnionBuilder) appendPart(method string, op setOp, q Sqlizer) UnionBuilder {
+	if q == nil {
+		return b.withErr(fmt.Errorf("squirrel: %w in %s subquery", ErrNilSqlizer, method))
 	}
-	return u
+	return b.set(func(d *setOpData) {
+		d.Parts = appendTo(d.Parts, setOpPart{op: op, query: q})
+	})
+}
+
+// withErr records err on the builder, to be returned by ToSql.
+// Only the first recorded error is kept.
+func (b UnionBuilder) withErr(err error) UnionBuilder {
+	if b.get().Err != nil {
+		return b
+	}
+	return b.set(func(d *setOpData) { d.Err = err })
 }
 
 // Union appends another subquery with UNION (DISTINCT).
 func (b UnionBuilder) Union(q Sqlizer) UnionBuilder {
-	return builder.Append(b, "Parts", unionPart{op: unionDistinct, query: q}).(UnionBuilder)
+	return b.appendPart("Union", unionDistinct, q)
 }
 
 // UnionAll appends another subquery with UNION ALL.
 func (b UnionBuilder) UnionAll(q Sqlizer) UnionBuilder {
-	return builder.Append(b, "Parts", unionPart{op: unionAll, query: q}).(UnionBuilder)
+	return b.appendPart("UnionAll", unionAll, q)
 }
 
 // ----- Options -----
@@ -188,46 +565,170 @@ func (b UnionBuilder) UnionAll(q Sqlizer) UnionBuilder {
 // OrderBy sets ORDER BY on the whole union.
 // Example: .OrderBy("id DESC", "created_at")
 func (b UnionBuilder) OrderBy(exprs ...string) UnionBuilder {
-	return builder.Extend(b, "OrderBy", exprs).(UnionBuilder)
+	return b.set(func(d *setOpData) { d.OrderBy = appendTo(d.OrderBy, exprs...) })
 }
 
 // Limit sets LIMIT on the whole union.
 func (b UnionBuilder) Limit(n uint64) UnionBuilder {
-	b = builder.Set(b, "LimitSet", true).(UnionBuilder)
-	return builder.Set(b, "Limit", n).(UnionBuilder)
+	return b.set(func(d *setOpData) {
+		d.LimitExpr = nil
+		d.LimitSet = true
+		d.Limit = n
+	})
+}
+
+// LimitExpr sets LIMIT on the whole union with an expression, e.g. a bound arg.
+// Example: .LimitExpr(Expr("?", pageSize))
+func (b UnionBuilder) LimitExpr(e Sqlizer) UnionBuilder {
+	return b.set(func(d *setOpData) {
+		d.LimitSet = false
+		d.LimitExpr = e
+	})
+}
+
+// FetchFirst sets FETCH FIRST n ROWS on the whole union, the same as Limit; it is
+// rendered as LIMIT unless the dialect requires FETCH or WithTies is set.
+func (b UnionBuilder) FetchFirst(n uint64) UnionBuilder {
+	return b.Limit(n)
+}
+
+// WithTies makes FetchFirst return the rows tied with the last one by the ORDER
+// BY clause as well: FETCH FIRST n ROWS WITH TIES.
+// Example: .OrderBy("score DESC").FetchFirst(3).WithTies()
+func (b UnionBuilder) WithTies() UnionBuilder {
+	return b.set(func(d *setOpData) { d.WithTies = true })
 }
 
 // Offset sets OFFSET on the whole union.
 func (b UnionBuilder) Offset(n uint64) UnionBuilder {
-	b = builder.Set(b, "OffsetSet", true).(UnionBuilder)
-	return builder.Set(b, "Offset", n).(UnionBuilder)
+	return b.set(func(d *setOpData) {
+		d.OffsetExpr = nil
+		d.OffsetSet = true
+		d.Offset = n
+	})
+}
+
+// OffsetExpr sets OFFSET on the whole union with an expression, see LimitExpr.
+func (b UnionBuilder) OffsetExpr(e Sqlizer) UnionBuilder {
+	return b.set(func(d *setOpData) {
+		d.OffsetSet = false
+		d.OffsetExpr = e
+	})
+}
+
+// PrefixExpr prepends a leading expression (e.g., WITH clauses, comments) to the
+// union. Unlike the other builders, there is no Prefix(sql, args...), as Suffix
+// takes Sqlizers.
+func (b UnionBuilder) PrefixExpr(e Sqlizer) UnionBuilder {
+	return b.set(func(d *setOpData) {
+		d.Prefixes = appendTo(d.Prefixes, orNilSqlizer(e, "prefix"))
+	})
 }
 
 // Suffix appends trailing SQL fragments (e.g., comments/hints) to the union.
 // Use with Expr(...) or other Sqlizers.
 func (b UnionBuilder) Suffix(exprs ...Sqlizer) UnionBuilder {
-	return builder.Extend(b, "Suffixes", exprs).(UnionBuilder)
+	return b.set(func(d *setOpData) {
+		d.Suffixes = appendTo(d.Suffixes, orNilSqlizers(exprs, "suffix")...)
+	})
+}
+
+// SuffixExpr appends a single trailing expression to the union.
+func (b UnionBuilder) SuffixExpr(e Sqlizer) UnionBuilder {
+	return b.set(func(d *setOpData) {
+		d.Suffixes = appendTo(d.Suffixes, orNilSqlizer(e, "suffix"))
+	})
 }
 
 // PlaceholderFormat sets the placeholder format (Question, Dollar, Colon, etc.).
 // Prefer setting this once at the top-level builder if the union is used inside
 // a larger statement (e.g., WITH ... <union>).
 func (b UnionBuilder) PlaceholderFormat(f PlaceholderFormat) UnionBuilder {
-	return builder.Set(b, "PlaceholderFormat", f).(UnionBuilder)
+	return b.set(func(d *setOpData) { d.PlaceholderFormat = f })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when the union has more than
+// n subqueries. 0 means no limit.
+func (b UnionBuilder) MaxParts(n int) UnionBuilder {
+	return b.set(func(d *setOpData) { d.MaxParts = n })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement is over l, e.g. SizeLimit{Args: 65535} for PostgreSQL.
+func (b UnionBuilder) SizeLimit(l SizeLimit) UnionBuilder {
+	return b.set(func(d *setOpData) { d.SizeLimit = l })
+}
+
+// TimeLocation converts the time.Time args of the query to loc, e.g. time.UTC,
+// when it is built. A nil loc leaves them as is.
+func (b UnionBuilder) TimeLocation(loc *time.Location) UnionBuilder {
+	return b.set(func(d *setOpData) { d.TimeLocation = loc })
+}
+
+// Dialect sets the target database engine (e.g. DialectClickHouse) for the union.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b UnionBuilder) Dialect(d Dialect) UnionBuilder {
+	b = b.set(func(data *setOpData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
+}
+
+// CheckColumnCount makes ToSql check that the subqueries select the same number
+// of columns, returning an error naming the mismatching subquery otherwise. Only
+// SelectBuilder (and nested set operation) subqueries without * are checked.
+func (b UnionBuilder) CheckColumnCount() UnionBuilder {
+	return b.set(func(d *setOpData) { d.CheckColumnCount = true })
+}
+
+// Align rewrites every subquery, which must be a SelectBuilder, to select exactly
+// columns in this order, with NULL AS column for the columns it doesn't select.
+// Columns are matched by their name or alias, the others are dropped.
+// Ex:
+//
+//	UnionAll(Select("id", "name").From("users"), Select("id", "title AS name", "url").From("pages")).
+//		Align("id", "name", "url")
+//	// (SELECT id, name, NULL AS url FROM users) UNION ALL (SELECT id, title AS name, url FROM pages)
+func (b UnionBuilder) Align(columns ...string) UnionBuilder {
+	// copied so that the caller can reuse the slice
+	columns = append([]string(nil), columns...)
+	return b.set(func(d *setOpData) { d.AlignColumns = columns })
 }
 
 // Compact enables one-line SQL output (no newlines / duplicate spaces).
 func (b UnionBuilder) Compact() UnionBuilder {
-	return builder.Set(b, "CompactOutput", true).(UnionBuilder)
+	return b.set(func(d *setOpData) { d.CompactOutput = true })
 }
 
 // ----- Sqlizer -----
 
 func (b UnionBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(unionData)
+	data := b.get()
+	return data.ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done: it is checked between the subqueries, e.g. for unions of tens of
+// thousands of generated SELECTs.
+func (b UnionBuilder) ToSqlContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.ToSql()
 }
 
+func (b UnionBuilder) toSqlRaw() (string, []any, error) {
+	data := b.get()
+	return data.toSqlRaw()
+}
+
+func (b UnionBuilder) toSqlRawContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
+	return data.toSqlRaw()
+}
+
 func (b UnionBuilder) MustSql() (string, []any) {
 	sql, args, err := b.ToSql()
 	if err != nil {
@@ -235,4 +736,3 @@ func (b UnionBuilder) MustSql() (string, []any) {
 	}
 	return sql, args
 }
-
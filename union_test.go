@@ -1,7 +1,9 @@
 package squirrel
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -116,6 +118,28 @@ func TestUnion_SuffixAndArgsOrder(t *testing.T) {
 	}
 }
 
+func TestUnion_PrefixArgsComeFirst(t *testing.T) {
+	u := Union(
+		Select("id").From("a").Where(Expr("x > ?", 7)),
+		Select("id").From("b").Where(Expr("y < ?", 9)),
+	).PrefixExpr(Expr("/* ? */", 1)).
+		SuffixExpr(Expr("LIMIT ?", 10)).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := u.ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "/* $1 */ (SELECT id FROM a WHERE x > $2) UNION (SELECT id FROM b WHERE y < $3) LIMIT $4"
+	if !compactedEqual(sql, wantSQL) {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+	wantArgs := []any{1, 7, 9, 10}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args mismatch\n got: %#v\nwant: %#v", args, wantArgs)
+	}
+}
+
 func TestUnion_PlaceholderQuestionFormat(t *testing.T) {
 	u := Union(
 		Select("id").From("a").Where(Expr("x > ?", 1)),
@@ -160,3 +184,252 @@ func TestUnion_EmptyError(t *testing.T) {
 		t.Fatalf("expected error for empty union, got nil")
 	}
 }
+
+func TestUnion_ClickHouseBracketFree(t *testing.T) {
+	u := Union(
+		Select("id").From("a").Where(Expr("x > ?", 1)),
+		Select("id").From("b"),
+	).UnionAll(Select("id").From("c")).Dialect(DialectClickHouse)
+
+	sql, args, err := u.ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "SELECT id FROM a WHERE x > ? UNION DISTINCT SELECT id FROM b UNION ALL SELECT id FROM c"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+	if !reflect.DeepEqual(args, []any{1}) {
+		t.Fatalf("args mismatch\n got: %#v\nwant: %#v", args, []any{1})
+	}
+
+	sql, _, err = u.OrderBy("id").Limit(5).ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL = "SELECT * FROM (SELECT id FROM a WHERE x > ? UNION DISTINCT SELECT id FROM b UNION ALL SELECT id FROM c) ORDER BY id LIMIT 5"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+}
+
+func TestUnion_SQLiteBracketFree(t *testing.T) {
+	u := UnionAll(
+		Select("id").From("a"),
+		Select("id").From("b"),
+	).OrderBy("id").Dialect(DialectSQLite)
+
+	sql, _, err := u.ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "SELECT id FROM a UNION ALL SELECT id FROM b ORDER BY id"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+}
+
+func TestUnion_CheckColumnCount(t *testing.T) {
+	u := UnionAll(
+		Select("id", "name").From("users"),
+		Select("id, title").From("posts"),
+		Select("id", "COALESCE(nickname, name)").From("admins"),
+	).CheckColumnCount()
+	if _, _, err := u.ToSql(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	u = UnionAll(
+		Select("id", "name").From("users"),
+		Select("*").From("archived_users"),
+		Select("id").From("posts"),
+	).CheckColumnCount()
+	_, _, err := u.ToSql()
+	if err == nil {
+		t.Fatalf("expected error for mismatching column counts")
+	}
+	want := "squirrel: union subquery 2 selects 1 columns, but subquery 0 selects 2"
+	if err.Error() != want {
+		t.Fatalf("error mismatch\n got: %s\nwant: %s", err, want)
+	}
+
+	// not checked unless opted in
+	u = UnionAll(Select("id", "name").From("users"), Select("id").From("posts"))
+	if _, _, err := u.ToSql(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUnion_Align(t *testing.T) {
+	u := UnionAll(
+		Select("id", "name").From("users").Where(Expr("active = ?", true)),
+		Select("p.id, p.title AS name").Column("? AS url", "/pages").From("pages p"),
+		Select("COUNT(*) AS id").From("posts"),
+	).Align("id", "name", "url").PlaceholderFormat(Dollar)
+
+	sql, args, err := u.ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "(SELECT id, name, NULL AS url FROM users WHERE active = $1) " +
+		"UNION ALL (SELECT p.id, p.title AS name, $2 AS url FROM pages p) " +
+		"UNION ALL (SELECT COUNT(*) AS id, NULL AS name, NULL AS url FROM posts)"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+	wantArgs := []any{true, "/pages"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args mismatch\n got: %#v\nwant: %#v", args, wantArgs)
+	}
+}
+
+func TestUnion_AlignErr(t *testing.T) {
+	for _, u := range []UnionBuilder{
+		UnionAll(Select("id").From("users"), Select("*").From("pages")).Align("id"),
+		UnionAll(Select("id").From("users"), Expr("SELECT id FROM pages")).Align("id"),
+	} {
+		if _, _, err := u.ToSql(); err == nil {
+			t.Fatalf("expected error")
+		}
+	}
+}
+
+func TestUnion_FetchFirstWithTies(t *testing.T) {
+	u := UnionAll(
+		Select("name", "score").From("a"),
+		Select("name", "score").From("b"),
+	).OrderBy("score DESC").FetchFirst(3).WithTies().Dialect(DialectPostgres)
+
+	sql, _, err := u.ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "(SELECT name, score FROM a) UNION ALL (SELECT name, score FROM b) ORDER BY score DESC FETCH FIRST 3 ROWS WITH TIES"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+
+	for _, u := range []UnionBuilder{
+		UnionAll(Select("id").From("a"), Select("id").From("b")).FetchFirst(3).WithTies(),
+		UnionAll(Select("id").From("a"), Select("id").From("b")).OrderBy("id").WithTies(),
+		UnionAll(Select("id").From("a"), Select("id").From("b")).OrderBy("id").FetchFirst(3).WithTies().Dialect(DialectMySQL),
+	} {
+		if _, _, err := u.ToSql(); err == nil {
+			t.Fatalf("expected error")
+		}
+	}
+}
+
+func TestUnion_FetchPaginationDialects(t *testing.T) {
+	u := UnionAll(Select("id").From("a"), Select("id").From("b")).OrderBy("id").Limit(10)
+
+	sql, _, err := u.Dialect(DialectMSSQL).ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "(SELECT id FROM a) UNION ALL (SELECT id FROM b) ORDER BY id OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+
+	// SQL Server requires an ORDER BY clause before OFFSET
+	sql, _, err = UnionAll(Select("id").From("a"), Select("id").From("b")).Offset(5).Dialect(DialectMSSQL).ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL = "(SELECT id FROM a) UNION ALL (SELECT id FROM b) ORDER BY (SELECT NULL) OFFSET 5 ROWS"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+
+	sql, _, err = u.Offset(20).Dialect(DialectOracle).ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL = "(SELECT id FROM a) UNION ALL (SELECT id FROM b) ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+}
+
+func TestUnion_LimitOffsetExpr(t *testing.T) {
+	u := UnionAll(
+		Select("id").From("a").Where(Expr("x = ?", 1)),
+		Select("id").From("b"),
+	).OrderBy("id").LimitExpr(Expr("?", 10)).OffsetExpr(Expr("?", 20)).PlaceholderFormat(Dollar)
+
+	sql, args, err := u.ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL := "(SELECT id FROM a WHERE x = $1) UNION ALL (SELECT id FROM b) ORDER BY id LIMIT $2 OFFSET $3"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+	wantArgs := []any{1, 10, 20}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args mismatch\n got: %#v\nwant: %#v", args, wantArgs)
+	}
+
+	sql, args, err = u.Dialect(DialectOracle).ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantSQL = "(SELECT id FROM a WHERE x = :1) UNION ALL (SELECT id FROM b) ORDER BY id OFFSET :2 ROWS FETCH NEXT :3 ROWS ONLY"
+	if sql != wantSQL {
+		t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, wantSQL)
+	}
+	wantArgs = []any{1, 20, 10}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args mismatch\n got: %#v\nwant: %#v", args, wantArgs)
+	}
+}
+
+func TestUnionNilSubqueryError(t *testing.T) {
+	_, _, err := Union(Select("id").From("a"), nil).ToSql()
+	if err == nil || !strings.Contains(err.Error(), "nil Sqlizer in Union subquery 2") {
+		t.Fatalf("expected nil subquery error, got: %v", err)
+	}
+
+	_, _, err = UnionAll(Select("id").From("a")).UnionAll(nil).Limit(10).ToSql()
+	if err == nil || !strings.Contains(err.Error(), "nil Sqlizer in UnionAll subquery") {
+		t.Fatalf("expected nil subquery error, got: %v", err)
+	}
+}
+
+func TestUnionNilSuffixError(t *testing.T) {
+	_, _, err := Union(Select("id").From("a")).Suffix(nil).ToSql()
+	if !errors.Is(err, ErrNilSqlizer) {
+		t.Fatalf("expected ErrNilSqlizer, got: %v", err)
+	}
+}
+
+func TestUnion_NestedDollar(t *testing.T) {
+	psql := StatementBuilder.PlaceholderFormat(Dollar)
+	u := psql.Union(psql.Select("id").From("a").Where("x = ?", 1), psql.Select("id").From("b").Where("y = ?", 2))
+
+	for _, tc := range []struct {
+		q       Sqlizer
+		wantSQL string
+	}{
+		{
+			psql.Select("*").From("t").Where(Eq{"z": 0}).Where(Expr("id IN (?)", u)),
+			"SELECT * FROM t WHERE z = $1 AND id IN ((SELECT id FROM a WHERE x = $2) UNION (SELECT id FROM b WHERE y = $3))",
+		},
+		{
+			psql.With("c", psql.Select("id").From("d").Where("w = ?", 0)).Union(u),
+			"WITH c AS (SELECT id FROM d WHERE w = $1) (SELECT id FROM a WHERE x = $2) UNION (SELECT id FROM b WHERE y = $3)",
+		},
+	} {
+		sql, args, err := tc.q.ToSql()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sql != tc.wantSQL {
+			t.Fatalf("sql mismatch\n got: %s\nwant: %s", sql, tc.wantSQL)
+		}
+		if !reflect.DeepEqual(args, []any{0, 1, 2}) {
+			t.Fatalf("args mismatch: %v", args)
+		}
+	}
+}
@@ -2,15 +2,24 @@ package squirrel
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
-
-	"github.com/lann/builder"
+	"time"
 )
 
+// ErrStaleRow is returned by CheckStaleRow when an update guarded by
+// UpdateBuilder.WithVersion didn't change any row.
+var ErrStaleRow = errors.New("stale row: no row matched the expected version")
+
 type updateData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	TimeLocation      *time.Location
+	SizeLimit         SizeLimit
 	Prefixes          []Sqlizer
 	Table             string
 	SetClauses        []setClause
@@ -20,6 +29,10 @@ type updateData struct {
 	Limit             string
 	Offset            string
 	Suffixes          []Sqlizer
+	Returning         []string
+	MaxParts          int
+	ctx               context.Context // set by ToSqlContext
+	size              *sizeTracker    // set by ToSql
 }
 
 type setClause struct {
@@ -36,14 +49,28 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 		err = fmt.Errorf("update statements must have at least one Set clause")
 		return "", nil, err
 	}
+	if err = ctxErr(d.ctx); err != nil {
+		return "", nil, err
+	}
+	if err = checkParts("SET", len(d.SetClauses), d.MaxParts); err != nil {
+		return "", nil, err
+	}
+	if err = checkParts("WHERE", len(d.WhereParts), d.MaxParts); err != nil {
+		return "", nil, err
+	}
+
+	d.size = newSizeTracker(d.SizeLimit)
 
 	sql := &bytes.Buffer{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("prefix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 
 		_, _ = sql.WriteString(" ")
 	}
@@ -60,7 +87,7 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 				vsql  string
 				vargs []any
 			)
-			vsql, vargs, err = vs.ToSql()
+			vsql, vargs, err = nestedToSqlContext(d.ctx, vs)
 			if err != nil {
 				return "", nil, err
 			}
@@ -77,21 +104,34 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 		setSqls[i] = fmt.Sprintf("%s = %s", setClause.column, valSql)
 	}
 	_, _ = sql.WriteString(strings.Join(setSqls, ", "))
+	if err = d.size.clause("SET", sql.Len(), len(args)); err != nil {
+		return "", nil, err
+	}
 
 	if d.From != nil {
 		_, _ = sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		args, err = appendToSqlContext(d.ctx, []Sqlizer{d.From}, sql, "", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("FROM", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
 	}
 
 	if len(d.WhereParts) > 0 {
 		_, _ = sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSqlContext(d.ctx, d.WhereParts, sql, " AND ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("WHERE", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if (len(d.OrderBys) > 0 || len(d.Limit) > 0 || len(d.Offset) > 0) && !d.Dialect.supportsUpdateDeleteLimit() {
+		return "", nil, d.Dialect.unsupportedError("ORDER BY, LIMIT and OFFSET in UPDATE statements")
 	}
 
 	if len(d.OrderBys) > 0 {
@@ -111,23 +151,46 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 
 	if len(d.Suffixes) > 0 {
 		_, _ = sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSqlContext(d.ctx, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return "", nil, err
 		}
+		if err = d.size.clause("suffix", sql.Len(), len(args)); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(d.Returning) > 0 {
+		if !d.Dialect.supportsReturning() {
+			return "", nil, d.Dialect.unsupportedError("RETURNING")
+		}
+
+		_, _ = sql.WriteString(" RETURNING ")
+		_, _ = sql.WriteString(strings.Join(d.Returning, ", "))
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
+	args = bindArgs(normalizeTimes(args, d.TimeLocation))
+	if d.PlaceholderFormat != nil { // zero value builder
+		sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	}
+	if err == nil {
+		err = d.size.check(len(sqlStr), len(args))
+	}
 	return sqlStr, args, err
 }
 
 // Builder
 
 // UpdateBuilder builds SQL UPDATE statements.
-type UpdateBuilder builder.Builder
+// The zero value is an empty statement using ? placeholders.
+type UpdateBuilder struct {
+	core[updateData]
+}
 
-func init() {
-	builder.Register(UpdateBuilder{}, updateData{})
+// set returns a copy of b with the data changed by f.
+func (b UpdateBuilder) set(f func(d *updateData)) UpdateBuilder {
+	return UpdateBuilder{b.with(f)}
 }
 
 // Format methods
@@ -135,14 +198,51 @@ func init() {
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
 // query.
 func (b UpdateBuilder) PlaceholderFormat(f PlaceholderFormat) UpdateBuilder {
-	return builder.Set(b, "PlaceholderFormat", f).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.PlaceholderFormat = f })
+}
+
+// MaxParts makes ToSql return a TooManyPartsError when there are more than n
+// SET or WHERE parts. 0 means no limit.
+func (b UpdateBuilder) MaxParts(n int) UpdateBuilder {
+	return b.set(func(d *updateData) { d.MaxParts = n })
+}
+
+// SizeLimit makes ToSql return a StatementTooLargeError when the rendered
+// statement is over l, e.g. SizeLimit{Args: 65535} for PostgreSQL.
+func (b UpdateBuilder) SizeLimit(l SizeLimit) UpdateBuilder {
+	return b.set(func(d *updateData) { d.SizeLimit = l })
+}
+
+// TimeLocation converts the time.Time args of the query to loc, e.g. time.UTC,
+// when it is built. A nil loc leaves them as is.
+func (b UpdateBuilder) TimeLocation(loc *time.Location) UpdateBuilder {
+	return b.set(func(d *updateData) { d.TimeLocation = loc })
+}
+
+// Dialect sets the target database engine (e.g. DialectSQLite) for the query.
+// The placeholder format preferred by the dialect is set as well, call
+// PlaceholderFormat afterwards to override it.
+func (b UpdateBuilder) Dialect(d Dialect) UpdateBuilder {
+	b = b.set(func(data *updateData) { data.Dialect = d })
+	if f := d.PlaceholderFormat(); f != nil {
+		b = b.PlaceholderFormat(f)
+	}
+	return b
 }
 
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
 func (b UpdateBuilder) ToSql() (string, []any, error) {
-	data := builder.GetStruct(b).(updateData)
+	data := b.get()
+	return data.ToSql()
+}
+
+// ToSqlContext works like ToSql, but returns the error of ctx as soon as it is
+// done, e.g. for updates with thousands of generated conditions.
+func (b UpdateBuilder) ToSqlContext(ctx context.Context) (string, []any, error) {
+	data := b.get()
+	data.ctx = ctx
 	return data.ToSql()
 }
 
@@ -163,17 +263,21 @@ func (b UpdateBuilder) Prefix(sql string, args ...any) UpdateBuilder {
 
 // PrefixExpr adds an expression to the very beginning of the query
 func (b UpdateBuilder) PrefixExpr(e Sqlizer) UpdateBuilder {
-	return builder.Append(b, "Prefixes", e).(UpdateBuilder)
+	return b.set(func(d *updateData) {
+		d.Prefixes = appendTo(d.Prefixes, orNilSqlizer(e, "prefix"))
+	})
 }
 
 // Table sets the table to be updated.
 func (b UpdateBuilder) Table(table string) UpdateBuilder {
-	return builder.Set(b, "Table", table).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.Table = table })
 }
 
 // Set adds SET clauses to the query.
 func (b UpdateBuilder) Set(column string, value any) UpdateBuilder {
-	return builder.Append(b, "SetClauses", setClause{column: column, value: value}).(UpdateBuilder)
+	return b.set(func(d *updateData) {
+		d.SetClauses = appendTo(d.SetClauses, setClause{column: column, value: value})
+	})
 }
 
 // SetMap is a convenience method which calls .Set for each key/value pair in clauses.
@@ -192,39 +296,55 @@ func (b UpdateBuilder) SetMap(clauses map[string]any) UpdateBuilder {
 	return b
 }
 
+// WithVersion adds optimistic locking to the query: the row is only updated if
+// column still equals current, and column is incremented.
+// Use CheckStaleRow on the result to detect concurrent modifications.
+// Ex: Update("docs").Set("body", body).Where(Eq{"id": 1}).WithVersion("version", 3)
+// -> "UPDATE docs SET body = ?, version = version + 1 WHERE id = ? AND version = ?"
+func (b UpdateBuilder) WithVersion(column string, current any) UpdateBuilder {
+	return b.Set(column, Expr(column+" + 1")).Where(Eq{column: current})
+}
+
 // From adds FROM clause to the query
 // FROM is valid construct in postgresql only.
 func (b UpdateBuilder) From(from string) UpdateBuilder {
-	return builder.Set(b, "From", newPart(from)).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.From = newPart(from) })
 }
 
 // FromSelect sets a subquery into the FROM clause of the query.
 func (b UpdateBuilder) FromSelect(from SelectBuilder, alias string) UpdateBuilder {
 	// Prevent misnumbered parameters in nested selects (#183).
 	from = from.PlaceholderFormat(Question)
-	return builder.Set(b, "From", Alias(from, alias)).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.From = Alias(from, alias) })
 }
 
 // Where adds WHERE expressions to the query.
 //
 // See SelectBuilder.Where for more information.
 func (b UpdateBuilder) Where(pred any, args ...any) UpdateBuilder {
-	return builder.Append(b, "WhereParts", newWherePart(pred, args...)).(UpdateBuilder)
+	if pred == nil { // skipping it would widen the statement
+		return b.set(func(d *updateData) {
+			d.WhereParts = appendTo[Sqlizer](d.WhereParts, nilSqlizer{clause: "WHERE"})
+		})
+	}
+	return b.set(func(d *updateData) {
+		d.WhereParts = appendTo(d.WhereParts, newWherePart(pred, args...))
+	})
 }
 
 // OrderBy adds ORDER BY expressions to the query.
 func (b UpdateBuilder) OrderBy(orderBys ...string) UpdateBuilder {
-	return builder.Extend(b, "OrderBys", orderBys).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.OrderBys = appendTo(d.OrderBys, orderBys...) })
 }
 
 // Limit sets a LIMIT clause on the query.
 func (b UpdateBuilder) Limit(limit uint64) UpdateBuilder {
-	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.Limit = fmt.Sprintf("%d", limit) })
 }
 
 // Offset sets a OFFSET clause on the query.
 func (b UpdateBuilder) Offset(offset uint64) UpdateBuilder {
-	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(UpdateBuilder)
+	return b.set(func(d *updateData) { d.Offset = fmt.Sprintf("%d", offset) })
 }
 
 // Suffix adds an expression to the end of the query
@@ -234,5 +354,26 @@ func (b UpdateBuilder) Suffix(sql string, args ...any) UpdateBuilder {
 
 // SuffixExpr adds an expression to the end of the query
 func (b UpdateBuilder) SuffixExpr(e Sqlizer) UpdateBuilder {
-	return builder.Append(b, "Suffixes", e).(UpdateBuilder)
+	return b.set(func(d *updateData) {
+		d.Suffixes = appendTo(d.Suffixes, orNilSqlizer(e, "suffix"))
+	})
+}
+
+// Returning adds a RETURNING clause to the query.
+// It is rendered after all suffixes.
+func (b UpdateBuilder) Returning(columns ...string) UpdateBuilder {
+	return b.set(func(d *updateData) { d.Returning = appendTo(d.Returning, columns...) })
+}
+
+// CheckStaleRow returns ErrStaleRow if result reports no affected rows,
+// e.g. for an update guarded by UpdateBuilder.WithVersion.
+func CheckStaleRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrStaleRow
+	}
+	return nil
 }
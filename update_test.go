@@ -86,3 +86,53 @@ func TestUpdateBuilderFromSelect(t *testing.T) {
 		"WHERE employees.account_id = subquery.id"
 	assert.Equal(t, expectedSql, sql)
 }
+
+func TestUpdateBuilderReturning(t *testing.T) {
+	b := Update("users").Set("name", "John").Where(Eq{"id": 1}).Returning("id", "name")
+
+	sql, args, err := b.Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = ? RETURNING id, name", sql)
+	assert.Equal(t, []any{"John", 1}, args)
+
+	_, _, err = b.Dialect(DialectMySQL).ToSql()
+	assert.Error(t, err)
+}
+
+func TestUpdateBuilderSQLiteLimit(t *testing.T) {
+	_, _, err := Update("users").Set("name", "John").Limit(1).Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	sql, _, err := Update("users").Set("name", "John").Limit(1).Dialect(DialectSQLiteUpdateDeleteLimit).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE users SET name = ? LIMIT 1", sql)
+
+	for _, d := range []Dialect{DialectPostgres, DialectDuckDB, DialectBigQuery, DialectSnowflake, DialectClickHouse} {
+		_, _, err = Update("users").Set("name", "John").OrderBy("id").Dialect(d).ToSql()
+		assert.Error(t, err, d)
+	}
+}
+
+func TestUpdateBuilderWithVersion(t *testing.T) {
+	sql, args, err := Update("docs").Set("body", "text").Where(Eq{"id": 1}).WithVersion("version", 3).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE docs SET body = ?, version = version + 1 WHERE id = ? AND version = ?", sql)
+	assert.Equal(t, []any{"text", 1, 3}, args)
+}
+
+type rowsAffectedResult int64
+
+func (r rowsAffectedResult) LastInsertId() (int64, error) { return 0, nil }
+func (r rowsAffectedResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestCheckStaleRow(t *testing.T) {
+	assert.Equal(t, ErrStaleRow, CheckStaleRow(rowsAffectedResult(0)))
+	assert.NoError(t, CheckStaleRow(rowsAffectedResult(1)))
+}
+
+func TestUpdateBuilderNilSqlizer(t *testing.T) {
+	var pred Sqlizer
+
+	_, _, err := Update("t").Set("a", 1).Where(pred).ToSql()
+	assert.EqualError(t, err, "nil Sqlizer in WHERE")
+}
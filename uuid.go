@@ -0,0 +1,95 @@
+package squirrel
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// uuidExpr helps to generate UUIDs in SQL query
+type uuidExpr struct {
+	v4         bool
+	clientSide bool
+	dialect    Dialect
+}
+
+// UUIDv4 allows to use a random (version 4) UUID, e.g. as an Insert value.
+// It is rendered as the function of the dialect generating version 4 UUIDs
+// (gen_random_uuid(), NEWID(), GENERATE_UUID(), ...). For dialects without one
+// (MySQL, MariaDB, SQLite, Oracle) the UUID is generated by the client and bound
+// as an arg, a new one on every ToSql call.
+// Ex: Insert("users").Columns("id").Values(UUIDv4()) -> "INSERT INTO users (id) VALUES (gen_random_uuid())"
+func UUIDv4() uuidExpr {
+	return uuidExpr{v4: true}
+}
+
+// GenRandomUUID allows to use the UUID function of the dialect, e.g. as a column
+// default: gen_random_uuid() for PostgreSQL, UUID() for MySQL, SYS_GUID() for
+// Oracle. Unlike UUIDv4 the generated UUID version depends on the engine.
+// Ex: GenRandomUUID().Dialect(DialectMySQL) -> "UUID()"
+func GenRandomUUID() uuidExpr {
+	return uuidExpr{}
+}
+
+// ClientSide makes the UUID be generated by the client and bound as an arg,
+// whatever the dialect.
+func (e uuidExpr) ClientSide() uuidExpr {
+	e.clientSide = true
+	return e
+}
+
+// Dialect sets the dialect used to render the expression.
+func (e uuidExpr) Dialect(d Dialect) uuidExpr {
+	e.dialect = d
+	return e
+}
+
+func (e uuidExpr) ToSql() (string, []any, error) {
+	if e.clientSide {
+		return e.bound()
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectDefault, DialectPostgres, DialectDuckDB:
+		return "gen_random_uuid()", nil, nil
+	case DialectMSSQL:
+		return "NEWID()", nil, nil
+	case DialectBigQuery:
+		return "GENERATE_UUID()", nil, nil
+	case DialectSnowflake:
+		return "UUID_STRING()", nil, nil
+	case DialectClickHouse:
+		return "generateUUIDv4()", nil, nil
+	}
+
+	if e.v4 {
+		return e.bound()
+	}
+
+	switch e.dialect { //nolint:exhaustive
+	case DialectMySQL, DialectMariaDB:
+		return "UUID()", nil, nil
+	case DialectOracle, DialectOracleLegacy:
+		return "SYS_GUID()", nil, nil
+	}
+	return "", nil, e.dialect.unsupportedError("GenRandomUUID")
+}
+
+func (e uuidExpr) bound() (string, []any, error) {
+	uuid, err := newUUIDv4()
+	if err != nil {
+		return "", nil, err
+	}
+	return "?", []any{uuid}, nil
+}
+
+// newUUIDv4 returns a random UUID in its canonical text form.
+func newUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generating UUID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
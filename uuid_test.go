@@ -0,0 +1,79 @@
+package squirrel
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var uuidV4Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestUUIDv4(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectDefault, "gen_random_uuid()"},
+		{DialectPostgres, "gen_random_uuid()"},
+		{DialectMSSQL, "NEWID()"},
+		{DialectBigQuery, "GENERATE_UUID()"},
+		{DialectSnowflake, "UUID_STRING()"},
+		{DialectClickHouse, "generateUUIDv4()"},
+	}
+	for _, tt := range tests {
+		sql, args, err := UUIDv4().Dialect(tt.dialect).ToSql()
+		assert.NoError(t, err, tt.dialect)
+		assert.Equal(t, tt.want, sql, tt.dialect)
+		assert.Empty(t, args)
+	}
+
+	sql, args, err := UUIDv4().Dialect(DialectMySQL).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "?", sql)
+	if assert.Len(t, args, 1) {
+		assert.Regexp(t, uuidV4Pattern, args[0])
+	}
+}
+
+func TestUUIDv4ClientSide(t *testing.T) {
+	sql, args, err := Insert("users").Columns("id", "name").
+		Values(UUIDv4().ClientSide(), "John").
+		PlaceholderFormat(Dollar).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO users (id,name) VALUES ($1,$2)", sql)
+	if assert.Len(t, args, 2) {
+		assert.Regexp(t, uuidV4Pattern, args[0])
+		assert.Equal(t, "John", args[1])
+	}
+
+	_, first, _ := UUIDv4().ClientSide().ToSql()
+	_, second, _ := UUIDv4().ClientSide().ToSql()
+	assert.NotEqual(t, first, second)
+}
+
+func TestGenRandomUUID(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, "gen_random_uuid()"},
+		{DialectMySQL, "UUID()"},
+		{DialectMariaDB, "UUID()"},
+		{DialectOracle, "SYS_GUID()"},
+		{DialectMSSQL, "NEWID()"},
+	}
+	for _, tt := range tests {
+		sql, _, err := GenRandomUUID().Dialect(tt.dialect).ToSql()
+		assert.NoError(t, err, tt.dialect)
+		assert.Equal(t, tt.want, sql, tt.dialect)
+	}
+
+	_, _, err := GenRandomUUID().Dialect(DialectSQLite).ToSql()
+	assert.Error(t, err)
+
+	sql, args, err := GenRandomUUID().ClientSide().Dialect(DialectSQLite).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "?", sql)
+	assert.Len(t, args, 1)
+}
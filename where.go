@@ -1,6 +1,7 @@
 package squirrel
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -11,16 +12,21 @@ func newWherePart(pred any, args ...any) Sqlizer {
 }
 
 func (p wherePart) ToSql() (sql string, args []any, err error) {
+	return p.toSqlRawContext(context.Background())
+}
+
+func (p wherePart) toSqlRawContext(ctx context.Context) (sql string, args []any, err error) {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
-	case rawSqlizer:
-		return pred.toSqlRaw()
 	case Sqlizer:
-		return pred.ToSql()
+		return nestedToSqlContext(ctx, pred)
 	case map[string]any:
 		return Eq(pred).ToSql()
 	case string:
+		if hasListArg(p.args) {
+			return interpolateArgs(pred, p.args, false)
+		}
 		sql = pred
 		args = p.args
 	default: